@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -10,6 +11,8 @@ import (
 
 	models "github.com/rk280392/harvesterNavigator/internal/models"
 	"github.com/rk280392/harvesterNavigator/internal/services/batch"
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
+	"github.com/rk280392/harvesterNavigator/internal/services/diagnostics"
 	"github.com/rk280392/harvesterNavigator/internal/services/engine"
 	"github.com/rk280392/harvesterNavigator/internal/services/health"
 	"github.com/rk280392/harvesterNavigator/internal/services/lhva"
@@ -17,11 +20,14 @@ import (
 	"github.com/rk280392/harvesterNavigator/internal/services/pdb"
 	"github.com/rk280392/harvesterNavigator/internal/services/pod"
 	"github.com/rk280392/harvesterNavigator/internal/services/replicas"
+	"github.com/rk280392/harvesterNavigator/internal/services/source"
 	"github.com/rk280392/harvesterNavigator/internal/services/upgrade"
 	"github.com/rk280392/harvesterNavigator/internal/services/vm"
 	"github.com/rk280392/harvesterNavigator/internal/services/vmi"
 	"github.com/rk280392/harvesterNavigator/internal/services/vmim"
 	"github.com/rk280392/harvesterNavigator/internal/services/volume"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
@@ -29,18 +35,35 @@ import (
 type DataFetcher struct {
 	client        *kubernetes.Clientset
 	dynamicClient dynamic.Interface
+	source        source.Source
 	batchFetcher  *batch.BatchFetcher
 	volumeService *volume.VolumeService
 	pdbChecker    *pdb.HealthChecker
+	diagnostics   *diagnostics.Engine
+	healthConfig  health.Config
+
+	// healthSummary and pdbHealth are the results of the last health check
+	// run, by node for the PDB checks
+	healthMutex   sync.Mutex
+	healthSummary *models.HealthCheckSummary
+	pdbHealth     map[string]*models.PDBHealthStatus
 }
 
-func CreateDataFetcher(clientset *kubernetes.Clientset, dynamicClient dynamic.Interface) *DataFetcher {
+// CreateDataFetcher creates a data fetcher that reads resources from src and
+// falls back to the API server for anything src cannot serve. With a nil
+// clientset it works purely offline, e.g. over a support bundle. It is safe to
+// share across requests. healthConfig selects the health checks, which are
+// run by runHealthChecks rather than on each fetch.
+func CreateDataFetcher(clientset *kubernetes.Clientset, dynamicClient dynamic.Interface, src source.Source, healthConfig health.Config) *DataFetcher {
 	return &DataFetcher{
 		client:        clientset,
 		dynamicClient: dynamicClient,
-		batchFetcher:  batch.CreateBatchFetcher(clientset),
-		volumeService: volume.CreateVolumeService(clientset),
+		source:        src,
+		batchFetcher:  batch.CreateBatchFetcher(clientset, src),
+		volumeService: volume.CreateVolumeService(clientset, src),
 		pdbChecker:    pdb.NewHealthChecker(clientset, dynamicClient),
+		diagnostics:   diagnostics.CreateEngine(diagnostics.DefaultRules()...),
+		healthConfig:  healthConfig,
 	}
 }
 
@@ -48,23 +71,13 @@ func (df *DataFetcher) fetchFullClusterData() (models.FullClusterData, error) {
 	var allData models.FullClusterData
 	start := time.Now()
 
-	upgradeInfo, err := upgrade.FetchLatestUpgrade(df.client)
-	if err != nil {
-		log.Printf("Warning: could not fetch upgrade information: %v", err)
-		upgradeInfo = nil
-	} else {
-		allData.UpgradeInfo = upgradeInfo
-		log.Printf("Upgrade info: %s -> %s (%s)",
-			upgradeInfo.PreviousVersion, upgradeInfo.Version, upgradeInfo.State)
-	}
 	log.Println("Starting cluster data fetch...")
 
-	log.Println("Running health checks...")
-	healthChecker := health.CreateHealthChecker(df.client, upgradeInfo)
-	healthSummary := healthChecker.RunAllChecks(context.Background())
-	allData.HealthChecks = healthSummary
-	log.Printf("Health checks completed: %d passed, %d failed, %d warnings",
-		healthSummary.PassedChecks, healthSummary.FailedChecks, healthSummary.WarningChecks)
+	if df.client != nil {
+		allData.HealthChecks, _ = df.lastHealth()
+	} else {
+		log.Println("Skipping health checks: no API client (offline mode)")
+	}
 
 	var nodeWg sync.WaitGroup
 	nodeWg.Add(1)
@@ -78,7 +91,7 @@ func (df *DataFetcher) fetchFullClusterData() (models.FullClusterData, error) {
 	nodeWg.Add(1)
 	go func() {
 		defer nodeWg.Done()
-		upgradeInfo, err := upgrade.FetchLatestUpgrade(df.client)
+		upgradeInfo, err := df.fetchUpgrade()
 		if err != nil {
 			log.Printf("Warning: could not fetch upgrade information: %v", err)
 		} else {
@@ -109,18 +122,22 @@ func (df *DataFetcher) fetchFullClusterData() (models.FullClusterData, error) {
 		diskHealthByNode[n.NodeInfo.Name] = diskMap
 	}
 
-	// Enrich replica info with disk health (disk pressure, schedulability)
+	// Enrich replica info of every VM disk with node disk health (disk pressure, schedulability)
 	for i := range allData.VMs {
-		for j := range allData.VMs[i].ReplicaInfo {
-			r := &allData.VMs[i].ReplicaInfo[j]
-			if diskMap, ok := diskHealthByNode[r.NodeID]; ok {
-				if disk, ok := diskMap[r.DiskID]; ok {
-					r.DiskSchedulable = disk.IsSchedulable
-					r.DiskPressure = !disk.IsSchedulable && disk.PressureReason == "DiskPressure"
-					r.DiskPressureMsg = disk.PressureMessage
+		for d := range allData.VMs[i].Disks {
+			replicaInfo := allData.VMs[i].Disks[d].ReplicaInfo
+			for j := range replicaInfo {
+				r := &replicaInfo[j]
+				if diskMap, ok := diskHealthByNode[r.NodeID]; ok {
+					if disk, ok := diskMap[r.DiskID]; ok {
+						r.DiskSchedulable = disk.IsSchedulable
+						r.DiskPressure = !disk.IsSchedulable && disk.PressureReason == "DiskPressure"
+						r.DiskPressureMsg = disk.PressureMessage
+					}
 				}
 			}
 		}
+		mirrorPrimaryDisk(&allData.VMs[i])
 	}
 
 	// Cross-reference stuck Pre-draining nodes with VM migration data
@@ -141,19 +158,77 @@ func (df *DataFetcher) fetchFullClusterData() (models.FullClusterData, error) {
 		}
 	}
 
+	allData.Issues = df.diagnostics.Run(&allData)
+
 	elapsed := time.Since(start)
 	log.Printf("Cluster data fetch completed in %v", elapsed)
 	return allData, nil
 }
 
+// runHealthChecks runs the health checks now and then every interval until
+// ctx is done, so that fetches serve the last results from memory
+func (df *DataFetcher) runHealthChecks(ctx context.Context, interval time.Duration) {
+	df.lastHealth()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			summary, pdbHealth := df.checkHealth(ctx)
+			df.healthMutex.Lock()
+			df.healthSummary, df.pdbHealth = summary, pdbHealth
+			df.healthMutex.Unlock()
+		}
+	}
+}
+
+// lastHealth returns the health summary and the PDB health by node of the
+// last health check run. The first caller runs the checks, e.g. for a
+// headless command, and any other caller waits for that run.
+func (df *DataFetcher) lastHealth() (*models.HealthCheckSummary, map[string]*models.PDBHealthStatus) {
+	df.healthMutex.Lock()
+	defer df.healthMutex.Unlock()
+	if df.healthSummary == nil {
+		df.healthSummary, df.pdbHealth = df.checkHealth(context.Background())
+	}
+	return df.healthSummary, df.pdbHealth
+}
+
+// checkHealth runs every enabled health check and the PDB checks of every
+// node against the API server
+func (df *DataFetcher) checkHealth(ctx context.Context) (*models.HealthCheckSummary, map[string]*models.PDBHealthStatus) {
+	upgradeInfo, err := df.fetchUpgrade()
+	if err != nil {
+		log.Printf("Warning: could not fetch upgrade information: %v", err)
+		upgradeInfo = nil
+	}
+
+	log.Println("Running health checks...")
+	healthChecker := health.CreateHealthChecker(df.client, df.dynamicClient, upgradeInfo, df.healthConfig)
+	summary := healthChecker.RunAllChecks(ctx)
+	log.Printf("Health checks completed: %d passed, %d failed, %d warnings, %d timed out",
+		summary.PassedChecks, summary.FailedChecks, summary.WarningChecks, summary.TimeoutChecks)
+
+	log.Println("Checking PDB health for all nodes...")
+	pdbHealth, err := df.pdbChecker.CheckAllNodesPDB()
+	if err != nil {
+		log.Printf("Warning: Could not perform PDB health checks: %v", err)
+	} else {
+		log.Printf("PDB health checks completed for %d nodes", len(pdbHealth))
+	}
+	return summary, pdbHealth
+}
+
 // fetchNodeData fetches and processes node information
 func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 	log.Println("Fetching Longhorn node data...")
-	longhornNodes, err := vm.FetchAllLonghornNodes(df.client)
+	longhornNodes, err := df.listLonghornNodes()
 	if err != nil {
 		return err
 	}
-	log.Printf("Successfully fetched %d Longhorn node resources from API.", len(longhornNodes))
+	log.Printf("Successfully fetched %d Longhorn node resources.", len(longhornNodes))
 
 	parsedLonghornNodes, err := vm.ParseLonghornNodeData(longhornNodes)
 	if err != nil {
@@ -163,7 +238,7 @@ func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 
 	// Fetch Kubernetes node data
 	log.Println("Fetching Kubernetes node data...")
-	kubernetesNodes, err := node.FetchAllKubernetesNodes(df.client)
+	kubernetesNodes, err := df.listKubernetesNodes()
 	if err != nil {
 		log.Printf("Warning: Could not fetch Kubernetes node data: %v", err)
 		// Continue with just Longhorn data
@@ -183,7 +258,7 @@ func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 
 		// Fetch running pod counts efficiently
 		log.Println("Fetching running pod counts...")
-		podCounts, err := node.FetchRunningPodCounts(df.client)
+		podCounts, err := df.countRunningPods()
 		if err != nil {
 			log.Printf("Warning: Could not fetch pod counts: %v", err)
 			podCounts = make(map[string]int)
@@ -191,13 +266,10 @@ func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 			log.Printf("Successfully fetched pod counts for %d nodes.", len(podCounts))
 		}
 
-		log.Println("Checking PDB health for all nodes...")
-		pdbHealthResults, err := df.pdbChecker.CheckAllNodesPDB()
-		if err != nil {
-			log.Printf("Warning: Could not perform PDB health checks: %v", err)
-			pdbHealthResults = make(map[string]*models.PDBHealthStatus)
-		} else {
-			log.Printf("PDB health checks completed for %d nodes", len(pdbHealthResults))
+		// PDB health is checked with the health checks, on their interval
+		var pdbHealthResults map[string]*models.PDBHealthStatus
+		if df.client != nil {
+			_, pdbHealthResults = df.lastHealth()
 		}
 		// Merge node data
 		mergedNodes := make([]models.NodeWithMetrics, len(parsedLonghornNodes))
@@ -215,21 +287,15 @@ func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 			}
 			mergedNodes[i] = nodeWithMetrics
 		}
-		log.Println("Checking PDB health for all nodes...")
 
 		allData.Nodes = mergedNodes
 		log.Printf("Successfully merged node data for %d nodes.", len(mergedNodes))
 	}
 
-	// Fetch CPU feature labels from nodes
-	log.Println("Fetching CPU feature labels from nodes...")
-	cpuLabels, err := node.FetchNodeCPULabels(df.client)
-	if err != nil {
-		log.Printf("Warning: Could not fetch CPU feature labels: %v", err)
-	} else {
-		allData.NodeCPULabels = cpuLabels
-		log.Printf("Successfully fetched CPU labels for %d nodes", len(cpuLabels))
-	}
+	// Extract CPU feature labels from the nodes listed above
+	cpuLabels := node.ExtractNodeCPULabels(kubernetesNodes)
+	allData.NodeCPULabels = cpuLabels
+	log.Printf("Successfully fetched CPU labels for %d nodes", len(cpuLabels))
 
 	return nil
 }
@@ -238,15 +304,16 @@ func (df *DataFetcher) fetchNodeData(allData *models.FullClusterData) error {
 func (df *DataFetcher) fetchVMData() ([]models.VMInfo, error) {
 	log.Println("Fetching VM data with batch processing...")
 
-	vmList, err := vm.FetchAllVMData(df.client, "apis/kubevirt.io/v1", "", "virtualmachines")
+	vmList, err := df.listResource(cache.VirtualMachines, "", func() ([]map[string]interface{}, error) {
+		return vm.FetchAllVMData(df.client, "apis/kubevirt.io/v1", "", "virtualmachines")
+	})
 	if err != nil {
 		return nil, err
 	}
 	log.Printf("Found %d VMs. Processing with batch operations...", len(vmList))
 	var pvcRequests []batch.PVCRequest
-	vmToPVC := make(map[int]string) // VM index to PVC name mapping
 
-	for i, vmData := range vmList {
+	for _, vmData := range vmList {
 		vmInfo := &models.VMInfo{Errors: []models.VMError{}}
 
 		metadata, ok := vmData["metadata"].(map[string]interface{})
@@ -259,18 +326,17 @@ func (df *DataFetcher) fetchVMData() ([]models.VMInfo, error) {
 		vmInfo.Namespace = namespace
 		vmInfo.Name = vmName
 
-		// Parse VM metadata to get PVC name
+		// Parse VM metadata to get the PVC of every disk
 		if err := vm.ParseVMMetaData(vmData, vmInfo); err != nil {
 			log.Printf("Warning: Could not parse VM metadata for %s: %v", vmName, err)
 			continue
 		}
 
-		if vmInfo.ClaimNames != "" {
+		for _, disk := range vmInfo.Disks {
 			pvcRequests = append(pvcRequests, batch.PVCRequest{
-				Name:      vmInfo.ClaimNames,
+				Name:      disk.ClaimName,
 				Namespace: namespace,
 			})
-			vmToPVC[i] = vmInfo.ClaimNames
 		}
 	}
 
@@ -307,7 +373,7 @@ func (df *DataFetcher) fetchVMData() ([]models.VMInfo, error) {
 			batchVMs := make([]models.VMInfo, 0, end-start)
 
 			for j := start; j < end; j++ {
-				vmInfo := df.processVMWithBatchedData(j, vmList[j], vmToPVC, volumeDetails, podMapping)
+				vmInfo := df.processVMWithBatchedData(vmList[j], volumeDetails, podMapping)
 				if vmInfo != nil {
 					batchVMs = append(batchVMs, *vmInfo)
 				}
@@ -326,9 +392,7 @@ func (df *DataFetcher) fetchVMData() ([]models.VMInfo, error) {
 
 // processVMWithBatchedData processes a single VM using pre-fetched batch data
 func (df *DataFetcher) processVMWithBatchedData(
-	vmIndex int,
 	vmData map[string]interface{},
-	vmToPVC map[int]string,
 	volumeDetails map[string]*volume.VolumeDetails,
 	podMapping map[string]string,
 ) *models.VMInfo {
@@ -356,47 +420,21 @@ func (df *DataFetcher) processVMWithBatchedData(
 	}
 
 	// Skip if no PVC
-	if vmInfo.ClaimNames == "" {
+	if len(vmInfo.Disks) == 0 {
 		return vmInfo
 	}
 
-	// Get volume details from batch data
-	pvcKey := fmt.Sprintf("pvc-%s-%s", namespace, vmInfo.ClaimNames)
-	if volDetails, exists := volumeDetails[pvcKey]; exists {
-		vmInfo.VolumeName = volDetails.VolumeHandle
-		vmInfo.PVCStatus = models.PVCStatus(volDetails.Status)
-		vmInfo.StorageClass = volDetails.StorageClass
-		vmInfo.VolumeRobustness = volDetails.Robustness
-		vmInfo.VolumeState = volDetails.State
-		vmInfo.VolumeNumberOfReplicas = volDetails.NumberOfReplicas
-		if vmInfo.VolumeName != "" {
-			paths := getDefaultResourcePaths(namespace)
-			lhvaData, err := lhva.FetchLHVAData(df.client, vmInfo.VolumeName, paths.LHVAPath, "longhorn-system", "volumeattachments")
-			if err != nil {
-				log.Printf("Failed to fetch LHVA data for %s: %v", vmInfo.VolumeName, err)
-			} else {
-				lhvaStatus, err := lhva.ParseLHVAStatus(lhvaData)
-				if err == nil {
-					vmInfo.AttachmentTicketsStatusRaw = lhvaStatus
-				}
-				lhvaSpec, err := lhva.ParseLHVASpec(lhvaData)
-				if err == nil {
-					vmInfo.AttachmentTicketsSpecRaw = lhvaSpec
-				}
-			}
-		}
-
-		// Pod collection will happen after VMI processing
-
-		// Process Longhorn-specific data if available
-		if volDetails.IsLonghornCSI && volDetails.VolumeHandle != "" {
-			df.processLonghornData(vmInfo, volDetails.VolumeHandle)
-		}
+	// Get volume details of every disk from batch data
+	for i := range vmInfo.Disks {
+		df.processDisk(vmInfo, &vmInfo.Disks[i], volumeDetails)
 	}
+	mirrorPrimaryDisk(vmInfo)
 
 	// Fetch VMI details (still individual calls but much fewer)
 	paths := getDefaultResourcePaths(namespace)
-	vmiData, err := vmi.FetchVMIDetails(df.client, vmInfo.Name, paths.VMIPath, namespace, "virtualmachineinstances")
+	vmiData, err := df.getResource(cache.VirtualMachineInstances, namespace, vmInfo.Name, func() (map[string]interface{}, error) {
+		return vmi.FetchVMIDetails(df.client, vmInfo.Name, paths.VMIPath, namespace, "virtualmachineinstances")
+	})
 	if err != nil {
 		// Check if it's a "not found" error for terminating VMs
 		if strings.Contains(err.Error(), "could not find the requested resource") ||
@@ -425,7 +463,7 @@ func (df *DataFetcher) processVMWithBatchedData(
 			})
 		}
 		// If VMI fetch fails, try VMIM directly
-		vmimDataList, err := vmim.FetchAllVMIMsForVMI(df.client, vmInfo.Name, paths.VMIMPath, namespace)
+		vmimDataList, err := df.fetchVMIMsForVMI(vmInfo.Name, namespace)
 		if err == nil && len(vmimDataList) > 0 {
 			vmimStatus, err := vmim.ParseVMIMData(vmimDataList, df.client)
 			if err == nil {
@@ -433,7 +471,7 @@ func (df *DataFetcher) processVMWithBatchedData(
 			}
 		}
 	} else {
-		vmiStatus, err := vmi.ParseVMIData(df.client, vmiData, namespace)
+		vmiStatus, err := vmi.ParseVMIDataWithLookup(vmiData, namespace, df.lookupPodNames)
 		if err != nil {
 			vmInfo.Errors = append(vmInfo.Errors, models.VMError{
 				Type:     "vmi-parse",
@@ -447,7 +485,7 @@ func (df *DataFetcher) processVMWithBatchedData(
 	}
 
 	// Fetch VMIM details (migrations for this VMI)
-	vmimDataList, err := vmim.FetchAllVMIMsForVMI(df.client, vmInfo.Name, paths.VMIMPath, namespace)
+	vmimDataList, err := df.fetchVMIMsForVMI(vmInfo.Name, namespace)
 	if err != nil {
 		vmInfo.Errors = append(vmInfo.Errors, models.VMError{
 			Type:     "vmim",
@@ -467,16 +505,16 @@ func (df *DataFetcher) processVMWithBatchedData(
 	// Get pod information for all active pods (post-VMI processing)
 	if len(vmInfo.VMIInfo) > 0 && vmInfo.VMIInfo[0].ActivePodNames != nil {
 		var allPodInfo []models.PodInfo
-		paths := getDefaultResourcePaths(namespace)
 
 		// Fetch details for each active pod
 		for podUID, podName := range vmInfo.VMIInfo[0].ActivePodNames {
 			if podName != "" {
-				podData, err := pod.FetchPodDetails(df.client, podName, paths.PodPath, namespace, "pods")
+				podData, err := df.fetchPod(podName, namespace)
 				if err != nil {
 					// Add fallback pod info with unknown status
 					nodeID := vmInfo.VMIInfo[0].ActivePods[podUID]
 					allPodInfo = append(allPodInfo, models.PodInfo{
+						Name:   podName,
 						VMI:    vmInfo.Name,
 						NodeID: nodeID,
 						Status: "Unknown",
@@ -491,14 +529,13 @@ func (df *DataFetcher) processVMWithBatchedData(
 		}
 		vmInfo.PodInfo = allPodInfo
 	} else {
-		// Fallback to original single pod logic if no VMI info
-		if podName, exists := podMapping[pvcKey]; exists {
+		// Fallback to the pod mounting any of the VM's disks if no VMI info
+		if podName, exists := findDiskPod(vmInfo, podMapping); exists {
 			vmInfo.PodName = podName
 
 			// Fetch pod details if needed
 			if podName != "" {
-				paths := getDefaultResourcePaths(namespace)
-				podData, err := pod.FetchPodDetails(df.client, podName, paths.PodPath, namespace, "pods")
+				podData, err := df.fetchPod(podName, namespace)
 				if err != nil {
 					vmInfo.Errors = append(vmInfo.Errors, models.VMError{
 						Type:     "pod",
@@ -517,8 +554,196 @@ func (df *DataFetcher) processVMWithBatchedData(
 	return vmInfo
 }
 
+// canFallBack reports whether a source error should be retried against the API server
+func (df *DataFetcher) canFallBack(err error) bool {
+	return errors.Is(err, cache.ErrNotSynced) && df.client != nil
+}
+
+// listResource lists a resource from the resource source, or calls fetch when
+// the source cannot serve it
+func (df *DataFetcher) listResource(gvr schema.GroupVersionResource, namespace string, fetch func() ([]map[string]interface{}, error)) ([]map[string]interface{}, error) {
+	items, err := df.source.List(gvr, namespace)
+	if df.canFallBack(err) {
+		return fetch()
+	}
+	return items, err
+}
+
+// getResource gets a single object from the resource source, or calls fetch
+// when the source cannot serve it
+func (df *DataFetcher) getResource(gvr schema.GroupVersionResource, namespace, name string, fetch func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	obj, err := df.source.Get(gvr, namespace, name)
+	if df.canFallBack(err) {
+		return fetch()
+	}
+	return obj, err
+}
+
+// fetchUpgrade returns the most recent Harvester upgrade
+func (df *DataFetcher) fetchUpgrade() (*models.UpgradeInfo, error) {
+	upgrades, err := df.source.List(cache.Upgrades, "harvester-system")
+	if df.canFallBack(err) {
+		return upgrade.FetchLatestUpgrade(df.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return upgrade.LatestUpgradeFromList(source.ToItems(upgrades))
+}
+
+// listLonghornNodes returns all nodes.longhorn.io objects
+func (df *DataFetcher) listLonghornNodes() ([]interface{}, error) {
+	nodes, err := df.source.List(cache.LonghornNodes, "longhorn-system")
+	if df.canFallBack(err) {
+		return vm.FetchAllLonghornNodes(df.client)
+	}
+	return source.ToItems(nodes), err
+}
+
+// listKubernetesNodes returns all core Kubernetes nodes
+func (df *DataFetcher) listKubernetesNodes() ([]interface{}, error) {
+	nodes, err := df.source.List(cache.Nodes, "")
+	if df.canFallBack(err) {
+		return node.FetchAllKubernetesNodes(df.client)
+	}
+	return source.ToItems(nodes), err
+}
+
+// countRunningPods returns the number of running pods per node
+func (df *DataFetcher) countRunningPods() (map[string]int, error) {
+	pods, err := df.source.List(cache.Pods, "")
+	if df.canFallBack(err) {
+		return node.FetchRunningPodCounts(df.client)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node.CountRunningPods(source.ToItems(pods)), nil
+}
+
+// listCSIVolumeAttachments returns all storage.k8s.io VolumeAttachments
+func (df *DataFetcher) listCSIVolumeAttachments() ([]map[string]interface{}, error) {
+	return df.listResource(cache.CSIVolumeAttachments, "", func() ([]map[string]interface{}, error) {
+		list, err := df.dynamicClient.Resource(cache.CSIVolumeAttachments).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list CSI volume attachments: %w", err)
+		}
+		items := make([]map[string]interface{}, 0, len(list.Items))
+		for _, item := range list.Items {
+			items = append(items, item.Object)
+		}
+		return items, nil
+	})
+}
+
+// fetchVMIMsForVMI returns all migrations of a VMI
+func (df *DataFetcher) fetchVMIMsForVMI(vmiName, namespace string) ([]map[string]interface{}, error) {
+	vmims, err := df.source.List(cache.VirtualMachineInstanceMigrations, namespace)
+	if df.canFallBack(err) {
+		return vmim.FetchAllVMIMsForVMI(df.client, vmiName, getDefaultResourcePaths(namespace).VMIMPath, namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vmim.FilterVMIMsForVMI(vmims, vmiName), nil
+}
+
+// fetchPod returns a single pod
+func (df *DataFetcher) fetchPod(name, namespace string) (map[string]interface{}, error) {
+	return df.getResource(cache.Pods, namespace, name, func() (map[string]interface{}, error) {
+		return pod.FetchPodDetails(df.client, name, getDefaultResourcePaths(namespace).PodPath, namespace, "pods")
+	})
+}
+
+// lookupPodNames resolves virt-launcher pod names for a VMI
+func (df *DataFetcher) lookupPodNames(namespace, vmName string, nodeToUID map[string]string) (map[string]string, error) {
+	pods, err := df.source.List(cache.Pods, namespace)
+	if df.canFallBack(err) {
+		return vmi.FetchPodNamesForVM(df.client, namespace, vmName, nodeToUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vmi.MatchPodNamesForVM(pods, vmName, nodeToUID), nil
+}
+
+// processDisk fills a disk's PVC, PV, Longhorn volume and attachment details from batch data
+func (df *DataFetcher) processDisk(vmInfo *models.VMInfo, disk *models.VMDisk, volumeDetails map[string]*volume.VolumeDetails) {
+	pvcKey := fmt.Sprintf("pvc-%s-%s", vmInfo.Namespace, disk.ClaimName)
+	volDetails, exists := volumeDetails[pvcKey]
+	if !exists {
+		return
+	}
+
+	disk.PVName = volDetails.PVName
+	disk.VolumeName = volDetails.VolumeHandle
+	disk.PVCStatus = models.PVCStatus(volDetails.Status)
+	if volDetails.StorageClass != "" {
+		disk.StorageClass = volDetails.StorageClass
+	}
+	disk.VolumeRobustness = volDetails.Robustness
+	disk.VolumeState = volDetails.State
+	disk.VolumeNumberOfReplicas = volDetails.NumberOfReplicas
+	disk.VolumeNodeID = volDetails.CurrentNodeID
+	disk.VolumeMigrationNodeID = volDetails.MigrationNodeID
+
+	if disk.VolumeName != "" {
+		paths := getDefaultResourcePaths(vmInfo.Namespace)
+		lhvaData, err := df.getResource(cache.LonghornVolumeAttachments, "longhorn-system", disk.VolumeName, func() (map[string]interface{}, error) {
+			return lhva.FetchLHVAData(df.client, disk.VolumeName, paths.LHVAPath, "longhorn-system", "volumeattachments")
+		})
+		if err != nil {
+			log.Printf("Failed to fetch LHVA data for %s: %v", disk.VolumeName, err)
+		} else {
+			lhvaStatus, err := lhva.ParseLHVAStatus(lhvaData)
+			if err == nil {
+				disk.AttachmentTicketsStatusRaw = lhvaStatus
+			}
+			lhvaSpec, err := lhva.ParseLHVASpec(lhvaData)
+			if err == nil {
+				disk.AttachmentTicketsSpecRaw = lhvaSpec
+			}
+		}
+	}
+
+	// Process Longhorn-specific data if available
+	if volDetails.IsLonghornCSI && volDetails.VolumeHandle != "" {
+		df.processLonghornData(disk, volDetails.VolumeHandle)
+	}
+}
+
+// mirrorPrimaryDisk copies the first disk into the VM's top-level volume fields
+func mirrorPrimaryDisk(vmInfo *models.VMInfo) {
+	if len(vmInfo.Disks) == 0 {
+		return
+	}
+	primary := vmInfo.Disks[0]
+	vmInfo.ClaimNames = primary.ClaimName
+	vmInfo.VolumeName = primary.VolumeName
+	vmInfo.PVCStatus = primary.PVCStatus
+	vmInfo.StorageClass = primary.StorageClass
+	vmInfo.VolumeRobustness = primary.VolumeRobustness
+	vmInfo.VolumeState = primary.VolumeState
+	vmInfo.VolumeNumberOfReplicas = primary.VolumeNumberOfReplicas
+	vmInfo.ReplicaInfo = primary.ReplicaInfo
+	vmInfo.EngineInfo = primary.EngineInfo
+	vmInfo.AttachmentTicketsStatusRaw = primary.AttachmentTicketsStatusRaw
+	vmInfo.AttachmentTicketsSpecRaw = primary.AttachmentTicketsSpecRaw
+}
+
+// findDiskPod returns the pod mounting any of the VM's disks, trying disks in order
+func findDiskPod(vmInfo *models.VMInfo, podMapping map[string]string) (string, bool) {
+	for _, disk := range vmInfo.Disks {
+		pvcKey := fmt.Sprintf("pvc-%s-%s", vmInfo.Namespace, disk.ClaimName)
+		if podName, exists := podMapping[pvcKey]; exists {
+			return podName, true
+		}
+	}
+	return "", false
+}
+
 // processLonghornData processes Longhorn-specific data using batch-fetched information
-func (df *DataFetcher) processLonghornData(vmInfo *models.VMInfo, volumeHandle string) {
+func (df *DataFetcher) processLonghornData(disk *models.VMDisk, volumeHandle string) {
 	// Get replica details from batch data
 	replicaData := df.volumeService.GetReplicaDetails(volumeHandle)
 	if len(replicaData) > 0 {
@@ -528,7 +753,7 @@ func (df *DataFetcher) processLonghornData(vmInfo *models.VMInfo, volumeHandle s
 				replicaInfos = append(replicaInfos, replicaInfo)
 			}
 		}
-		vmInfo.ReplicaInfo = replicaInfos
+		disk.ReplicaInfo = replicaInfos
 	}
 
 	// Get engine details from batch data
@@ -540,7 +765,7 @@ func (df *DataFetcher) processLonghornData(vmInfo *models.VMInfo, volumeHandle s
 				engineInfos = append(engineInfos, engineInfo)
 			}
 		}
-		vmInfo.EngineInfo = engineInfos
+		disk.EngineInfo = engineInfos
 	}
 }
 
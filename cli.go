@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/patternengine"
+	"github.com/rk280392/harvesterNavigator/pkg/display"
+	"sigs.k8s.io/yaml"
+)
+
+// command is a headless subcommand. It runs against a single fetch of the
+// cluster data and prints it instead of serving the dashboard.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error
+}
+
+var commands = []command{
+	{name: "vm", usage: "vm [flags] <namespace>/<name>", summary: "Show a VM with its disks, replicas and issues", run: runVMCommand},
+	{name: "nodes", usage: "nodes [flags]", summary: "List nodes with readiness, disks and PDB issues", run: runNodesCommand},
+	{name: "health", usage: "health [flags]", summary: "Run the cluster health checks", run: runHealthCommand},
+	{name: "issues", usage: "issues [flags]", summary: "List detected issues", run: runIssuesCommand},
+	{name: "diff", usage: "diff [flags] <from> <to>", summary: "Compare two cluster snapshots, e.g. before and after an upgrade", run: runDiffCommand},
+	{name: "analyze", usage: "analyze [flags] <log file|->", summary: "Run the log pattern engine over a log file and report every finding", run: runAnalyzeCommand},
+	{name: "patterns", usage: "patterns test [flags] <corpus dir>", summary: "Check the log patterns, with any -patterns-dir, against a corpus of log samples", run: runPatternsCommand},
+}
+
+// errUsage reports a command line mistake, after which usage is printed
+var errUsage = errors.New("invalid usage")
+
+// runCommand runs the subcommand named by args[0] and returns the process
+// exit code. fetch is only called once the arguments are valid.
+func runCommand(args []string, fetch func() (types.FullClusterData, error)) int {
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == args[0] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printCommands(os.Stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	output := fs.String("o", "table", "Output format: table, json or yaml")
+	// Parse errors are reported once, with usage, below
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: harvesterNavigator [global flags] %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.summary)
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	err := cmd.run(fs, output, args[1:], fetch)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(os.Stderr, "Error: %v\n\n", err)
+		fs.Usage()
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+}
+
+// printCommands lists the available subcommands
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nWithout a command the web dashboard is served.")
+}
+
+// parseCommandFlags parses flags that may appear before or after positional
+// arguments and returns the positional arguments
+func parseCommandFlags(fs *flag.FlagSet, output *string, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch *output {
+	case "table", "json", "yaml":
+	default:
+		return nil, fmt.Errorf("%w: unknown output format %q", errUsage, *output)
+	}
+	return positional, nil
+}
+
+// writeOutput prints v as JSON or YAML, or calls table for the table format
+func writeOutput(format string, v interface{}, table func()) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	case "yaml":
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		fmt.Print(string(data))
+	default:
+		table()
+	}
+	return nil
+}
+
+func runVMCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	namespace := fs.String("n", "default", "Namespace of the VM when not given as <namespace>/<name>")
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("%w: expected exactly one VM", errUsage)
+	}
+
+	name := positional[0]
+	if ns, vmName, ok := strings.Cut(name, "/"); ok {
+		*namespace, name = ns, vmName
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return err
+	}
+
+	var vm *types.VMInfo
+	for i := range data.VMs {
+		if data.VMs[i].Namespace == *namespace && data.VMs[i].Name == name {
+			vm = &data.VMs[i]
+			break
+		}
+	}
+	if vm == nil {
+		return fmt.Errorf("VM %s/%s not found", *namespace, name)
+	}
+
+	var vmIssues []types.Issue
+	for _, issue := range data.Issues {
+		if issue.VMName == vm.Name && issue.VMNamespace == vm.Namespace {
+			vmIssues = append(vmIssues, issue)
+		}
+	}
+
+	return writeOutput(*output, vm, func() {
+		display.DisplayVMInfo(vm)
+		if len(vmIssues) > 0 {
+			fmt.Println("\nISSUES:")
+			display.DisplayIssues(vmIssues)
+		}
+	})
+}
+
+func runNodesCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, data.Nodes, func() {
+		display.DisplayNodes(data.Nodes)
+	})
+}
+
+func runHealthCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return err
+	}
+	if data.HealthChecks == nil {
+		return errors.New("health checks are not available for this data source")
+	}
+	return writeOutput(*output, data.HealthChecks, func() {
+		display.DisplayHealthChecks(data.HealthChecks)
+	})
+}
+
+func runIssuesCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	severity := fs.String("severity", "", "Only show issues of these comma-separated severities, e.g. critical,high")
+	details := fs.Bool("details", false, "Show evidence and verification and remediation steps")
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, positional[0])
+	}
+
+	data, err := fetch()
+	if err != nil {
+		return err
+	}
+
+	issues := data.Issues
+	if *severity != "" {
+		wanted := make(map[string]bool)
+		for _, s := range strings.Split(*severity, ",") {
+			wanted[strings.ToLower(strings.TrimSpace(s))] = true
+		}
+		issues = []types.Issue{}
+		for _, issue := range data.Issues {
+			if wanted[issue.Severity] {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return writeOutput(*output, issues, func() {
+		if *details {
+			display.DisplayIssueDetails(issues)
+			return
+		}
+		display.DisplayIssues(issues)
+	})
+}
+
+// runAnalyzeCommand reads logs from a file, or stdin for "-", and only
+// connects to the cluster to resolve resources
+func runAnalyzeCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	minSeverity := fs.String("min-severity", "", "Only report findings at least this severe: critical, warning or info")
+	minConfidence := fs.String("min-confidence", "", "Only report findings at least this confident: certain, likely or possible")
+	maxHints := fs.Int("max-hints", 0, "Report at most this many findings (0 for all)")
+	includeInfo := fs.Bool("include-info", true, "Report info findings")
+	resolve := fs.Bool("resolve", false, "Look up the volumes, replicas, engines, nodes and pods named in the logs in the cluster (or -bundle) and report whether they are still unhealthy")
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("%w: expected exactly one log file", errUsage)
+	}
+	opts, err := analysisOptions(*minSeverity, *minConfidence, *maxHints, *includeInfo)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	var logs []byte
+	if positional[0] == "-" {
+		logs, err = io.ReadAll(os.Stdin)
+	} else {
+		logs, err = os.ReadFile(positional[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	result, err := patternengine.NewAnalyzer().Analyze(context.Background(), string(logs), opts)
+	if err != nil {
+		return err
+	}
+	if *resolve {
+		data, err := fetch()
+		if err != nil {
+			return err
+		}
+		patternengine.ResolveResources(result, &data)
+	}
+	return writeOutput(*output, result, func() {
+		display.DisplayAnalysis(result)
+	})
+}
+
+// runPatternsCommand checks the built-in and custom patterns against a
+// corpus, failing when any sample does not meet its expectation
+func runPatternsCommand(fs *flag.FlagSet, output *string, args []string, _ func() (types.FullClusterData, error)) error {
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 || positional[0] != "test" {
+		return fmt.Errorf("%w: expected test and a corpus directory", errUsage)
+	}
+
+	samples, err := patternengine.LoadCorpus(positional[1])
+	if err != nil {
+		return err
+	}
+	report, err := patternengine.RunCorpus(context.Background(), patternengine.NewAnalyzer(), samples)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(*output, report, func() {
+		display.DisplayCorpusReport(report)
+	}); err != nil {
+		return err
+	}
+	if failed := len(report.Failed()); failed > 0 {
+		return fmt.Errorf("%d of %d samples failed", failed, len(report.Samples))
+	}
+	return nil
+}
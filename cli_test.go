@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func cliTestData() models.FullClusterData {
+	return models.FullClusterData{
+		VMs: []models.VMInfo{
+			{Name: "vm1", Namespace: "default", PrintableStatus: "Running"},
+			{Name: "vm1", Namespace: "team-a", PrintableStatus: "Stopped"},
+		},
+		Nodes: []models.NodeWithMetrics{
+			{NodeInfo: models.NodeInfo{Name: "node-a"}, RunningPods: 10},
+			{NodeInfo: models.NodeInfo{Name: "node-b"}, RunningPods: 12},
+		},
+		HealthChecks: &models.HealthCheckSummary{
+			Results: []models.HealthCheckResult{{CheckName: "nodes", Status: "passed"}},
+		},
+		Issues: []models.Issue{
+			{ID: "issue-1", Title: "Replica faulted", Severity: "critical", VMName: "vm1", VMNamespace: "default"},
+			{ID: "issue-2", Title: "Disk pressure", Severity: "medium", NodeName: "node-b"},
+		},
+	}
+}
+
+// runCLI runs a subcommand against data, or fetchErr when set, and returns
+// its exit code and what it printed
+func runCLI(t *testing.T, args []string, data models.FullClusterData, fetchErr error) (code int, stdout, stderr string, fetched bool) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outFile.Close()
+	defer errFile.Close()
+
+	realStdout, realStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	code = runCommand(args, func() (models.FullClusterData, error) {
+		fetched = true
+		return data, fetchErr
+	})
+	os.Stdout, os.Stderr = realStdout, realStderr
+
+	out, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.ReadFile(errFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(out), string(errOut), fetched
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		health   bool // keep the health checks in the data
+		fetchErr error
+		wantCode int
+		// wantOut and wantErr must appear in stdout and stderr, wantMissing
+		// must not appear in stdout
+		wantOut     []string
+		wantMissing []string
+		wantErr     []string
+		// noFetch marks usage errors, which must not reach the cluster
+		noFetch bool
+	}{
+		{name: "unknown command", args: []string{"vms"}, wantCode: 2, wantErr: []string{`Unknown command "vms"`, "Commands:"}, noFetch: true},
+
+		{name: "vm json", args: []string{"vm", "-o", "json", "default/vm1"}, wantOut: []string{`"name": "vm1"`, `"printableStatus": "Running"`}},
+		{name: "vm flags after name", args: []string{"vm", "team-a/vm1", "-o", "yaml"}, wantOut: []string{"name: vm1", "printableStatus: Stopped"}},
+		{name: "vm namespace flag", args: []string{"vm", "-n", "team-a", "-o", "json", "vm1"}, wantOut: []string{`"namespace": "team-a"`}},
+		{name: "vm not found", args: []string{"vm", "default/vm2"}, wantCode: 1, wantErr: []string{"Error: VM default/vm2 not found"}},
+		{name: "vm without name", args: []string{"vm"}, wantCode: 2, wantErr: []string{"expected exactly one VM", "Usage: harvesterNavigator [global flags] vm"}, noFetch: true},
+		{name: "vm with two names", args: []string{"vm", "vm1", "vm2"}, wantCode: 2, wantErr: []string{"expected exactly one VM"}, noFetch: true},
+		{name: "vm unknown format", args: []string{"vm", "-o", "xml", "vm1"}, wantCode: 2, wantErr: []string{`unknown output format "xml"`}, noFetch: true},
+		{name: "vm help", args: []string{"vm", "-h"}, wantErr: []string{"Usage: harvesterNavigator [global flags] vm", "-n string"}, noFetch: true},
+
+		{name: "nodes table", args: []string{"nodes"}, wantOut: []string{"node-a", "node-b"}},
+		{name: "nodes json", args: []string{"nodes", "-o", "json"}, wantOut: []string{`"runningPods": 12`}},
+		{name: "nodes argument", args: []string{"nodes", "node-a"}, wantCode: 2, wantErr: []string{`unexpected argument "node-a"`}, noFetch: true},
+		{name: "nodes fetch failure", args: []string{"nodes"}, fetchErr: errors.New("connection refused"), wantCode: 1, wantErr: []string{"Error: connection refused"}},
+
+		{name: "health yaml", args: []string{"health", "-o", "yaml"}, health: true, wantOut: []string{"checkName: nodes"}},
+		{name: "health unavailable", args: []string{"health"}, wantCode: 1, wantErr: []string{"health checks are not available"}},
+
+		{name: "issues json", args: []string{"issues", "-o", "json"}, wantOut: []string{"issue-1", "issue-2"}},
+		{name: "issues by severity", args: []string{"issues", "-severity", "Critical, high", "-o", "json"}, wantOut: []string{"issue-1"}, wantMissing: []string{"issue-2"}},
+		{name: "issues table", args: []string{"issues"}, wantOut: []string{"Replica faulted", "Disk pressure"}},
+		{name: "issues unknown flag", args: []string{"issues", "-all"}, wantCode: 2, wantErr: []string{"flag provided but not defined: -all", "-severity string"}, noFetch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := cliTestData()
+			if !tt.health {
+				data.HealthChecks = nil
+			}
+			code, stdout, stderr, fetched := runCLI(t, tt.args, data, tt.fetchErr)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("stdout is missing %q:\n%s", want, stdout)
+				}
+			}
+			for _, unwanted := range tt.wantMissing {
+				if strings.Contains(stdout, unwanted) {
+					t.Errorf("stdout contains %q:\n%s", unwanted, stdout)
+				}
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("stderr is missing %q:\n%s", want, stderr)
+				}
+			}
+			if tt.noFetch && fetched {
+				t.Error("fetched cluster data for an invalid command line")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ type FullClusterData struct {
 	UpgradeInfo   *UpgradeInfo                 `json:"upgradeInfo,omitempty"`
 	HealthChecks  *HealthCheckSummary          `json:"healthChecks,omitempty"`
 	NodeCPULabels map[string]map[string]string `json:"nodeCPULabels,omitempty"`
+	Issues        []Issue                      `json:"issues"`
 }
 
 type UpgradeInfo struct {
@@ -118,9 +119,12 @@ type VMError struct {
 }
 
 // VMInfo represents complete information about a Virtual Machine and its related resources.
+// The top-level volume fields (ClaimNames, VolumeName, ReplicaInfo, ...) mirror
+// the first entry of Disks; Disks holds every PVC-backed disk of the VM.
 type VMInfo struct {
 	Name                       string        `json:"name"`
 	Namespace                  string        `json:"namespace"`
+	Disks                      []VMDisk      `json:"disks"`
 	ImageId                    string        `json:"imageId"`
 	PodName                    string        `json:"podName"`
 	StorageClass               string        `json:"storageClass"`
@@ -146,6 +150,50 @@ type VMInfo struct {
 	Errors                     []VMError     `json:"errors,omitempty"`
 }
 
+// VMDisk describes one PVC-backed disk of a VM and its storage backend
+type VMDisk struct {
+	Name                       string        `json:"name"`
+	ClaimName                  string        `json:"claimName"`
+	PVName                     string        `json:"pvName,omitempty"`
+	VolumeName                 string        `json:"volumeName"`
+	StorageClass               string        `json:"storageClass,omitempty"`
+	ImageId                    string        `json:"imageId,omitempty"`
+	Hotplug                    bool          `json:"hotplug"`
+	PVCStatus                  PVCStatus     `json:"pvcStatus"`
+	VolumeRobustness           string        `json:"volumeRobustness,omitempty"`
+	VolumeState                string        `json:"volumeState,omitempty"`
+	VolumeNumberOfReplicas     int           `json:"volumeNumberOfReplicas,omitempty"`
+	VolumeNodeID               string        `json:"volumeNodeId,omitempty"`          // node Longhorn attached the volume to
+	VolumeMigrationNodeID      string        `json:"volumeMigrationNodeId,omitempty"` // target of a live migration
+	ReplicaInfo                []ReplicaInfo `json:"replicaInfo"`
+	EngineInfo                 []EngineInfo  `json:"engineInfo"`
+	AttachmentTicketsStatusRaw any           `json:"attachmentTicketsStatusRaw,omitempty"`
+	AttachmentTicketsSpecRaw   any           `json:"attachmentTicketsSpecRaw,omitempty"`
+}
+
+// DiskViews returns the VM's disks. VMs without per-disk data, such as
+// snapshots saved before disks were modelled, yield one disk built from the
+// top-level volume fields.
+func (vm *VMInfo) DiskViews() []VMDisk {
+	if len(vm.Disks) > 0 {
+		return vm.Disks
+	}
+	return []VMDisk{{
+		ClaimName:                  vm.ClaimNames,
+		VolumeName:                 vm.VolumeName,
+		StorageClass:               vm.StorageClass,
+		ImageId:                    vm.ImageId,
+		PVCStatus:                  vm.PVCStatus,
+		VolumeRobustness:           vm.VolumeRobustness,
+		VolumeState:                vm.VolumeState,
+		VolumeNumberOfReplicas:     vm.VolumeNumberOfReplicas,
+		ReplicaInfo:                vm.ReplicaInfo,
+		EngineInfo:                 vm.EngineInfo,
+		AttachmentTicketsStatusRaw: vm.AttachmentTicketsStatusRaw,
+		AttachmentTicketsSpecRaw:   vm.AttachmentTicketsSpecRaw,
+	}}
+}
+
 // VMStatus represents the possible states of a Virtual Machine
 type VMStatus string
 
@@ -341,6 +389,7 @@ type HealthCheckSummary struct {
 	PassedChecks  int                 `json:"passedChecks"`
 	FailedChecks  int                 `json:"failedChecks"`
 	WarningChecks int                 `json:"warningChecks"`
+	TimeoutChecks int                 `json:"timeoutChecks"`
 	LastRun       time.Time           `json:"lastRun"`
 	Results       []HealthCheckResult `json:"results"`
 }
@@ -423,6 +472,7 @@ type ContainerError struct {
 
 type HealthCheckResult struct {
 	CheckName string     `json:"checkName"`
+	Category  string     `json:"category,omitempty"`
 	Status    string     `json:"status"`
 	Message   string     `json:"message,omitempty"`
 	Error     string     `json:"error,omitempty"`
@@ -442,7 +492,8 @@ type LogAnalysisRequest struct {
 	VolumeState      string `json:"volume_state,omitempty"`
 	ReplicaCount     int    `json:"replica_count,omitempty"`
 	FaultedCount     int    `json:"faulted_count,omitempty"`
-	SourceNode       string `json:"source_nade"`
+	NodeName         string `json:"node_name,omitempty"`
+	SourceNode       string `json:"source_node"`
 	TargetNode       string `json:"target_node"`
 	TimeWindow       string `json:"time_window"`
 	Provider         string `json:"provider"`
@@ -450,9 +501,14 @@ type LogAnalysisRequest struct {
 	// Multi-layered troubleshooting data
 	NodeDiskStatus  []NodeDiskInfo   `json:"node_disk_status,omitempty"`
 	ReplicaDetails  []ReplicaDetail  `json:"replica_details,omitempty"`
+	EngineDetails   []EngineDetail   `json:"engine_details,omitempty"`
 	PodDistribution []PodLocation    `json:"pod_distribution,omitempty"`
 	AttachmentState *AttachmentState `json:"attachment_state,omitempty"`
 	MigrationState  *MigrationState  `json:"migration_state,omitempty"`
+
+	// LogError is why no logs could be collected, told to the model in
+	// place of the logs
+	LogError string `json:"-"`
 }
 
 // NodeDiskInfo - Disk pressure and capacity info per node
@@ -474,6 +530,16 @@ type ReplicaDetail struct {
 	FailedAt string `json:"failed_at,omitempty"`
 	Started  bool   `json:"started"`
 	DiskPath string `json:"disk_path,omitempty"`
+	// InstanceManager is the instance-manager pod running the replica
+	InstanceManager string `json:"instance_manager,omitempty"`
+}
+
+// EngineDetail - Per-engine state and location
+type EngineDetail struct {
+	Name     string `json:"name"`
+	NodeName string `json:"node_name"`
+	State    string `json:"state"`
+	Active   bool   `json:"active"`
 }
 
 // PodLocation - Pod location and state for split-brain detection
@@ -511,4 +577,235 @@ type LogAnalysisResult struct {
 	Provider      string  `json:"provider"`
 	TokensUsed    int     `json:"tokens_used"`
 	EstimatedCost float64 `json:"estimated_cost"`
+
+	// FallbackReason is set when the LLM gave no valid answer and this is
+	// the pattern engine's result instead; RawResponse is the LLM's reply
+	FallbackReason string `json:"fallback_reason,omitempty"`
+	RawResponse    string `json:"raw_response,omitempty"`
+
+	// Prompt is what was sent to the LLM
+	Prompt *PromptInfo `json:"prompt,omitempty"`
+}
+
+// PromptInfo is a prompt built within a token budget, with what had to be
+// left out to fit
+type PromptInfo struct {
+	Text            string   `json:"text"`
+	EstimatedTokens int      `json:"estimated_tokens"`
+	Budget          int      `json:"budget"` // 0 for no limit
+	Sections        []string `json:"sections"`
+	DroppedSections []string `json:"dropped_sections,omitempty"`
+	// LogLines counts the condensed log lines sent; OmittedLogLines the
+	// older ones left out
+	LogLines        int `json:"log_lines"`
+	OmittedLogLines int `json:"omitted_log_lines"`
+}
+
+// Issue is a problem found by the diagnostics engine, in the shape the
+// frontend renders
+type Issue struct {
+	ID                string             `json:"id"`
+	Title             string             `json:"title"`
+	Severity          string             `json:"severity"`
+	Category          string             `json:"category"`
+	Description       string             `json:"description"`
+	AffectedResource  string             `json:"affectedResource"`
+	ResourceType      string             `json:"resourceType"`
+	ResourceName      string             `json:"resourceName"`
+	Resources         []ResourceRef      `json:"resources,omitempty"`
+	Evidence          []string           `json:"evidence,omitempty"`
+	VMName            string             `json:"vmName,omitempty"`
+	VMNamespace       string             `json:"vmNamespace,omitempty"`
+	NodeName          string             `json:"nodeName,omitempty"`
+	Namespace         string             `json:"namespace,omitempty"`
+	DiskPath          string             `json:"diskPath,omitempty"`
+	PDBIssueType      string             `json:"pdbIssueType,omitempty"`
+	DetectionTime     time.Time          `json:"detectionTime"`
+	VerificationSteps []IssueStep        `json:"verificationSteps"`
+	RemediationSteps  []IssueStep        `json:"remediationSteps"`
+	AttachmentDetails *AttachmentDetails `json:"attachmentDetails,omitempty"`
+	UpgradeDetails    *UpgradeDetails    `json:"upgradeDetails,omitempty"`
+	PDBDetails        *PDBIssueInfo      `json:"pdbDetails,omitempty"`
+	PodDetails        *PodError          `json:"podDetails,omitempty"`
+}
+
+// ResourceRef identifies a Kubernetes object affected by an issue
+type ResourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// IssueStep is a single verification or remediation step. Commands is used
+// instead of Command when the step runs once per affected node.
+type IssueStep struct {
+	ID             string   `json:"id"`
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	Command        string   `json:"command,omitempty"`
+	Commands       []string `json:"commands,omitempty"`
+	ExpectedOutput string   `json:"expectedOutput,omitempty"`
+	Warning        string   `json:"warning,omitempty"`
+}
+
+// AttachmentDetails is the evidence behind attachment ticket and orphaned
+// replica issues
+type AttachmentDetails struct {
+	TicketCount        int                               `json:"ticketCount,omitempty"`
+	TicketIDs          []string                          `json:"ticketIds,omitempty"`
+	TicketID           string                            `json:"ticketId,omitempty"`
+	UnsatisfiedTickets []string                          `json:"unsatisfiedTickets,omitempty"`
+	Condition          map[string]interface{}            `json:"condition,omitempty"`
+	AttachmentData     map[string]map[string]interface{} `json:"attachmentData,omitempty"`
+	VolumeName         string                            `json:"volumeName,omitempty"`
+	AffectedVMs        []string                          `json:"affectedVMs,omitempty"`
+	TicketAnalysis     *TicketAnalysis                   `json:"ticketAnalysis,omitempty"`
+	MigrationStory     *MigrationStory                   `json:"migrationStory,omitempty"`
+	Timeline           *MigrationTimeline                `json:"timeline,omitempty"`
+	OrphanedReplicas   []string                          `json:"orphanedReplicas,omitempty"`
+	MissingEngines     []string                          `json:"missingEngines,omitempty"`
+	ExistingEngines    []string                          `json:"existingEngines,omitempty"`
+}
+
+// TicketAnalysis classifies the attachment tickets of a volume
+type TicketAnalysis struct {
+	TicketsByType map[string]int `json:"ticketsByType"`
+	TotalTickets  int            `json:"totalTickets"`
+	Cause         string         `json:"cause"`
+	Severity      string         `json:"severity"`
+	Description   string         `json:"description"`
+	ResourceType  string         `json:"resourceType"`
+}
+
+// MigrationTimeline orders the attachment and migration events of a volume
+type MigrationTimeline struct {
+	StartTime     *time.Time         `json:"startTime,omitempty"`
+	Duration      *MigrationDuration `json:"duration,omitempty"`
+	Events        []TimelineEvent    `json:"events"`
+	CurrentState  string             `json:"currentState"`
+	RiskLevel     string             `json:"riskLevel"`
+	Nodes         []string           `json:"nodes"`
+	IsLongRunning bool               `json:"isLongRunning"`
+}
+
+// TimelineEvent is a single point on a migration timeline
+type TimelineEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"`
+	TicketID  string    `json:"ticketId,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	Type      string    `json:"type"`
+	Satisfied bool      `json:"satisfied,omitempty"`
+	Phase     string    `json:"phase,omitempty"`
+	Estimated bool      `json:"estimated,omitempty"`
+}
+
+// MigrationDuration is how long a migration has been in progress
+type MigrationDuration struct {
+	Days          int    `json:"days"`
+	Hours         int    `json:"hours"`
+	TotalHours    int    `json:"totalHours"`
+	HumanReadable string `json:"humanReadable"`
+}
+
+// MigrationStory is the human-readable summary of a stuck migration
+type MigrationStory struct {
+	Headline        string             `json:"headline"`
+	Summary         string             `json:"summary"`
+	RiskDescription string             `json:"riskDescription"`
+	Timeline        []StoryEvent       `json:"timeline"`
+	MigrationPath   string             `json:"migrationPath"`
+	CurrentStatus   string             `json:"currentStatus"`
+	Duration        *MigrationDuration `json:"duration,omitempty"`
+	Urgency         string             `json:"urgency"`
+	RiskFactors     []string           `json:"riskFactors"`
+	NextSteps       []string           `json:"nextSteps"`
+}
+
+// StoryEvent is a timeline event formatted for display
+type StoryEvent struct {
+	Date        string `json:"date"`
+	Time        string `json:"time"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Node        string `json:"node"`
+	Context     string `json:"context"`
+	Estimated   bool   `json:"estimated"`
+}
+
+// UpgradeDetails is the evidence behind an upgrade-blocked migration
+type UpgradeDetails struct {
+	Version           string   `json:"version"`
+	State             string   `json:"state"`
+	RequiredCPULabels []string `json:"requiredCPULabels"`
+}
+
+// PDBIssueInfo is the evidence behind a PDB issue
+type PDBIssueInfo struct {
+	ExpectedNode    string    `json:"expectedNode"`
+	ActualNode      string    `json:"actualNode"`
+	StaleEngines    []string  `json:"staleEngines"`
+	AffectedVolumes []string  `json:"affectedVolumes"`
+	Resolution      string    `json:"resolution"`
+	SafetyCheck     bool      `json:"safetyCheck"`
+	CanSafelyDelete bool      `json:"canSafelyDelete"`
+	LastChecked     time.Time `json:"lastChecked"`
+}
+
+// Changeset is the difference between two snapshots of the cluster, e.g.
+// before and after an upgrade
+type Changeset struct {
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	VMsAdded       []string        `json:"vmsAdded"`
+	VMsRemoved     []string        `json:"vmsRemoved"`
+	StatusChanges  []StatusChange  `json:"statusChanges"`
+	ReplicaChanges []ReplicaChange `json:"replicaChanges"`
+	EngineRestarts []EngineChange  `json:"engineRestarts"`
+	DiskChanges    []DiskChange    `json:"diskChanges"`
+	HealthChanges  []StatusChange  `json:"healthChanges"`
+}
+
+// StatusChange is a status field of a VM, volume or health check that
+// changed. From or To is empty when the resource only exists on one side.
+type StatusChange struct {
+	Kind  string `json:"kind"` // "vm", "volume" or "health"
+	Name  string `json:"name"`
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ReplicaChange is a replica that was added, removed, moved to another node
+// or changed its current state
+type ReplicaChange struct {
+	Volume    string `json:"volume"`
+	Name      string `json:"name"`
+	Change    string `json:"change"` // "added", "removed", "moved" or "state"
+	FromNode  string `json:"fromNode,omitempty"`
+	ToNode    string `json:"toNode,omitempty"`
+	FromState string `json:"fromState,omitempty"`
+	ToState   string `json:"toState,omitempty"`
+}
+
+// EngineChange is a volume engine that was replaced, moved or changed state
+// between the snapshots
+type EngineChange struct {
+	Volume    string `json:"volume"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"` // "replaced", "moved" or "state"
+	Previous  string `json:"previous,omitempty"`
+	FromNode  string `json:"fromNode,omitempty"`
+	ToNode    string `json:"toNode,omitempty"`
+	FromState string `json:"fromState,omitempty"`
+	ToState   string `json:"toState,omitempty"`
+}
+
+// DiskChange is a node disk whose schedulability flipped
+type DiskChange struct {
+	Node            string `json:"node"`
+	Disk            string `json:"disk"`
+	Schedulable     bool   `json:"schedulable"`
+	PressureReason  string `json:"pressureReason,omitempty"`
+	PressureMessage string `json:"pressureMessage,omitempty"`
 }
@@ -3,31 +3,21 @@ package batch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
-	"time"
 
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
+	"github.com/rk280392/harvesterNavigator/internal/services/source"
 	"k8s.io/client-go/kubernetes"
 )
 
-// BatchFetcher handles batched API requests with caching
+// BatchFetcher handles batched API requests, serving them from a resource
+// source (the shared cluster cache or a support bundle) whenever it can
 type BatchFetcher struct {
 	client *kubernetes.Clientset
-	cache  *APICache
-}
-
-// APICache stores frequently accessed API responses
-type APICache struct {
-	data  map[string]CacheEntry
-	mutex sync.RWMutex
-	ttl   time.Duration
-}
-
-// CacheEntry represents a cached API response
-type CacheEntry struct {
-	Data      interface{}
-	Timestamp time.Time
+	source source.Source
 }
 
 // BatchRequest represents a single API request
@@ -46,19 +36,12 @@ type BatchResponse struct {
 	Error error
 }
 
-// CreateBatchFetcher creates a batch fetcher with caching
-func CreateBatchFetcher(client *kubernetes.Clientset) *BatchFetcher {
+// CreateBatchFetcher creates a batch fetcher backed by the given source.
+// Requests the source cannot serve go to the API server when client is set.
+func CreateBatchFetcher(client *kubernetes.Clientset, src source.Source) *BatchFetcher {
 	return &BatchFetcher{
 		client: client,
-		cache:  CreateAPICache(5 * time.Minute), // 5 minute TTL
-	}
-}
-
-// CreateAPICache creates an API cache with specified TTL
-func CreateAPICache(ttl time.Duration) *APICache {
-	return &APICache{
-		data: make(map[string]CacheEntry),
-		ttl:  ttl,
+		source: src,
 	}
 }
 
@@ -84,11 +67,12 @@ func (bf *BatchFetcher) ExecuteBatch(requests []BatchRequest, maxConcurrency int
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			// Check cache first
-			if cachedData := bf.cache.Get(request.ID); cachedData != nil {
+			// Serve from the resource source first
+			if data, ok, err := bf.executeSourceRequest(request); ok {
 				responses[index] = BatchResponse{
-					ID:   request.ID,
-					Data: cachedData.(map[string]interface{}),
+					ID:    request.ID,
+					Data:  data,
+					Error: err,
 				}
 				return
 			}
@@ -100,11 +84,6 @@ func (bf *BatchFetcher) ExecuteBatch(requests []BatchRequest, maxConcurrency int
 				Data:  data,
 				Error: err,
 			}
-
-			// Cache successful responses
-			if err == nil && data != nil {
-				bf.cache.Set(request.ID, data)
-			}
 		}(i, req)
 	}
 
@@ -114,6 +93,10 @@ func (bf *BatchFetcher) ExecuteBatch(requests []BatchRequest, maxConcurrency int
 
 // executeRequest executes a single API request
 func (bf *BatchFetcher) executeRequest(req BatchRequest) (map[string]interface{}, error) {
+	if bf.client == nil {
+		return nil, fmt.Errorf("no API client available for %s", req.ID)
+	}
+
 	var restClient = bf.client.RESTClient().Get().AbsPath(req.AbsPath)
 
 	if req.Namespace != "" {
@@ -139,49 +122,34 @@ func (bf *BatchFetcher) executeRequest(req BatchRequest) (map[string]interface{}
 	return data, nil
 }
 
-// Get retrieves data from cache if not expired
-func (c *APICache) Get(key string) interface{} {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	entry, exists := c.data[key]
-	if !exists {
-		return nil
+// executeSourceRequest answers a request from the resource source. It returns
+// ok=false when the source cannot serve the resource, e.g. a cache resource
+// that is not watched or not yet synced.
+func (bf *BatchFetcher) executeSourceRequest(req BatchRequest) (map[string]interface{}, bool, error) {
+	gvr, ok := source.ResourceFor(req.AbsPath, req.Resource)
+	if !ok || bf.source == nil {
+		return nil, false, nil
 	}
 
-	// Check if entry has expired
-	if time.Since(entry.Timestamp) > c.ttl {
-		// Entry expired, remove it
-		delete(c.data, key)
-		return nil
+	if req.Name != "" {
+		data, err := bf.source.Get(gvr, req.Namespace, req.Name)
+		if errors.Is(err, cache.ErrNotSynced) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("source lookup failed for %s: %w", req.ID, err)
+		}
+		return data, true, nil
 	}
 
-	return entry.Data
-}
-
-// Set stores data in cache with current timestamp
-func (c *APICache) Set(key string, data interface{}) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.data[key] = CacheEntry{
-		Data:      data,
-		Timestamp: time.Now(),
+	objects, err := bf.source.List(gvr, req.Namespace)
+	if errors.Is(err, cache.ErrNotSynced) {
+		return nil, false, nil
 	}
-}
-
-// Clear removes all cached entries
-func (c *APICache) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-	c.data = make(map[string]CacheEntry)
-}
-
-// Size returns the number of cached entries
-func (c *APICache) Size() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-	return len(c.data)
+	if err != nil {
+		return nil, true, fmt.Errorf("source list failed for %s: %w", req.ID, err)
+	}
+	return map[string]interface{}{"items": source.ToItems(objects)}, true, nil
 }
 
 // BatchFetchLonghornResources fetches all Longhorn resources in batch
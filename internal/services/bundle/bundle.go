@@ -0,0 +1,277 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Bundle reads resources and pod logs from a Harvester support bundle, either
+// a .zip file or an extracted directory. Resources come from the bundle's
+// yamls/ tree and logs from its logs/<namespace>/<pod>/<container>.log files.
+type Bundle struct {
+	fsys    fs.FS
+	closer  io.Closer
+	objects map[schema.GroupVersionResource][]map[string]interface{}
+	mutex   sync.Mutex
+}
+
+// OpenBundle opens a support bundle zip file or extracted directory
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access support bundle: %w", err)
+	}
+
+	var fsys fs.FS
+	var closer io.Closer
+	if info.IsDir() {
+		fsys = os.DirFS(bundlePath)
+	} else {
+		zipReader, err := zip.OpenReader(bundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open support bundle zip: %w", err)
+		}
+		fsys = zipReader
+		closer = zipReader
+	}
+
+	root, err := findBundleRoot(fsys)
+	if err != nil {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+
+	if root != "." {
+		fsys, err = fs.Sub(fsys, root)
+		if err != nil {
+			if closer != nil {
+				_ = closer.Close()
+			}
+			return nil, fmt.Errorf("failed to open bundle directory %s: %w", root, err)
+		}
+	}
+
+	return &Bundle{
+		fsys:    fsys,
+		closer:  closer,
+		objects: make(map[schema.GroupVersionResource][]map[string]interface{}),
+	}, nil
+}
+
+// findBundleRoot locates the directory containing yamls/. Bundles are usually
+// zipped with a single supportbundle_<id>_<date>/ top-level directory.
+func findBundleRoot(fsys fs.FS) (string, error) {
+	if isDir(fsys, "yamls") {
+		return ".", nil
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", fmt.Errorf("failed to read support bundle: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && isDir(fsys, path.Join(entry.Name(), "yamls")) {
+			return entry.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("not a support bundle: no yamls/ directory found")
+}
+
+func isDir(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.IsDir()
+}
+
+// Close releases the underlying zip file, if any
+func (b *Bundle) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// List returns all bundled objects of a resource, sorted by namespace and
+// name. An empty namespace lists across all namespaces. A resource that is
+// not in the bundle yields an empty list.
+func (b *Bundle) List(gvr schema.GroupVersionResource, namespace string) ([]map[string]interface{}, error) {
+	objects, err := b.load(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		return objects, nil
+	}
+
+	var result []map[string]interface{}
+	for _, obj := range objects {
+		if objectNamespace(obj) == namespace {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// Get returns a single bundled object or a Kubernetes NotFound error
+func (b *Bundle) Get(gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error) {
+	objects, err := b.List(gvr, namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if objectName(obj) == name {
+			return obj, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+// load reads and caches every object of a resource from the yamls/ tree:
+// yamls/namespaced/<namespace>/<group>/<version>/<resource>.yaml and
+// yamls/cluster/<group>/<version>/<resource>.yaml. The core group has no
+// group directory.
+func (b *Bundle) load(gvr schema.GroupVersionResource) ([]map[string]interface{}, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if objects, ok := b.objects[gvr]; ok {
+		return objects, nil
+	}
+
+	groupVersionPath := gvr.Version
+	if gvr.Group != "" {
+		groupVersionPath = path.Join(gvr.Group, gvr.Version)
+	}
+	fileName := gvr.Resource + ".yaml"
+
+	namespaced, err := fs.Glob(b.fsys, path.Join("yamls", "namespaced", "*", groupVersionPath, fileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search bundle for %s: %w", gvr.Resource, err)
+	}
+	files := append(namespaced, path.Join("yamls", "cluster", groupVersionPath, fileName))
+
+	var objects []map[string]interface{}
+	for _, file := range files {
+		fileObjects, err := b.readObjects(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from bundle: %w", file, err)
+		}
+		objects = append(objects, fileObjects...)
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		if objectNamespace(objects[i]) != objectNamespace(objects[j]) {
+			return objectNamespace(objects[i]) < objectNamespace(objects[j])
+		}
+		return objectName(objects[i]) < objectName(objects[j])
+	})
+
+	b.objects[gvr] = objects
+	return objects, nil
+}
+
+// readObjects decodes a YAML file holding either a List or a stream of
+// individual objects. Decoding goes through JSON, so numbers come back as
+// float64 exactly like an API response.
+func (b *Bundle) readObjects(file string) ([]map[string]interface{}, error) {
+	f, err := b.fsys.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var objects []map[string]interface{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bufio.NewReader(f), 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+
+		if items, ok := doc["items"].([]interface{}); ok {
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, obj)
+				}
+			}
+			continue
+		}
+		if _, ok := doc["metadata"]; ok {
+			objects = append(objects, doc)
+		}
+	}
+	return objects, nil
+}
+
+// PodLog returns the bundled log of a container. With an empty container name
+// the pod's only (or first) container log is used.
+func (b *Bundle) PodLog(namespace, podName, containerName string) (string, error) {
+	return b.podLogFile(namespace, podName, containerName, ".log")
+}
+
+// PreviousPodLog returns the log of the previous instance of a restarted
+// container, which the bundle stores as <container>.log.1
+func (b *Bundle) PreviousPodLog(namespace, podName, containerName string) (string, error) {
+	return b.podLogFile(namespace, podName, containerName, ".log.1")
+}
+
+func (b *Bundle) podLogFile(namespace, podName, containerName, suffix string) (string, error) {
+	podDir := path.Join("logs", namespace, podName)
+
+	if containerName == "" {
+		entries, err := fs.ReadDir(b.fsys, podDir)
+		if err != nil {
+			return "", fmt.Errorf("no logs in bundle for pod %s/%s: %w", namespace, podName, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".log") {
+				containerName = strings.TrimSuffix(entry.Name(), ".log")
+				break
+			}
+		}
+		if containerName == "" {
+			return "", fmt.Errorf("no container logs in bundle for pod %s/%s", namespace, podName)
+		}
+	}
+
+	data, err := fs.ReadFile(b.fsys, path.Join(podDir, containerName+suffix))
+	if err != nil {
+		return "", fmt.Errorf("no logs in bundle for %s/%s container %s: %w", namespace, podName, containerName, err)
+	}
+	return string(data), nil
+}
+
+func objectNamespace(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	namespace, _ := metadata["namespace"].(string)
+	return namespace
+}
+
+func objectName(obj map[string]interface{}) string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return name
+}
@@ -0,0 +1,97 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	pods    = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	nodes   = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	volumes = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "volumes"}
+)
+
+// openTestBundle opens testdata, which holds the bundle one directory down,
+// as it is after extracting a support bundle zip
+func openTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b, err := OpenBundle("testdata")
+	if err != nil {
+		t.Fatalf("OpenBundle: %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+func objectNames(objects []map[string]interface{}) string {
+	var names []string
+	for _, obj := range objects {
+		names = append(names, objectNamespace(obj)+"/"+objectName(obj))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestBundle_List(t *testing.T) {
+	b := openTestBundle(t)
+
+	tests := []struct {
+		name      string
+		gvr       schema.GroupVersionResource
+		namespace string
+		want      string
+	}{
+		{"group path, List kind", volumes, "", "longhorn-system/pvc-a,longhorn-system/pvc-b"},
+		{"core path across namespaces, List and multi-document", pods, "", "default/virt-launcher-vm1-x,default/virt-launcher-vm2-x,longhorn-system/longhorn-manager-abc"},
+		{"namespace filter", pods, "default", "default/virt-launcher-vm1-x,default/virt-launcher-vm2-x"},
+		{"cluster scoped", nodes, "", "/node-a"},
+		{"not in bundle", schema.GroupVersionResource{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachines"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := b.List(tt.gvr, tt.namespace)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if got := objectNames(objects); got != tt.want {
+				t.Errorf("List = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// Numbers are decoded as JSON would decode them
+	volume, err := b.Get(volumes, "longhorn-system", "pvc-b")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if replicas := volume["spec"].(map[string]interface{})["numberOfReplicas"]; replicas != float64(3) {
+		t.Errorf("numberOfReplicas = %v (%T), want float64 3", replicas, replicas)
+	}
+	if _, err := b.Get(volumes, "longhorn-system", "pvc-missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Get of a missing volume = %v, want NotFound", err)
+	}
+}
+
+func TestBundle_PodLog(t *testing.T) {
+	b := openTestBundle(t)
+
+	current, err := b.PodLog("longhorn-system", "longhorn-manager-abc", "")
+	if err != nil || !strings.Contains(current, "current") {
+		t.Errorf("PodLog = %q, %v, want the only container's current log", current, err)
+	}
+	previous, err := b.PreviousPodLog("longhorn-system", "longhorn-manager-abc", "longhorn-manager")
+	if err != nil || !strings.Contains(previous, "before restart") {
+		t.Errorf("PreviousPodLog = %q, %v, want the .log.1 file", previous, err)
+	}
+	if _, err := b.PodLog("longhorn-system", "longhorn-manager-abc", "sidecar"); err == nil {
+		t.Error("PodLog of a missing container succeeded")
+	}
+}
+
+func TestOpenBundle_NotABundle(t *testing.T) {
+	if _, err := OpenBundle(t.TempDir()); err == nil || !strings.Contains(err.Error(), "not a support bundle") {
+		t.Errorf("OpenBundle of an empty directory = %v, want not a support bundle", err)
+	}
+}
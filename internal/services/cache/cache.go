@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// ErrNotSynced is returned when a resource is not watched or its informer has
+// not finished the initial list. Callers should fall back to the API.
+var ErrNotSynced = errors.New("resource not synced in cluster cache")
+
+// Watched resource types
+var (
+	VirtualMachines                  = schema.GroupVersionResource{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachines"}
+	VirtualMachineInstances          = schema.GroupVersionResource{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachineinstances"}
+	VirtualMachineInstanceMigrations = schema.GroupVersionResource{Group: "kubevirt.io", Version: "v1", Resource: "virtualmachineinstancemigrations"}
+	LonghornVolumes                  = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "volumes"}
+	LonghornReplicas                 = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "replicas"}
+	LonghornEngines                  = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "engines"}
+	LonghornNodes                    = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "nodes"}
+	LonghornVolumeAttachments        = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "volumeattachments"}
+	Pods                             = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	PersistentVolumeClaims           = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	PersistentVolumes                = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+	Nodes                            = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	CSIVolumeAttachments             = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "volumeattachments"}
+
+	// Upgrades are read on demand rather than watched
+	Upgrades = schema.GroupVersionResource{Group: "harvesterhci.io", Version: "v1beta1", Resource: "upgrades"}
+)
+
+// WatchedResources lists every resource kept in the shared cache
+var WatchedResources = []schema.GroupVersionResource{
+	VirtualMachines,
+	VirtualMachineInstances,
+	VirtualMachineInstanceMigrations,
+	LonghornVolumes,
+	LonghornReplicas,
+	LonghornEngines,
+	LonghornNodes,
+	LonghornVolumeAttachments,
+	Pods,
+	PersistentVolumeClaims,
+	PersistentVolumes,
+	Nodes,
+	CSIVolumeAttachments,
+}
+
+// ClusterCache keeps watched cluster resources in memory using shared
+// informers. Stores are updated by watch events, so reads never go stale
+// and never hit the API server.
+type ClusterCache struct {
+	discovery   discovery.DiscoveryInterface
+	factory     dynamicinformer.DynamicSharedInformerFactory
+	informers   map[schema.GroupVersionResource]informers.GenericInformer
+	subscribers map[chan struct{}]struct{}
+	mutex       sync.RWMutex
+}
+
+// CreateClusterCache creates a cluster cache. Call Start before reading from it.
+func CreateClusterCache(discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface) *ClusterCache {
+	return &ClusterCache{
+		discovery:   discoveryClient,
+		factory:     dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0),
+		informers:   make(map[schema.GroupVersionResource]informers.GenericInformer),
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// Start registers informers for every watched resource served by the cluster,
+// starts them and waits up to syncTimeout for the initial list. Resources that
+// have not synced by then keep syncing in the background.
+func (c *ClusterCache) Start(ctx context.Context, syncTimeout time.Duration) error {
+	c.mutex.Lock()
+	for _, gvr := range WatchedResources {
+		served, err := c.isServed(gvr)
+		if err != nil {
+			log.Printf("Warning: Could not discover %s: %v", gvr.String(), err)
+			continue
+		}
+		if !served {
+			log.Printf("Warning: %s is not served by the cluster, it will not be cached", gvr.String())
+			continue
+		}
+
+		informer := c.factory.ForResource(gvr)
+		if err := informer.Informer().SetTransform(pruneObject); err != nil {
+			c.mutex.Unlock()
+			return fmt.Errorf("failed to set transform for %s: %w", gvr.String(), err)
+		}
+		if _, err := informer.Informer().AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { c.notify() },
+			UpdateFunc: func(interface{}, interface{}) { c.notify() },
+			DeleteFunc: func(interface{}) { c.notify() },
+		}); err != nil {
+			c.mutex.Unlock()
+			return fmt.Errorf("failed to add event handler for %s: %w", gvr.String(), err)
+		}
+		c.informers[gvr] = informer
+	}
+	count := len(c.informers)
+	c.mutex.Unlock()
+
+	if count == 0 {
+		return fmt.Errorf("none of the watched resources are served by the cluster")
+	}
+
+	c.factory.Start(ctx.Done())
+
+	syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+	defer cancel()
+
+	synced := c.factory.WaitForCacheSync(syncCtx.Done())
+	for gvr, ok := range synced {
+		if !ok {
+			log.Printf("Warning: %s did not sync within %v, reading it from the API until it does", gvr.String(), syncTimeout)
+		}
+	}
+	log.Printf("Cluster cache started with %d watched resources", count)
+	return nil
+}
+
+// isServed checks whether the API server serves the given resource
+func (c *ClusterCache) isServed(gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := c.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Subscribe returns a channel that receives a signal whenever a watched
+// resource changes. Signals are coalesced, so a receiver that falls behind
+// sees one pending signal rather than one per event. Call the returned
+// function to unsubscribe.
+func (c *ClusterCache) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	if c == nil {
+		return ch, func() {}
+	}
+
+	c.mutex.Lock()
+	c.subscribers[ch] = struct{}{}
+	c.mutex.Unlock()
+
+	return ch, func() {
+		c.mutex.Lock()
+		delete(c.subscribers, ch)
+		c.mutex.Unlock()
+	}
+}
+
+// notify signals every subscriber without blocking the informer
+func (c *ClusterCache) notify() {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for ch := range c.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Synced reports whether the cache can serve the given resource
+func (c *ClusterCache) Synced(gvr schema.GroupVersionResource) bool {
+	_, err := c.lister(gvr)
+	return err == nil
+}
+
+func (c *ClusterCache) lister(gvr schema.GroupVersionResource) (toolscache.GenericLister, error) {
+	if c == nil {
+		return nil, ErrNotSynced
+	}
+
+	c.mutex.RLock()
+	informer, ok := c.informers[gvr]
+	c.mutex.RUnlock()
+
+	if !ok || !informer.Informer().HasSynced() {
+		return nil, fmt.Errorf("%s: %w", gvr.Resource, ErrNotSynced)
+	}
+	return informer.Lister(), nil
+}
+
+// List returns all cached objects of a resource, sorted by namespace and name.
+// An empty namespace lists across all namespaces. The returned maps are shared
+// with the cache and must not be modified.
+func (c *ClusterCache) List(gvr schema.GroupVersionResource, namespace string) ([]map[string]interface{}, error) {
+	lister, err := c.lister(gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []runtime.Object
+	if namespace == "" {
+		objects, err = lister.List(labels.Everything())
+	} else {
+		objects, err = lister.ByNamespace(namespace).List(labels.Everything())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached %s: %w", gvr.Resource, err)
+	}
+
+	items := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			items = append(items, u)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+
+	result := make([]map[string]interface{}, len(items))
+	for i, u := range items {
+		result[i] = u.Object
+	}
+	return result, nil
+}
+
+// Get returns a single cached object. Cluster-scoped resources use an empty
+// namespace. A missing object yields a Kubernetes NotFound error.
+func (c *ClusterCache) Get(gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error) {
+	lister, err := c.lister(gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj runtime.Object
+	if namespace == "" {
+		obj, err = lister.Get(name)
+	} else {
+		obj, err = lister.ByNamespace(namespace).Get(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T in cache", obj)
+	}
+	return u.Object, nil
+}
+
+// pruneObject drops managed fields and converts integers to float64 so cached
+// objects have the same shape as a JSON-decoded API response.
+func pruneObject(obj interface{}) (interface{}, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj, nil
+	}
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	normalizeNumbers(u.Object)
+	return u, nil
+}
+
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return v
+	}
+}
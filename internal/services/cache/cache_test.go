@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func pod(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"namespace": namespace, "name": name},
+		"spec":       map[string]interface{}{"priority": int64(10)},
+	}}
+}
+
+func startedCache(t *testing.T, objects ...runtime.Object) *ClusterCache {
+	t.Helper()
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Namespaced: true, Kind: "Pod"}},
+	}}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{Pods: "PodList"}, objects...)
+
+	c := CreateClusterCache(discovery, dynamicClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := c.Start(ctx, 10*time.Second); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return c
+}
+
+func names(objects []map[string]interface{}) []string {
+	var result []string
+	for _, obj := range objects {
+		u := unstructured.Unstructured{Object: obj}
+		result = append(result, u.GetNamespace()+"/"+u.GetName())
+	}
+	return result
+}
+
+func TestClusterCache_List(t *testing.T) {
+	c := startedCache(t, pod("b", "pod-1"), pod("a", "pod-2"), pod("a", "pod-1"))
+
+	all, err := c.List(Pods, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := names(all); len(got) != 3 || got[0] != "a/pod-1" || got[1] != "a/pod-2" || got[2] != "b/pod-1" {
+		t.Errorf("List = %v, want sorted by namespace and name", got)
+	}
+
+	inB, err := c.List(Pods, "b")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := names(inB); len(got) != 1 || got[0] != "b/pod-1" {
+		t.Errorf("List in b = %v", got)
+	}
+
+	// Informer objects are normalized to the shape of a JSON response
+	spec := all[0]["spec"].(map[string]interface{})
+	if _, ok := spec["priority"].(float64); !ok {
+		t.Errorf("priority is %T, want float64", spec["priority"])
+	}
+
+	// Resources the cluster does not serve are never synced
+	if _, err := c.List(LonghornVolumes, ""); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("List of an unserved resource = %v, want ErrNotSynced", err)
+	}
+}
+
+func TestClusterCache_NotSynced(t *testing.T) {
+	c := CreateClusterCache(&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}, nil)
+	if _, err := c.List(Pods, ""); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("List before Start = %v, want ErrNotSynced", err)
+	}
+	if _, err := c.Get(Pods, "a", "pod-1"); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("Get before Start = %v, want ErrNotSynced", err)
+	}
+
+	var nilCache *ClusterCache
+	if _, err := nilCache.List(Pods, ""); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("List on a nil cache = %v, want ErrNotSynced", err)
+	}
+}
+
+func TestClusterCache_SubscribeCoalesces(t *testing.T) {
+	c := CreateClusterCache(&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}, nil)
+	ch, unsubscribe := c.Subscribe()
+
+	for i := 0; i < 5; i++ {
+		c.notify()
+	}
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected a pending signal")
+	}
+	select {
+	case <-ch:
+		t.Error("expected the five events coalesced into one signal")
+	default:
+	}
+
+	unsubscribe()
+	c.notify()
+	select {
+	case <-ch:
+		t.Error("signal after unsubscribing")
+	default:
+	}
+}
+
+func TestPruneObject(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":          "vol",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec": map[string]interface{}{
+			"numberOfReplicas": int64(3),
+			"size":             int(1024),
+			"disks":            []interface{}{map[string]interface{}{"weight": int64(1)}},
+			"ratio":            0.5,
+		},
+	}}
+
+	pruned, err := pruneObject(obj)
+	if err != nil {
+		t.Fatalf("pruneObject: %v", err)
+	}
+	u := pruned.(*unstructured.Unstructured)
+	if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, "metadata", "managedFields"); found {
+		t.Error("managedFields not removed")
+	}
+	spec := u.Object["spec"].(map[string]interface{})
+	if spec["numberOfReplicas"] != float64(3) || spec["size"] != float64(1024) || spec["ratio"] != 0.5 {
+		t.Errorf("spec = %v, want integers as float64", spec)
+	}
+	if weight := spec["disks"].([]interface{})[0].(map[string]interface{})["weight"]; weight != float64(1) {
+		t.Errorf("nested weight = %v (%T), want float64", weight, weight)
+	}
+}
@@ -0,0 +1,511 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+const (
+	// staleConditionAge is how long an attachment condition must have been
+	// failing before it is reported, so transient attach/detach is ignored
+	staleConditionAge = 2 * time.Minute
+	// longRunningMigration is when a dual attachment becomes critical
+	longRunningMigration = 24 * time.Hour
+	// estimatedTicketAge is assumed for tickets that carry no timestamp
+	estimatedTicketAge = 12 * time.Hour
+)
+
+// attachmentTicketRule reports Longhorn volume attachment problems: more than
+// one attachment ticket, unsatisfied tickets and long-failing conditions
+type attachmentTicketRule struct {
+	now func() time.Time
+}
+
+func (*attachmentTicketRule) Name() string { return "attachment-tickets" }
+
+func (r *attachmentTicketRule) Check(data *models.FullClusterData) []models.Issue {
+	now := r.now()
+
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		for _, disk := range vm.DiskViews() {
+			tickets := MergeAttachmentTickets(disk.AttachmentTicketsStatusRaw, disk.AttachmentTicketsSpecRaw)
+			if len(tickets) == 0 {
+				continue
+			}
+			volumeID := disk.VolumeName
+			if volumeID == "" {
+				volumeID = vm.Name
+			}
+			issues = append(issues, ticketIssues(vm, volumeID, tickets, now)...)
+		}
+	}
+	return issues
+}
+
+// MergeAttachmentTickets combines a volume attachment's ticket statuses
+// (satisfied, conditions) with its ticket specs (type, nodeID) by ticket ID
+func MergeAttachmentTickets(statusRaw, specRaw any) map[string]map[string]interface{} {
+	merged := make(map[string]map[string]interface{})
+	for _, raw := range []any{statusRaw, specRaw} {
+		tickets, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for ticketID, ticketRaw := range tickets {
+			fields, ok := ticketRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if merged[ticketID] == nil {
+				merged[ticketID] = make(map[string]interface{})
+			}
+			for key, value := range fields {
+				merged[ticketID][key] = value
+			}
+		}
+	}
+	return merged
+}
+
+func ticketIssues(vm *models.VMInfo, volumeID string, tickets map[string]map[string]interface{}, now time.Time) []models.Issue {
+	ticketIDs := make([]string, 0, len(tickets))
+	for ticketID := range tickets {
+		ticketIDs = append(ticketIDs, ticketID)
+	}
+	sort.Strings(ticketIDs)
+
+	resources := []models.ResourceRef{vmRef(vm), volumeRef(volumeID)}
+	var issues []models.Issue
+
+	if len(ticketIDs) > 1 {
+		analysis, timeline, story := analyzeMultipleTickets(vm, tickets, ticketIDs, now)
+
+		title := fmt.Sprintf("Multiple Volume Attachment Tickets (%s)", analysis.Cause)
+		description := fmt.Sprintf("Volume %s has %d attachment tickets: %s. Affected VM: %s",
+			volumeID, len(ticketIDs), analysis.Description, vm.Name)
+		if story != nil {
+			title = story.Headline
+			description = story.Summary
+		}
+
+		var evidence []string
+		for _, ticketID := range ticketIDs {
+			evidence = append(evidence, fmt.Sprintf("ticket %s: type=%s node=%s satisfied=%t",
+				ticketID, ticketType(ticketID, tickets[ticketID]), stringField(tickets[ticketID], "nodeID"), boolField(tickets[ticketID], "satisfied")))
+		}
+
+		issues = append(issues, withDefaultSteps(models.Issue{
+			ID:               fmt.Sprintf("multiple-attachment-tickets-%s", volumeID),
+			Title:            title,
+			Severity:         analysis.Severity,
+			Category:         "Volume Attachment",
+			Description:      description,
+			AffectedResource: fmt.Sprintf("Volume: %s", volumeID),
+			ResourceType:     analysis.ResourceType,
+			ResourceName:     volumeID,
+			Resources:        resources,
+			Evidence:         evidence,
+			VMName:           vm.Name,
+			VMNamespace:      vm.Namespace,
+			AttachmentDetails: &models.AttachmentDetails{
+				TicketCount:    len(ticketIDs),
+				TicketIDs:      ticketIDs,
+				AttachmentData: tickets,
+				VolumeName:     volumeID,
+				AffectedVMs:    []string{vm.Name},
+				TicketAnalysis: analysis,
+				MigrationStory: story,
+				Timeline:       timeline,
+			},
+		}))
+	}
+
+	var unsatisfied []string
+	for _, ticketID := range ticketIDs {
+		if !boolField(tickets[ticketID], "satisfied") {
+			unsatisfied = append(unsatisfied, ticketID)
+		}
+	}
+	if len(unsatisfied) > 0 {
+		issues = append(issues, withDefaultSteps(models.Issue{
+			ID:               fmt.Sprintf("unsatisfied-attachment-tickets-%s-%s", vm.Name, volumeID),
+			Title:            "Volume Attachment Not Satisfied",
+			Severity:         SeverityCritical,
+			Category:         "Volume Attachment",
+			Description:      fmt.Sprintf("Volume %s has %d unsatisfied attachment tickets. Volume may not be accessible to the VM.", volumeID, len(unsatisfied)),
+			AffectedResource: fmt.Sprintf("Volume: %s", volumeID),
+			ResourceType:     "attachment-tickets-unsatisfied",
+			ResourceName:     volumeID,
+			Resources:        resources,
+			Evidence:         []string{"unsatisfied tickets: " + strings.Join(unsatisfied, ", ")},
+			VMName:           vm.Name,
+			VMNamespace:      vm.Namespace,
+			AttachmentDetails: &models.AttachmentDetails{
+				UnsatisfiedTickets: unsatisfied,
+				AttachmentData:     tickets,
+			},
+		}))
+	}
+
+	for _, ticketID := range ticketIDs {
+		conditions, _ := tickets[ticketID]["conditions"].([]interface{})
+		for _, conditionRaw := range conditions {
+			condition, ok := conditionRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			status := stringField(condition, "status")
+			if status == "True" {
+				continue
+			}
+			transition, err := time.Parse(time.RFC3339, stringField(condition, "lastTransitionTime"))
+			if err != nil || now.Sub(transition) <= staleConditionAge {
+				continue
+			}
+
+			conditionType := stringField(condition, "type")
+			issues = append(issues, withDefaultSteps(models.Issue{
+				ID:               fmt.Sprintf("attachment-condition-failed-%s-%s-%s", vm.Name, volumeID, conditionType),
+				Title:            fmt.Sprintf("Attachment Condition Failed: %s", conditionType),
+				Severity:         SeverityMedium,
+				Category:         "Volume Attachment",
+				Description:      fmt.Sprintf(`Volume attachment condition "%s" is failing for %s. Status: %s`, conditionType, volumeID, status),
+				AffectedResource: fmt.Sprintf("Volume: %s", volumeID),
+				ResourceType:     "attachment-condition-failed",
+				ResourceName:     volumeID,
+				Resources:        resources,
+				Evidence: []string{fmt.Sprintf("ticket %s: %s=%s since %s",
+					ticketID, conditionType, status, transition.UTC().Format(time.RFC3339))},
+				VMName:      vm.Name,
+				VMNamespace: vm.Namespace,
+				AttachmentDetails: &models.AttachmentDetails{
+					TicketID:       ticketID,
+					Condition:      condition,
+					AttachmentData: tickets,
+				},
+			}))
+		}
+	}
+
+	return issues
+}
+
+// analyzeMultipleTickets works out why a volume has more than one attachment
+// ticket. Several csi-attacher tickets mean a migration that never finished,
+// for which a timeline and story are built as well.
+func analyzeMultipleTickets(vm *models.VMInfo, tickets map[string]map[string]interface{}, ticketIDs []string, now time.Time) (*models.TicketAnalysis, *models.MigrationTimeline, *models.MigrationStory) {
+	analysis := &models.TicketAnalysis{
+		TicketsByType: make(map[string]int),
+		TotalTickets:  len(ticketIDs),
+	}
+
+	var typeNames []string
+	for _, ticketID := range ticketIDs {
+		t := ticketType(ticketID, tickets[ticketID])
+		if analysis.TicketsByType[t] == 0 {
+			typeNames = append(typeNames, t)
+		}
+		analysis.TicketsByType[t]++
+	}
+
+	var timeline *models.MigrationTimeline
+	var story *models.MigrationStory
+	if analysis.TicketsByType["csi-attacher"] > 1 {
+		timeline = extractMigrationTimeline(vm, tickets, ticketIDs, now)
+		story = buildMigrationStory(timeline)
+	}
+
+	typeList := func() string {
+		parts := make([]string, len(typeNames))
+		for i, t := range typeNames {
+			parts[i] = fmt.Sprintf("%d %s", analysis.TicketsByType[t], t)
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	switch {
+	case analysis.TicketsByType["csi-attacher"] > 1:
+		// Usually a stuck migration between nodes
+		analysis.Cause = "stuck-migration"
+		analysis.Severity = SeverityHigh
+		if timeline.IsLongRunning {
+			analysis.Severity = SeverityCritical
+		}
+		analysis.ResourceType = "attachment-tickets-stuck-migration"
+		humanReadable := "unknown"
+		if timeline.Duration != nil {
+			humanReadable = timeline.Duration.HumanReadable
+		}
+		analysis.Description = fmt.Sprintf("%s. Migration duration: %s", story.RiskDescription, humanReadable)
+	case analysis.TicketsByType["longhorn-api"] > 1:
+		// Stale manual attachments from the Longhorn UI
+		analysis.Cause = "stale-ui-tickets"
+		analysis.Severity = SeverityCritical
+		analysis.ResourceType = "attachment-tickets-stale-ui"
+		analysis.Description = fmt.Sprintf("%d Longhorn UI tickets indicate stale manual attachment operations", analysis.TicketsByType["longhorn-api"])
+	case len(typeNames) > 1:
+		// Often normal while a backup or snapshot runs
+		analysis.Cause = "mixed-operations"
+		analysis.Severity = SeverityMedium
+		analysis.ResourceType = "attachment-tickets-mixed-types"
+		analysis.Description = fmt.Sprintf("Mixed attachment types: %s. May be normal during operations like backup/snapshot", typeList())
+	case len(typeNames) == 1 && analysis.TicketsByType[typeNames[0]] > 1:
+		dominant := typeNames[0]
+		analysis.Cause = "multiple-" + dominant
+		analysis.Severity = SeverityHigh
+		if dominant == "backup-controller" {
+			analysis.Severity = SeverityMedium
+		}
+		analysis.ResourceType = "attachment-tickets-multiple-" + dominant
+		analysis.Description = fmt.Sprintf("%d %s tickets may indicate stuck %s operations",
+			analysis.TicketsByType[dominant], dominant, strings.TrimSuffix(dominant, "-controller"))
+	default:
+		analysis.Cause = "multiple-tickets"
+		analysis.Severity = SeverityMedium
+		analysis.ResourceType = "attachment-tickets-multiple"
+		analysis.Description = fmt.Sprintf("%d attachment tickets detected: %s. Manual investigation needed to determine root cause", analysis.TotalTickets, typeList())
+	}
+
+	return analysis, timeline, story
+}
+
+// ticketType returns the attacher type of a ticket, inferring it from the
+// ticket ID when the ticket does not say
+func ticketType(ticketID string, ticket map[string]interface{}) string {
+	for _, key := range []string{"type", "attacherType"} {
+		if t := stringField(ticket, key); t != "" {
+			return t
+		}
+	}
+	switch {
+	case strings.Contains(ticketID, "csi-"):
+		return "csi-attacher"
+	case strings.Contains(ticketID, "longhorn-api"):
+		return "longhorn-api"
+	case strings.Contains(ticketID, "backup"):
+		return "backup-controller"
+	case strings.Contains(ticketID, "snapshot"):
+		return "snapshot-controller"
+	}
+	return "unknown"
+}
+
+// extractMigrationTimeline orders the volume's attachment events. Tickets
+// without a timestamp are placed estimatedTicketAge in the past.
+func extractMigrationTimeline(vm *models.VMInfo, tickets map[string]map[string]interface{}, ticketIDs []string, now time.Time) *models.MigrationTimeline {
+	timeline := &models.MigrationTimeline{
+		Events:       []models.TimelineEvent{},
+		CurrentState: vm.PrintableStatus,
+		RiskLevel:    "HIGH",
+		Nodes:        []string{},
+	}
+	if timeline.CurrentState == "" {
+		timeline.CurrentState = "unknown"
+	}
+
+	seenNodes := make(map[string]bool)
+	for _, ticketID := range ticketIDs {
+		ticket := tickets[ticketID]
+		nodeID := stringField(ticket, "nodeID")
+		if nodeID == "" {
+			continue
+		}
+		if !seenNodes[nodeID] {
+			seenNodes[nodeID] = true
+			timeline.Nodes = append(timeline.Nodes, nodeID)
+		}
+
+		event := models.TimelineEvent{
+			Event:     fmt.Sprintf("Volume attachment to %s", nodeID),
+			TicketID:  truncateTicketID(ticketID),
+			Node:      nodeID,
+			Type:      "storage",
+			Satisfied: boolField(ticket, "satisfied"),
+		}
+		if timestamp, ok := ticketTimestamp(ticket); ok {
+			event.Timestamp = timestamp
+		} else {
+			event.Timestamp = now.Add(-estimatedTicketAge)
+			event.Event += " (estimated)"
+			event.Estimated = true
+		}
+		timeline.Events = append(timeline.Events, event)
+	}
+
+	// A VM reporting Migrating with recent attachments is treated as the
+	// migration that created them
+	if (vm.VMStatusReason == "Migrating" || vm.PrintableStatus == "Migrating") && len(timeline.Events) > 1 {
+		latest := timeline.Events[0].Timestamp
+		for _, event := range timeline.Events[1:] {
+			if event.Timestamp.After(latest) {
+				latest = event.Timestamp
+			}
+		}
+		if now.Sub(latest) < 7*24*time.Hour {
+			timeline.Events = append(timeline.Events, models.TimelineEvent{
+				Timestamp: latest,
+				Event:     "Migration inferred (VM status: Migrating)",
+				Type:      "migration",
+				Phase:     "Running",
+			})
+		}
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		return timeline.Events[i].Timestamp.Before(timeline.Events[j].Timestamp)
+	})
+
+	if len(timeline.Events) > 0 {
+		start := timeline.Events[0].Timestamp
+		elapsed := now.Sub(start)
+		days := int(elapsed / (24 * time.Hour))
+		hours := int((elapsed % (24 * time.Hour)) / time.Hour)
+
+		humanReadable := "<1h"
+		if days > 0 {
+			humanReadable = fmt.Sprintf("%dd %dh", days, hours)
+		} else if hours > 0 {
+			humanReadable = fmt.Sprintf("%dh", hours)
+		}
+
+		timeline.StartTime = &start
+		timeline.Duration = &models.MigrationDuration{
+			Days:          days,
+			Hours:         hours,
+			TotalHours:    int(elapsed / time.Hour),
+			HumanReadable: humanReadable,
+		}
+		timeline.IsLongRunning = elapsed > longRunningMigration
+		if timeline.IsLongRunning {
+			timeline.RiskLevel = "CRITICAL"
+		}
+	}
+
+	return timeline
+}
+
+// ticketTimestamp returns when a ticket was last satisfied or created
+func ticketTimestamp(ticket map[string]interface{}) (time.Time, bool) {
+	candidates := []string{stringField(ticket, "lastTransitionTime"), stringField(ticket, "creationTimestamp")}
+	if conditions, ok := ticket["conditions"].([]interface{}); ok {
+		for _, conditionRaw := range conditions {
+			if condition, ok := conditionRaw.(map[string]interface{}); ok && stringField(condition, "type") == "Satisfied" {
+				candidates = append(candidates, stringField(condition, "lastTransitionTime"))
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if timestamp, err := time.Parse(time.RFC3339, candidate); err == nil {
+			return timestamp, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// buildMigrationStory summarizes a migration timeline for display
+func buildMigrationStory(timeline *models.MigrationTimeline) *models.MigrationStory {
+	isStuck := timeline.CurrentState == "Migrating"
+
+	humanReadable := ""
+	if timeline.Duration != nil {
+		humanReadable = timeline.Duration.HumanReadable
+	}
+	orDefault := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+
+	migrationPath := orDefault(strings.Join(timeline.Nodes, " → "), "Multiple nodes")
+
+	story := &models.MigrationStory{
+		Headline:        "VM Migration Issues Detected - Split-Brain Risk",
+		Summary:         "Volume has conflicting attachment tickets during migration",
+		RiskDescription: "Multiple attachment tickets detected - migration coordination issue",
+		Timeline:        []models.StoryEvent{},
+		MigrationPath:   migrationPath,
+		CurrentStatus:   timeline.CurrentState,
+		Duration:        timeline.Duration,
+		Urgency:         "INVESTIGATE",
+		NextSteps: []string{
+			"Check current migration status",
+			"Verify if migration is actively progressing",
+			"Consider canceling and restarting migration if stuck",
+			"Monitor for completion",
+		},
+	}
+
+	if timeline.IsLongRunning {
+		story.Headline = fmt.Sprintf("Long-Running Volume Attachments (%s) - Split-Brain Risk", orDefault(humanReadable, "Extended Period"))
+		if isStuck {
+			story.Headline = fmt.Sprintf("VM Migration Stuck for %s - Split-Brain", orDefault(humanReadable, "Extended Period"))
+		}
+		story.Summary = fmt.Sprintf("Volume has been attached to multiple nodes for %s - indicating stuck migration", orDefault(humanReadable, "extended period"))
+		story.RiskDescription = "Volume attached to BOTH nodes simultaneously for extended period - HIGH data corruption risk"
+		story.Urgency = "URGENT"
+		story.NextSteps = []string{
+			"URGENT: Consider shutting down VM to prevent data corruption",
+			"Investigate why migration has been stuck for so long",
+			"Check for stuck migration processes",
+			"Verify volume integrity before restart",
+		}
+	}
+
+	for _, event := range timeline.Events {
+		node := orDefault(event.Node, "unknown")
+		context := orDefault(event.Phase, "storage")
+		story.Timeline = append(story.Timeline, models.StoryEvent{
+			Date:        event.Timestamp.Format("1/2/2006"),
+			Time:        event.Timestamp.Format("3:04:05 PM"),
+			Description: event.Event,
+			Type:        event.Type,
+			Node:        node,
+			Context:     context,
+			Estimated:   event.Estimated,
+		})
+	}
+
+	lastFactor := "Multiple attachment tickets"
+	if len(timeline.Nodes) > 1 {
+		lastFactor = "Split-brain scenario detected"
+	}
+	durationFactor := "Recent migration conflict"
+	if timeline.IsLongRunning {
+		durationFactor = "CRITICAL: Long-running dual attachment"
+	}
+	story.RiskFactors = []string{
+		"Duration: " + orDefault(humanReadable, "Unknown"),
+		"Nodes: " + migrationPath,
+		durationFactor,
+		lastFactor,
+	}
+
+	return story
+}
+
+func truncateTicketID(ticketID string) string {
+	if len(ticketID) <= 12 {
+		return ticketID
+	}
+	return ticketID[:12] + "..."
+}
+
+func stringField(fields map[string]interface{}, key string) string {
+	value, _ := fields[key].(string)
+	return value
+}
+
+func boolField(fields map[string]interface{}, key string) bool {
+	value, _ := fields[key].(bool)
+	return value
+}
@@ -0,0 +1,116 @@
+package diagnostics
+
+import (
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// Issue severities, from most to least urgent
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityWarning  = "warning"
+	SeverityLow      = "low"
+)
+
+// Rule inspects cluster data and reports the issues it finds
+type Rule interface {
+	// Name identifies the rule in logs and configuration
+	Name() string
+	// Check returns the issues found in data. It must not modify data.
+	Check(data *models.FullClusterData) []models.Issue
+}
+
+// Engine runs a set of rules over cluster data
+type Engine struct {
+	rules []Rule
+	now   func() time.Time
+}
+
+// CreateEngine creates an engine running the given rules in order
+func CreateEngine(rules ...Rule) *Engine {
+	return &Engine{
+		rules: rules,
+		now:   time.Now,
+	}
+}
+
+// DefaultRules returns every built-in rule
+func DefaultRules() []Rule {
+	return []Rule{
+		vmErrorRule{},
+		vmPendingRule{},
+		replicaRule{},
+		vmStuckTerminatingRule{},
+		&attachmentTicketRule{now: time.Now},
+		upgradeBlockedMigrationRule{},
+		nodeRule{},
+		nodeDiskRule{},
+		healthCheckRule{},
+	}
+}
+
+// Rules returns the rules the engine runs
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Run applies every rule to data. Issues are returned in rule order; an issue
+// whose ID was already reported by an earlier rule is dropped.
+func (e *Engine) Run(data *models.FullClusterData) []models.Issue {
+	detectionTime := e.now().UTC()
+	seen := make(map[string]bool)
+	issues := []models.Issue{}
+
+	for _, rule := range e.rules {
+		for _, issue := range rule.Check(data) {
+			if seen[issue.ID] {
+				continue
+			}
+			seen[issue.ID] = true
+
+			if issue.DetectionTime.IsZero() {
+				issue.DetectionTime = detectionTime
+			}
+			if issue.VerificationSteps == nil {
+				issue.VerificationSteps = []models.IssueStep{}
+			}
+			if issue.RemediationSteps == nil {
+				issue.RemediationSteps = []models.IssueStep{}
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// withDefaultSteps fills in the standard steps for the issue's resource type
+// when the rule did not provide its own
+func withDefaultSteps(issue models.Issue) models.Issue {
+	target := stepTarget{
+		Name:        issue.ResourceName,
+		VMName:      issue.VMName,
+		VMNamespace: issue.VMNamespace,
+	}
+	if issue.VerificationSteps == nil {
+		issue.VerificationSteps = verificationSteps(issue.ResourceType, target)
+	}
+	if issue.RemediationSteps == nil {
+		issue.RemediationSteps = remediationSteps(issue.ResourceType, target)
+	}
+	return issue
+}
+
+func vmRef(vm *models.VMInfo) models.ResourceRef {
+	return models.ResourceRef{Kind: "VirtualMachine", Namespace: vm.Namespace, Name: vm.Name}
+}
+
+func volumeRef(volumeName string) models.ResourceRef {
+	return models.ResourceRef{Kind: "Volume", Namespace: "longhorn-system", Name: volumeName}
+}
+
+func nodeRef(nodeName string) models.ResourceRef {
+	return models.ResourceRef{Kind: "Node", Name: nodeName}
+}
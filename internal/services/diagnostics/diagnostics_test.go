@@ -0,0 +1,150 @@
+package diagnostics
+
+import (
+	"testing"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func findIssue(issues []models.Issue, id string) *models.Issue {
+	for i := range issues {
+		if issues[i].ID == id {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestReplicaRule_ReportsEachDisk(t *testing.T) {
+	vm := models.VMInfo{
+		Name:      "web",
+		Namespace: "default",
+		Disks: []models.VMDisk{
+			{
+				Name:       "rootdisk",
+				VolumeName: "pvc-root",
+				ReplicaInfo: []models.ReplicaInfo{
+					{Name: "pvc-root-r-1", NodeID: "node-1", CurrentState: "running", Started: true},
+				},
+			},
+			{
+				Name:       "datadisk",
+				VolumeName: "pvc-data",
+				ReplicaInfo: []models.ReplicaInfo{
+					{Name: "pvc-data-r-1", NodeID: "node-1", CurrentState: "error"},
+					{Name: "pvc-data-r-2", NodeID: "node-2", CurrentState: "error"},
+				},
+			},
+		},
+	}
+
+	issues := replicaRule{}.Check(&models.FullClusterData{VMs: []models.VMInfo{vm}})
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(issues))
+	}
+	issue := issues[0]
+	if issue.ID != "replica-issues-default-web-pvc-data" {
+		t.Errorf("unexpected issue ID %s", issue.ID)
+	}
+	if issue.Severity != SeverityCritical {
+		t.Errorf("expected critical severity when all replicas are faulted, got %s", issue.Severity)
+	}
+	if issue.ResourceName != "pvc-data" {
+		t.Errorf("expected resource pvc-data, got %s", issue.ResourceName)
+	}
+}
+
+func TestReplicaRule_OrphanedReplicas(t *testing.T) {
+	vm := models.VMInfo{
+		Name:       "db",
+		Namespace:  "prod",
+		VolumeName: "pvc-db",
+		ReplicaInfo: []models.ReplicaInfo{
+			{Name: "pvc-db-r-1", EngineName: "pvc-db-e-old", CurrentState: "running", Started: true},
+			{Name: "pvc-db-r-2", EngineName: "pvc-db-e-old", CurrentState: "running", Started: true},
+		},
+		EngineInfo: []models.EngineInfo{{Name: "pvc-db-e-0"}},
+	}
+
+	issues := replicaRule{}.Check(&models.FullClusterData{VMs: []models.VMInfo{vm}})
+	issue := findIssue(issues, "orphaned-replicas-prod-db-pvc-db")
+	if issue == nil {
+		t.Fatalf("expected orphaned replica issue, got %+v", issues)
+	}
+	details := issue.AttachmentDetails
+	if details == nil || len(details.OrphanedReplicas) != 2 {
+		t.Fatalf("expected 2 orphaned replicas, got %+v", details)
+	}
+	if len(details.MissingEngines) != 1 || details.MissingEngines[0] != "pvc-db-e-old" {
+		t.Errorf("expected missing engine pvc-db-e-old, got %v", details.MissingEngines)
+	}
+}
+
+func TestAttachmentTicketRule_StuckMigration(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	vm := models.VMInfo{
+		Name:       "web",
+		Namespace:  "default",
+		VolumeName: "pvc-web",
+		AttachmentTicketsStatusRaw: map[string]interface{}{
+			"csi-aaa": map[string]interface{}{"satisfied": true},
+			"csi-bbb": map[string]interface{}{"satisfied": true},
+		},
+		AttachmentTicketsSpecRaw: map[string]interface{}{
+			"csi-aaa": map[string]interface{}{"type": "csi-attacher", "nodeID": "node-1"},
+			"csi-bbb": map[string]interface{}{"type": "csi-attacher", "nodeID": "node-2"},
+		},
+	}
+
+	rule := &attachmentTicketRule{now: func() time.Time { return now }}
+	issues := rule.Check(&models.FullClusterData{VMs: []models.VMInfo{vm}})
+	issue := findIssue(issues, "multiple-attachment-tickets-pvc-web")
+	if issue == nil {
+		t.Fatalf("expected multiple ticket issue, got %+v", issues)
+	}
+	if issue.ResourceType != "attachment-tickets-stuck-migration" {
+		t.Errorf("expected stuck migration, got %s", issue.ResourceType)
+	}
+	if issue.AttachmentDetails.TicketCount != 2 {
+		t.Errorf("expected 2 tickets, got %d", issue.AttachmentDetails.TicketCount)
+	}
+	if len(issue.RemediationSteps) == 0 {
+		t.Error("expected default remediation steps")
+	}
+}
+
+type staticRule struct {
+	issues []models.Issue
+}
+
+func (staticRule) Name() string { return "static" }
+
+func (r staticRule) Check(*models.FullClusterData) []models.Issue { return r.issues }
+
+func TestEngine_DeduplicatesAndStamps(t *testing.T) {
+	e := CreateEngine(
+		staticRule{issues: []models.Issue{{ID: "a", Title: "first"}}},
+		staticRule{issues: []models.Issue{{ID: "a", Title: "second"}, {ID: "b"}}},
+	)
+
+	issues := e.Run(&models.FullClusterData{})
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].Title != "first" {
+		t.Errorf("expected first rule to win, got %s", issues[0].Title)
+	}
+	for _, issue := range issues {
+		if issue.DetectionTime.IsZero() {
+			t.Errorf("issue %s has no detection time", issue.ID)
+		}
+		if issue.VerificationSteps == nil || issue.RemediationSteps == nil {
+			t.Errorf("issue %s has nil steps", issue.ID)
+		}
+	}
+
+	if issues := CreateEngine().Run(&models.FullClusterData{}); issues == nil {
+		t.Error("expected empty, non-nil issues")
+	}
+}
@@ -0,0 +1,245 @@
+package diagnostics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+const (
+	// seriousRestartCount is the restart count at which a running pod is
+	// reported on its own instead of in the restart summary
+	seriousRestartCount = 50
+	// restartSummaryThreshold is how many restarting pods trigger a summary
+	restartSummaryThreshold = 5
+)
+
+var highRestartsPattern = regexp.MustCompile(`HighRestarts\((\d+)\)`)
+
+// checkSeverity is the severity of each failed health check
+var checkSeverity = map[string]string{
+	"nodes":            SeverityCritical,
+	"error_pods":       SeverityHigh,
+	"volumes":          SeverityHigh,
+	"attached_volumes": SeverityMedium,
+	"bundles":          SeverityMedium,
+	"harvester_bundle": SeverityHigh,
+	"cluster":          SeverityCritical,
+	"machines":         SeverityMedium,
+	"free_space":       SeverityMedium,
+}
+
+// healthCheckRule turns failed and warning health checks into issues. Pod
+// errors are reported per pod when serious and summarized otherwise.
+type healthCheckRule struct{}
+
+func (healthCheckRule) Name() string { return "health-checks" }
+
+func (healthCheckRule) Check(data *models.FullClusterData) []models.Issue {
+	if data.HealthChecks == nil {
+		return nil
+	}
+
+	var issues []models.Issue
+	for _, check := range data.HealthChecks.Results {
+		if check.Status != "failed" && check.Status != "warning" && check.Status != "timeout" {
+			continue
+		}
+
+		if check.CheckName == "error_pods" && len(check.PodErrors) > 0 {
+			issues = append(issues, podErrorIssues(check)...)
+			continue
+		}
+
+		title := fmt.Sprintf("Health Check Failed: %s", formatCheckName(check.CheckName))
+		severity := SeverityMedium
+		switch {
+		case check.Status == "timeout":
+			// The check's outcome is unknown, not bad
+			title = fmt.Sprintf("Health Check Timed Out: %s", formatCheckName(check.CheckName))
+			severity = SeverityWarning
+		case check.Status == "warning":
+			if check.CheckName == "nodes" {
+				title = "Nodes Under Maintenance"
+			}
+		default:
+			if s, ok := checkSeverity[check.CheckName]; ok {
+				severity = s
+			}
+		}
+		description := check.Error
+		if description == "" {
+			description = check.Message
+		}
+
+		issues = append(issues, models.Issue{
+			ID:                fmt.Sprintf("health-%s", check.CheckName),
+			Title:             title,
+			Severity:          severity,
+			Category:          "Cluster Health",
+			Description:       description,
+			AffectedResource:  fmt.Sprintf("Health Check: %s", check.CheckName),
+			ResourceType:      "health-check",
+			ResourceName:      check.CheckName,
+			Evidence:          check.Details,
+			DetectionTime:     check.Timestamp,
+			VerificationSteps: healthCheckVerificationSteps(check.CheckName),
+			RemediationSteps:  healthCheckRemediationSteps(check.CheckName),
+		})
+	}
+	return issues
+}
+
+func podErrorIssues(check models.HealthCheckResult) []models.Issue {
+	var issues []models.Issue
+	restartCount := 0
+
+	for i := range check.PodErrors {
+		pod := check.PodErrors[i]
+		if !isSeriousPodError(pod) {
+			if strings.HasPrefix(pod.ErrorState, "HighRestarts") {
+				restartCount++
+			}
+			continue
+		}
+
+		var evidence []string
+		for _, c := range pod.ContainerErrors {
+			if c.Reason != "" {
+				evidence = append(evidence, fmt.Sprintf("container %s: %s %s", c.Name, c.Reason, c.Message))
+			}
+		}
+
+		issues = append(issues, models.Issue{
+			ID:                fmt.Sprintf("pod-error-%s-%s", pod.Namespace, pod.Name),
+			Title:             fmt.Sprintf("Pod Issue: %s", pod.Name),
+			Severity:          podSeverity(pod),
+			Category:          "Pod Health",
+			Description:       podDescription(pod),
+			AffectedResource:  fmt.Sprintf("Pod: %s/%s", pod.Namespace, pod.Name),
+			ResourceType:      "pod-error",
+			ResourceName:      pod.Name,
+			Resources:         []models.ResourceRef{{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}},
+			Evidence:          evidence,
+			Namespace:         pod.Namespace,
+			DetectionTime:     check.Timestamp,
+			PodDetails:        &pod,
+			VerificationSteps: podVerificationSteps(pod),
+			RemediationSteps:  podRemediationSteps(pod),
+		})
+	}
+
+	// Many moderately restarting pods are noise individually
+	if restartCount > restartSummaryThreshold {
+		issues = append(issues, models.Issue{
+			ID:               "pod-restarts-summary",
+			Title:            "Multiple Pods with High Restart Counts",
+			Severity:         SeverityLow,
+			Category:         "Pod Health",
+			Description:      fmt.Sprintf("%d pods have elevated restart counts. This may indicate temporary instability but pods are currently running.", restartCount),
+			AffectedResource: fmt.Sprintf("%d pods across multiple namespaces", restartCount),
+			ResourceType:     "pod-restart-summary",
+			ResourceName:     "multiple",
+			DetectionTime:    check.Timestamp,
+			VerificationSteps: []models.IssueStep{{
+				ID:             "check-restart-pods",
+				Title:          "Check Pods with High Restarts",
+				Command:        "kubectl get pods --all-namespaces --field-selector=status.phase=Running",
+				ExpectedOutput: "List of running pods",
+				Description:    "Review pods that have restarted frequently",
+			}},
+			RemediationSteps: []models.IssueStep{{
+				ID:          "monitor-restarts",
+				Title:       "Monitor for Patterns",
+				Command:     "kubectl get events --all-namespaces --sort-by=.lastTimestamp",
+				Description: "Check recent events to understand restart patterns",
+			}},
+		})
+	}
+
+	return issues
+}
+
+// isSeriousPodError keeps pods that are not running, are crashing or cannot
+// start, and running pods only once they have restarted very many times
+func isSeriousPodError(pod models.PodError) bool {
+	if pod.Phase != "Running" {
+		return true
+	}
+	state := pod.ErrorState
+	if strings.Contains(state, "CrashLoopBackOff") || strings.Contains(state, "ImagePull") || strings.Contains(state, "CreateContainer") {
+		return true
+	}
+	if match := highRestartsPattern.FindStringSubmatch(state); match != nil {
+		count, err := strconv.Atoi(match[1])
+		return err == nil && count >= seriousRestartCount
+	}
+	return false
+}
+
+func podSeverity(pod models.PodError) string {
+	if pod.Phase == "Failed" {
+		return SeverityCritical
+	}
+
+	switch pod.ErrorState {
+	case "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerError",
+		"CreateContainerConfigError", "InvalidImageName":
+		return SeverityHigh
+	}
+	if strings.HasPrefix(pod.ErrorState, "HighRestarts") {
+		return SeverityHigh
+	}
+	if strings.HasPrefix(pod.ErrorState, "Init:") {
+		return SeverityMedium
+	}
+
+	if pod.Phase == "Unknown" || pod.Phase == "Pending" {
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+func podDescription(pod models.PodError) string {
+	description := fmt.Sprintf("Pod %s in namespace %s", pod.Name, pod.Namespace)
+
+	if pod.ErrorState != "" && pod.ErrorState != pod.Phase {
+		description += fmt.Sprintf(" is experiencing %s", pod.ErrorState)
+	} else {
+		description += fmt.Sprintf(" is in %s state", pod.Phase)
+	}
+	if pod.NodeName != "" {
+		description += fmt.Sprintf(" on node %s", pod.NodeName)
+	}
+	if pod.Reason != "" && pod.Reason != pod.ErrorState {
+		description += fmt.Sprintf(". Reason: %s", pod.Reason)
+	}
+
+	switch {
+	case pod.ErrorState == "CrashLoopBackOff":
+		description += ". The container is repeatedly crashing and restarting."
+	case pod.ErrorState == "ImagePullBackOff" || pod.ErrorState == "ErrImagePull":
+		description += ". Cannot pull the container image from registry."
+	case pod.ErrorState == "CreateContainerConfigError":
+		description += ". Container configuration is invalid."
+	case strings.HasPrefix(pod.ErrorState, "HighRestarts"):
+		description += ". Container has restarted many times."
+	case strings.HasPrefix(pod.ErrorState, "Init:"):
+		description += ". Init container is failing to complete."
+	}
+	return description
+}
+
+// formatCheckName turns a check name like free_space into "Free Space"
+func formatCheckName(name string) string {
+	words := strings.Split(name, "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,219 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// ipredCtrlLabel is the CPU feature label KubeVirt upgrades are known to set
+// on only some nodes, leaving migrations unschedulable
+const ipredCtrlLabel = "cpu-feature.node.kubevirt.io/ipred-ctrl"
+
+// upgradeBlockedMigrationRule reports the latest stuck migration of each VM
+// while an upgrade is in progress, and whether a CPU label mismatch between
+// nodes explains it
+type upgradeBlockedMigrationRule struct{}
+
+func (upgradeBlockedMigrationRule) Name() string { return "upgrade-blocked-migration" }
+
+func (upgradeBlockedMigrationRule) Check(data *models.FullClusterData) []models.Issue {
+	upgradeInfo := data.UpgradeInfo
+	if upgradeInfo == nil || upgradeInfo.State == "Succeeded" {
+		return nil
+	}
+
+	// Split-brain labels: some, but not all, nodes carry the CPU feature
+	allNodes := make([]string, 0, len(data.NodeCPULabels))
+	for nodeName := range data.NodeCPULabels {
+		allNodes = append(allNodes, nodeName)
+	}
+	sort.Strings(allNodes)
+
+	var missingNodes []string
+	for _, nodeName := range allNodes {
+		if data.NodeCPULabels[nodeName][ipredCtrlLabel] != "true" {
+			missingNodes = append(missingNodes, nodeName)
+		}
+	}
+	labelledCount := len(allNodes) - len(missingNodes)
+	hasSplitBrainLabels := labelledCount > 0 && labelledCount < len(allNodes)
+
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		m, ok := latestStuckMigration(vm.VMIMInfo)
+		if !ok {
+			continue
+		}
+
+		resources := []models.ResourceRef{
+			vmRef(vm),
+			{Kind: "VirtualMachineInstanceMigration", Namespace: vm.Namespace, Name: m.Name},
+		}
+
+		if len(m.SchedulingEvents) == 0 {
+			issues = append(issues, models.Issue{
+				ID:               fmt.Sprintf("migration-stuck-no-events-%s", m.Name),
+				Title:            "VM Migration Stuck - No Events",
+				Severity:         SeverityWarning,
+				Category:         "Migration",
+				Description:      fmt.Sprintf("Migration %s is in %s phase but no scheduling events were found. This requires manual investigation.", m.Name, m.Phase),
+				AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+				ResourceType:     "migration-stuck",
+				ResourceName:     vm.Name,
+				Resources:        resources,
+				Evidence:         []string{fmt.Sprintf("migration %s phase: %s", m.Name, m.Phase)},
+				VMName:           vm.Name,
+				VMNamespace:      vm.Namespace,
+				VerificationSteps: []models.IssueStep{{
+					ID:             "manual-check",
+					Title:          "Manual Verification",
+					Description:    "Check the migration object manually",
+					Command:        fmt.Sprintf("kubectl describe vmim -n %s %s", vm.Namespace, m.Name),
+					ExpectedOutput: "Look for status conditions or recent events",
+				}},
+			})
+			continue
+		}
+
+		eventMessage := m.SchedulingEvents[0].Message
+		evidence := []string{fmt.Sprintf("%s: %s", m.SchedulingEvents[0].Reason, eventMessage)}
+
+		if !m.HasSchedulingError || m.SchedulingErrorReason != "NodeAffinityError" {
+			// Scheduling failed for another reason, e.g. insufficient CPU or memory
+			if eventMessage == "" {
+				eventMessage = "Unknown scheduling error"
+			}
+			issues = append(issues, models.Issue{
+				ID:               fmt.Sprintf("migration-scheduling-failed-%s", m.Name),
+				Title:            "VM Migration Scheduling Failed",
+				Severity:         SeverityWarning,
+				Category:         "Migration",
+				Description:      fmt.Sprintf("Migration %s failed to schedule. Reason: %s", m.Name, eventMessage),
+				AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+				ResourceType:     "migration-scheduling-failed",
+				ResourceName:     vm.Name,
+				Resources:        resources,
+				Evidence:         evidence,
+				VMName:           vm.Name,
+				VMNamespace:      vm.Namespace,
+				VerificationSteps: []models.IssueStep{{
+					ID:             "check-events",
+					Title:          "Check Scheduling Events",
+					Description:    "Verify the scheduling error",
+					Command:        fmt.Sprintf("kubectl get events -n %s --field-selector involvedObject.name=%s", vm.Namespace, m.VMIName),
+					ExpectedOutput: eventMessage,
+				}},
+			})
+			continue
+		}
+
+		// The label is confirmed either on the target pod spec or by nodes
+		// disagreeing about it
+		if len(m.RequiredNodeLabels) == 0 && !hasSplitBrainLabels {
+			issues = append(issues, models.Issue{
+				ID:       fmt.Sprintf("migration-affinity-error-%s", m.Name),
+				Title:    "VM Migration Blocked - Node Affinity",
+				Severity: SeverityHigh,
+				Category: "Migration",
+				Description: fmt.Sprintf(`Migration %s is failing due to node affinity/selector mismatch. Actual error: "%s". `+
+					"Could not verify specific CPU labels, but this is likely an upgrade constraint.", m.Name, eventMessage),
+				AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+				ResourceType:     "migration-affinity-mismatch",
+				ResourceName:     vm.Name,
+				Resources:        resources,
+				Evidence:         evidence,
+				VMName:           vm.Name,
+				VMNamespace:      vm.Namespace,
+				VerificationSteps: []models.IssueStep{{
+					ID:             "check-pod-spec",
+					Title:          "Check Pod Node Selector",
+					Description:    "Inspect the VMI or Pod for node selector requirements",
+					Command:        fmt.Sprintf("kubectl get vmi %s -n %s -o yaml | grep nodeSelector -A 5", m.VMIName, vm.Namespace),
+					ExpectedOutput: "List of required labels",
+				}},
+			})
+			continue
+		}
+
+		expectedOutput := "Inconsistent values (some true, some empty)"
+		annotateCommands := []string{`kubectl annotate node <node-name> node-labeller.kubevirt.io/skip-node="true" --overwrite`}
+		labelCommands := []string{"kubectl label node <node-name> cpu-feature.node.kubevirt.io/ipred-ctrl=true --overwrite"}
+		if len(missingNodes) > 0 {
+			expectedOutput = fmt.Sprintf("Nodes without label: %s", strings.Join(missingNodes, ", "))
+			annotateCommands = nil
+			labelCommands = nil
+			for _, nodeName := range missingNodes {
+				annotateCommands = append(annotateCommands, fmt.Sprintf(`kubectl annotate node %s node-labeller.kubevirt.io/skip-node="true" --overwrite`, nodeName))
+				labelCommands = append(labelCommands, fmt.Sprintf("kubectl label node %s cpu-feature.node.kubevirt.io/ipred-ctrl=true --overwrite", nodeName))
+			}
+			evidence = append(evidence, "nodes without "+ipredCtrlLabel+": "+strings.Join(missingNodes, ", "))
+		}
+
+		requiredLabels := append([]string{}, m.RequiredNodeLabels...)
+		issues = append(issues, models.Issue{
+			ID:       fmt.Sprintf("upgrade-blocked-cpu-mismatch-%s", m.Name),
+			Title:    "VM Migration Blocked - CPU Label Mismatch",
+			Severity: SeverityCritical,
+			Category: "Upgrade",
+			Description: "Migration blocked by verified CPU label mismatch. " +
+				"Target pods require specific CPU feature labels (e.g. ipred-ctrl) which are missing on target nodes. " +
+				fmt.Sprintf("Root cause: KubeVirt upgrade (%s -> %s) label inconsistency.", upgradeInfo.PreviousVersion, upgradeInfo.Version),
+			AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+			ResourceType:     "upgrade-blocked-migration",
+			ResourceName:     vm.Name,
+			Resources:        resources,
+			Evidence:         evidence,
+			VMName:           vm.Name,
+			VMNamespace:      vm.Namespace,
+			UpgradeDetails: &models.UpgradeDetails{
+				Version:           upgradeInfo.Version,
+				State:             upgradeInfo.State,
+				RequiredCPULabels: requiredLabels,
+			},
+			VerificationSteps: []models.IssueStep{{
+				ID:             "check-node-labels",
+				Title:          "Verify Node Labels",
+				Description:    "Check which nodes have the CPU feature label",
+				Command:        `kubectl get nodes -o jsonpath='{range .items[*]}{.metadata.name}{"\t"}{.metadata.labels.cpu-feature\.node\.kubevirt\.io/ipred-ctrl}{"\n"}{end}'`,
+				ExpectedOutput: expectedOutput,
+			}},
+			RemediationSteps: []models.IssueStep{
+				{
+					ID:          "annotate-skip-node",
+					Title:       "Annotate Nodes (Prevent Overwrite)",
+					Description: "Annotate nodes to prevent the node-labeller from overwriting your manual changes.",
+					Commands:    annotateCommands,
+				},
+				{
+					ID:          "add-missing-label",
+					Title:       "Add Missing CPU Label",
+					Description: "Add the missing CPU feature label to the affected nodes.",
+					Commands:    labelCommands,
+					Warning:     "This is a temporary fix. Remember to remove these manual labels and annotations after the upgrade completes on all nodes.",
+				},
+			},
+		})
+	}
+	return issues
+}
+
+// latestStuckMigration returns the most recent Pending or Failed migration
+func latestStuckMigration(migrations []models.VMIMInfo) (models.VMIMInfo, bool) {
+	var latest models.VMIMInfo
+	found := false
+	for _, m := range migrations {
+		if m.Phase != "Pending" && m.Phase != "Failed" {
+			continue
+		}
+		// RFC 3339 timestamps sort lexicographically
+		if !found || m.StartTimestamp > latest.StartTimestamp {
+			latest = m
+			found = true
+		}
+	}
+	return latest, found
+}
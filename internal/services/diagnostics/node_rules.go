@@ -0,0 +1,165 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// nodeRule reports nodes that are not Ready and the PDB problems found on them
+type nodeRule struct{}
+
+func (nodeRule) Name() string { return "node-health" }
+
+func (nodeRule) Check(data *models.FullClusterData) []models.Issue {
+	var issues []models.Issue
+	for i := range data.Nodes {
+		node := &data.Nodes[i]
+		nodeName := node.NodeInfo.Name
+		if nodeName == "" {
+			nodeName = "unknown"
+		}
+
+		// Either Longhorn or Kubernetes reporting Ready is enough
+		longhornReady := conditionStatus(node.NodeInfo.Conditions, "Ready")
+		k8sReady := ""
+		if node.KubernetesNodeInfo != nil {
+			k8sReady = conditionStatus(node.KubernetesNodeInfo.Conditions, "Ready")
+		}
+		if longhornReady != "True" && k8sReady != "True" {
+			issues = append(issues, withDefaultSteps(models.Issue{
+				ID:               fmt.Sprintf("node-not-ready-%s", nodeName),
+				Title:            "Node Not Ready",
+				Severity:         SeverityCritical,
+				Category:         "Node Health",
+				Description:      fmt.Sprintf("Node %s is not in Ready state. This affects VM scheduling and storage operations.", nodeName),
+				AffectedResource: fmt.Sprintf("Node: %s", nodeName),
+				ResourceType:     "node-not-ready",
+				ResourceName:     nodeName,
+				Resources:        []models.ResourceRef{nodeRef(nodeName)},
+				Evidence: []string{
+					fmt.Sprintf("longhorn Ready: %s", orUnknown(longhornReady)),
+					fmt.Sprintf("kubernetes Ready: %s", orUnknown(k8sReady)),
+				},
+				NodeName: nodeName,
+			}))
+		}
+
+		pdbHealth := node.PDBHealthStatus
+		if pdbHealth == nil || !pdbHealth.HasIssues {
+			continue
+		}
+		for _, pdbIssue := range pdbHealth.Issues {
+			issues = append(issues, withDefaultSteps(models.Issue{
+				ID:               fmt.Sprintf("pdb-%s-%s-%s", pdbIssue.IssueType, nodeName, pdbIssue.PDBName),
+				Title:            "PDB " + strings.ReplaceAll(pdbIssue.IssueType, "_", " "),
+				Severity:         pdbHealth.Severity,
+				Category:         "Pod Disruption Budget",
+				Description:      pdbIssue.Description,
+				AffectedResource: fmt.Sprintf("PDB: %s", pdbIssue.PDBName),
+				ResourceType:     "pdb",
+				ResourceName:     pdbIssue.PDBName,
+				Resources: []models.ResourceRef{
+					{Kind: "PodDisruptionBudget", Namespace: "longhorn-system", Name: pdbIssue.PDBName},
+					nodeRef(nodeName),
+				},
+				Evidence: []string{fmt.Sprintf("PDB protects %s, instance manager runs on %s",
+					pdbIssue.ExpectedNode, pdbIssue.ActualNode)},
+				NodeName:     nodeName,
+				PDBIssueType: pdbIssue.IssueType,
+				PDBDetails: &models.PDBIssueInfo{
+					ExpectedNode:    pdbIssue.ExpectedNode,
+					ActualNode:      pdbIssue.ActualNode,
+					StaleEngines:    pdbIssue.StaleEngines,
+					AffectedVolumes: pdbIssue.AffectedVolumes,
+					Resolution:      pdbIssue.Resolution,
+					SafetyCheck:     pdbIssue.SafetyCheck,
+					CanSafelyDelete: pdbHealth.CanSafelyDelete,
+					LastChecked:     pdbHealth.LastChecked,
+				},
+			}))
+		}
+	}
+	return issues
+}
+
+// nodeDiskRule reports Longhorn disks that cannot take new replicas
+type nodeDiskRule struct{}
+
+func (nodeDiskRule) Name() string { return "node-disks" }
+
+func (nodeDiskRule) Check(data *models.FullClusterData) []models.Issue {
+	var issues []models.Issue
+	for i := range data.Nodes {
+		node := &data.Nodes[i]
+		nodeName := node.NodeInfo.Name
+		if nodeName == "" {
+			nodeName = "unknown"
+		}
+
+		for _, disk := range node.NodeInfo.Disks {
+			if disk.IsSchedulable {
+				continue
+			}
+			diskName := diskDisplayName(disk.Path)
+
+			var evidence []string
+			if disk.PressureReason != "" {
+				evidence = append(evidence, fmt.Sprintf("%s: %s", disk.PressureReason, disk.PressureMessage))
+			}
+
+			issues = append(issues, withDefaultSteps(models.Issue{
+				ID:               fmt.Sprintf("disk-not-schedulable-%s-%s", nodeName, diskName),
+				Title:            "Disk Not Schedulable",
+				Severity:         SeverityWarning,
+				Category:         "Storage Health",
+				Description:      fmt.Sprintf("Disk %s on node %s is not schedulable. This reduces storage capacity and may affect VM scheduling.", diskName, nodeName),
+				AffectedResource: fmt.Sprintf("Node: %s, Disk: %s", nodeName, diskName),
+				ResourceType:     "disk-not-schedulable",
+				ResourceName:     diskName,
+				Resources:        []models.ResourceRef{nodeRef(nodeName)},
+				Evidence:         evidence,
+				NodeName:         nodeName,
+				DiskPath:         disk.Path,
+			}))
+		}
+	}
+	return issues
+}
+
+// diskDisplayName shortens a Longhorn disk path for display. Extra disks are
+// named by a long ID, which is abbreviated.
+func diskDisplayName(diskPath string) string {
+	if diskPath == "" {
+		return "Unknown"
+	}
+	if strings.Contains(diskPath, "/defaultdisk") {
+		return "defaultdisk"
+	}
+
+	name := diskPath[strings.LastIndex(diskPath, "/")+1:]
+	if strings.Contains(diskPath, "/extra-disks/") && len(name) > 16 {
+		return name[:8] + "..." + name[len(name)-8:]
+	}
+	if name == "" {
+		return "Unknown"
+	}
+	return name
+}
+
+func conditionStatus(conditions []models.NodeCondition, conditionType string) string {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
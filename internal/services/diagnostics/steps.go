@@ -0,0 +1,683 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// stepTarget is what the standard steps of an issue type are run against
+type stepTarget struct {
+	// Name is the issue's resource name: a volume, node, PDB or VM
+	Name        string
+	VMName      string
+	VMNamespace string
+}
+
+// verificationSteps returns the standard verification steps for an issue type
+func verificationSteps(resourceType string, t stepTarget) []models.IssueStep {
+	switch resourceType {
+	case "vm-pending":
+		return []models.IssueStep{{
+			ID:             "check-vm-status",
+			Title:          "Check VM Status",
+			Command:        fmt.Sprintf("kubectl get vm %s -n %s -o yaml", t.Name, t.VMNamespace),
+			ExpectedOutput: "VM should show current status and conditions",
+			Description:    "Check the VM status and any error conditions",
+		}}
+	case "replica-faulted":
+		return []models.IssueStep{{
+			ID:             "check-volume-status",
+			Title:          "Check Longhorn Volume Status",
+			Command:        fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o yaml", t.Name),
+			ExpectedOutput: "Volume should show current state and replica information",
+			Description:    "Check the Longhorn volume state and replica count",
+		}}
+	case "node-not-ready":
+		return []models.IssueStep{{
+			ID:             "check-node-status",
+			Title:          "Check Node Status",
+			Command:        fmt.Sprintf("kubectl get node %s -o wide", t.Name),
+			ExpectedOutput: "Node should show Ready status",
+			Description:    "Check basic node status and availability",
+		}}
+	case "pdb":
+		return []models.IssueStep{
+			{
+				ID:             "check-pdb-node-reference",
+				Title:          "Verify PDB Node Reference",
+				Command:        fmt.Sprintf(`kubectl get pdb %s -n longhorn-system -o yaml | yq '.spec.selector.matchLabels."longhorn.io/node"'`, t.Name),
+				ExpectedOutput: "Should show the node name this PDB claims to protect",
+				Description:    "Check which node this PDB is configured to protect",
+			},
+			{
+				ID:             "check-instance-manager-location",
+				Title:          "Check Instance Manager Actual Location",
+				Command:        fmt.Sprintf("kubectl get instancemanager %s -n longhorn-system -o yaml | yq '.spec.nodeID'", t.Name),
+				ExpectedOutput: "Should show where the instance manager actually runs",
+				Description:    "Verify the actual node where instance manager is located",
+			},
+			{
+				ID:             "check-claimed-engines",
+				Title:          "List Engines Claimed by Instance Manager",
+				Command:        fmt.Sprintf("kubectl get instancemanager %s -n longhorn-system -o jsonpath='{.status.instanceEngines}' | jq 'keys[]'", t.Name),
+				ExpectedOutput: "List of engine names the IM thinks it manages",
+				Description:    "See what engines this instance manager claims to manage",
+			},
+			{
+				ID:             "verify-engine-existence",
+				Title:          "Verify Engines Actually Exist",
+				Command:        "kubectl get engines.longhorn.io -n longhorn-system",
+				ExpectedOutput: "List of actual engine resources",
+				Description:    "Compare with step 3 to find phantom engines",
+			},
+			{
+				ID:             "check-volume-health",
+				Title:          "Verify Volume Health Before Fix",
+				Command:        `kubectl get volumes -n longhorn-system -o yaml | yq '.items[] | select(.status.state == "attached")| .status.robustness'`,
+				ExpectedOutput: `All outputs should be "healthy"`,
+				Description:    "Ensure all volumes are healthy before PDB deletion",
+			},
+		}
+	case "attachment-tickets-multiple":
+		return []models.IssueStep{
+			{
+				ID:             "analyze-ticket-types",
+				Title:          "Analyze Attachment Ticket Types",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.spec.attachmentTickets}' | jq 'to_entries[] | {ticketId: .key, type: .value.type, nodeID: .value.nodeID, parameters: .value.parameters}'", t.Name),
+				ExpectedOutput: "Detailed breakdown of each ticket type and target node",
+				Description:    "Critical first step: Identifies the purpose and target of each attachment ticket",
+			},
+			{
+				ID:             "check-ticket-generations",
+				Title:          "Check Ticket Generation Consistency",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o json | jq '{spec: [.spec.attachmentTickets | to_entries[] | {ticketId: .key, specGen: .value.generation}], status: [.status.attachmentTicketStatuses | to_entries[] | {ticketId: .key, statusGen: .value.generation, satisfied: .value.satisfied}]}'", t.Name),
+				ExpectedOutput: "Generation numbers should match between spec and status for healthy tickets",
+				Description:    "Mismatched generations indicate stale or processing tickets",
+			},
+			{
+				ID:             "verify-volume-safety-state",
+				Title:          "Verify Volume Safety State",
+				Command:        fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status.state}'", t.Name),
+				ExpectedOutput: "detached or attached (not attaching/detaching)",
+				Description:    "SAFETY CHECK: Ensure volume is in stable state before any remediation",
+				Warning:        "Do not proceed with remediation if volume is in transitional state",
+			},
+			{
+				ID:             "check-workload-sources",
+				Title:          "Identify Workload Sources of Tickets",
+				Command:        fmt.Sprintf(`kubectl get pods --all-namespaces -o json | jq '.items[] | select(.spec.volumes[]?.persistentVolumeClaim.claimName // "" | test("%s")) | {namespace: .metadata.namespace, name: .metadata.name, phase: .status.phase, nodeName: .spec.nodeName}'`, t.Name),
+				ExpectedOutput: "List of pods currently using this volume",
+				Description:    "Shows which workloads are requesting attachments - critical for safe remediation",
+			},
+		}
+	case "attachment-tickets-unsatisfied":
+		return []models.IssueStep{
+			{
+				ID:             "check-attachment-tickets",
+				Title:          "List All Volume Attachment Tickets",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.status.attachmentTicketStatuses}' | jq '.'", t.Name),
+				ExpectedOutput: "JSON of all attachment tickets with their status",
+				Description:    "Shows all attachment tickets and their current state",
+			},
+			{
+				ID:             "check-csi-attachments",
+				Title:          "Check CSI Volume Attachments",
+				Command:        fmt.Sprintf("kubectl get volumeattachment --all-namespaces | grep %s", t.Name),
+				ExpectedOutput: "List of CSI volume attachments",
+				Description:    "Shows CSI-level volume attachments that might be causing conflicts",
+			},
+		}
+	case "attachment-tickets-stuck-migration":
+		return []models.IssueStep{
+			{
+				ID:             "check-migration-timeline",
+				Title:          "Review Migration Timeline",
+				Command:        fmt.Sprintf(`kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.status.attachmentTicketStatuses}' | jq 'to_entries[] | {ticketId: .key, satisfied: .value.satisfied, lastTransition: (.value.conditions[]? | select(.type == "Satisfied") | .lastTransitionTime)}'`, t.Name),
+				ExpectedOutput: "Timeline showing when each attachment ticket was satisfied",
+				Description:    "TIMELINE ANALYSIS: Shows the chronological progression of the migration",
+			},
+			{
+				ID:             "check-migration-nodes",
+				Title:          "Check Migration Node Mapping",
+				Command:        fmt.Sprintf(`kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.spec.attachmentTickets}' | jq 'to_entries[] | select(.value.type == "csi-attacher") | {ticketId: .key, nodeID: .value.nodeID}'`, t.Name),
+				ExpectedOutput: "List of nodes involved in migration with ticket IDs",
+				Description:    "NODE MAPPING: Shows which nodes have CSI attachment tickets",
+			},
+			{
+				ID:             "find-pvc-name",
+				Title:          "Find PVC Claim Name",
+				Command:        fmt.Sprintf(`kubectl get pvc --all-namespaces -o json | jq -r '.items[] | select(.spec.volumeName == "%s") | "\(.metadata.namespace)/\(.metadata.name)"'`, t.Name),
+				ExpectedOutput: "namespace/pvc-name format",
+				Description:    "PVC RESOLUTION: Find the PVC claim name for this volume",
+			},
+			{
+				ID:             "find-vm-pod",
+				Title:          "Find VM Pods",
+				Command:        fmt.Sprintf("kubectl get pods -n %s -o wide -l vm.kubevirt.io/name=%s", t.VMNamespace, t.VMName),
+				ExpectedOutput: "Source and target virt-launcher pods with their nodes",
+				Description:    "VM LOCATION: Find where the VM's pods are running",
+			},
+			{
+				ID:             "check-vm-migration-status",
+				Title:          "Check for Active Migrations",
+				Command:        `kubectl get vmim --all-namespaces -o json | jq '.items[] | select(.status.phase == "Running" or .status.phase == "Pending") | {name: .metadata.name, phase: .status.phase, vmiName: .spec.vmiName, creationTime: .metadata.creationTimestamp}'`,
+				ExpectedOutput: "Active migration operations in the cluster",
+				Description:    "MIGRATION STATE: Check if there are active VM migrations that might be related",
+			},
+		}
+	case "attachment-tickets-stale-ui":
+		return []models.IssueStep{
+			{
+				ID:             "identify-stale-ui-tickets",
+				Title:          "Identify Stale UI Tickets",
+				Command:        fmt.Sprintf(`kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.spec.attachmentTickets}' | jq 'to_entries[] | select(.value.type == "longhorn-api") | {ticketId: .key, nodeID: .value.nodeID, lastAttachedBy: .value.parameters.lastAttachedBy}'`, t.Name),
+				ExpectedOutput: "List of longhorn-api tickets with details",
+				Description:    "Shows manual attachment tickets that may be stale",
+			},
+			{
+				ID:             "verify-no-ui-operations",
+				Title:          "Verify No Active UI Operations",
+				Command:        fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status}' | jq '{state: .state, robustness: .robustness, currentNodeID: .currentNodeID}'", t.Name),
+				ExpectedOutput: "Volume should not show active operations",
+				Description:    "Ensure no active Longhorn UI operations are in progress",
+			},
+		}
+	case "attachment-tickets-mixed-types":
+		return []models.IssueStep{
+			{
+				ID:             "analyze-operation-types",
+				Title:          "Analyze Mixed Operation Types",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.spec.attachmentTickets}' | jq '[to_entries[] | {ticketId: .key, type: .value.type, nodeID: .value.nodeID}] | group_by(.type)'", t.Name),
+				ExpectedOutput: "Tickets grouped by their operation type",
+				Description:    "Shows different types of operations targeting this volume",
+			},
+			{
+				ID:             "check-backup-snapshot-status",
+				Title:          "Check Backup/Snapshot Operations",
+				Command:        fmt.Sprintf(`kubectl get backups.longhorn.io,snapshots.longhorn.io -n longhorn-system -o json | jq '.items[] | select(.spec.volumeName == "%s") | {type: .kind, name: .metadata.name, state: .status.state}'`, t.Name),
+				ExpectedOutput: "Status of backup/snapshot operations",
+				Description:    "Verify if backup or snapshot operations are active or stuck",
+			},
+		}
+	case "attachment-tickets-multiple-unknown":
+		return []models.IssueStep{
+			{
+				ID:             "investigate-ticket-structure",
+				Title:          "Investigate Ticket Data Structure",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o yaml", t.Name),
+				ExpectedOutput: "Complete YAML showing ticket structure and all available fields",
+				Description:    "Raw data investigation to understand ticket format and identify missing type information",
+			},
+			{
+				ID:             "check-ticket-ids-for-clues",
+				Title:          "Analyze Ticket IDs for Type Clues",
+				Command:        fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.spec.attachmentTickets}' | jq 'keys[]'", t.Name),
+				ExpectedOutput: "List of ticket IDs that may contain type indicators",
+				Description:    "Ticket IDs often contain prefixes indicating their source (csi-, longhorn-api-, etc.)",
+			},
+			{
+				ID:             "verify-volume-current-state",
+				Title:          "Check Current Volume State",
+				Command:        fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status}' | jq '{state: .state, currentNodeID: .currentNodeID, robustness: .robustness}'", t.Name),
+				ExpectedOutput: "Current volume attachment state and health",
+				Description:    "Understanding current state helps determine if multiple tickets are problematic",
+			},
+			{
+				ID:             "check-for-stuck-operations",
+				Title:          "Look for Stuck Operations",
+				Command:        fmt.Sprintf(`kubectl get events --all-namespaces --sort-by='.lastTimestamp' | grep -i "%s" | tail -10`, t.Name),
+				ExpectedOutput: "Recent events related to this volume",
+				Description:    "Events may reveal what operations are creating multiple tickets",
+			},
+		}
+	case "attachment-condition-failed":
+		return []models.IssueStep{
+			{
+				ID:             "check-specific-condition",
+				Title:          "Check Failed Condition Details",
+				Command:        fmt.Sprintf(`kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o jsonpath='{.status.attachmentTicketStatuses}' | jq '.[] | select(.conditions[]?.status != "True")'`, t.Name),
+				ExpectedOutput: "Details about the failed condition",
+				Description:    "Get specifics about what attachment condition is failing",
+			},
+			{
+				ID:             "check-kubelet-logs",
+				Title:          "Check Recent Events",
+				Command:        fmt.Sprintf("kubectl get events --all-namespaces --sort-by='.lastTimestamp' | grep %s", t.Name),
+				ExpectedOutput: "Recent events related to this volume",
+				Description:    "Look for kubelet or CSI events about attachment failures",
+			},
+		}
+	}
+	return []models.IssueStep{}
+}
+
+// remediationSteps returns the standard remediation steps for an issue type
+func remediationSteps(resourceType string, t stepTarget) []models.IssueStep {
+	switch resourceType {
+	case "vm-pending":
+		return []models.IssueStep{{
+			ID:          "free-resources",
+			Title:       "Free Up Resources",
+			Command:     fmt.Sprintf("kubectl delete vm <unused-vm-name> -n %s", t.VMNamespace),
+			Description: "Delete unused VMs to free up resources",
+			Warning:     "Ensure VM is not needed before deletion",
+		}}
+	case "orphaned-replicas":
+		patchReplica := func(n int, ordinal, description, warning string) models.IssueStep {
+			return models.IssueStep{
+				ID:          fmt.Sprintf("patch-replica-%d", n),
+				Title:       fmt.Sprintf("Update %s Orphaned Replica", ordinal),
+				Description: description,
+				Command:     fmt.Sprintf(`kubectl patch replica <REPLICA_NAME_%d> -n longhorn-system --type merge -p '{"spec":{"engineName":"<CORRECT_ENGINE_NAME>"}}'`, n),
+				Warning:     warning,
+			}
+		}
+		return []models.IssueStep{
+			{
+				ID:          "identify-correct-engine",
+				Title:       "Identify the Correct Engine Name",
+				Description: "From verification step 2, copy the actual engine name that exists (e.g., pvc-xxx-e-0)",
+				Command:     "# The correct engine name is shown in verification step 2 output",
+				Warning:     "Make note of this engine name - you will use it in the next steps",
+			},
+			patchReplica(1, "First", "Patch the first replica to point to the correct engine",
+				"Replace <REPLICA_NAME_1> with actual replica name from verification, and <CORRECT_ENGINE_NAME> with engine from step 1"),
+			patchReplica(2, "Second", "Patch the second replica to point to the correct engine",
+				"Replace <REPLICA_NAME_2> with actual replica name from verification"),
+			patchReplica(3, "Third", "Patch the third replica if it exists",
+				"Only run if you have 3 replicas. Skip if only 2 replicas were orphaned."),
+			{
+				ID:             "verify-engine-recognizes-replicas",
+				Title:          "Verify Engine Now Has Replicas",
+				Description:    "Check that the engine now shows replicas in its address map",
+				Command:        "kubectl get engine <CORRECT_ENGINE_NAME> -n longhorn-system -o jsonpath='{.status.currentReplicaAddressMap}' | jq .",
+				ExpectedOutput: "Should show 2-3 replica entries with IP addresses (not empty map {})",
+			},
+			{
+				ID:             "verify-vm-startup",
+				Title:          "Verify VM Can Now Start",
+				Description:    "Check if VM successfully transitions from Scheduling to Running",
+				Command:        fmt.Sprintf("kubectl get vm %s -n %s -o jsonpath='{.status.printableStatus}'", t.VMName, t.VMNamespace),
+				ExpectedOutput: `Should show "Running" or progress from "Scheduling" to "Starting"`,
+			},
+			{
+				ID:             "check-pod-events",
+				Title:          "Confirm No More Attachment Errors",
+				Description:    "Verify pod no longer shows FailedAttachVolume errors",
+				Command:        fmt.Sprintf("kubectl get events -n %s --field-selector involvedObject.kind=Pod | grep virt-launcher-%s | grep -i attach", t.VMNamespace, t.VMName),
+				ExpectedOutput: `Should show "AttachVolume.Attach succeeded" instead of errors`,
+			},
+		}
+	case "node-not-ready":
+		return []models.IssueStep{{
+			ID:          "restart-node-services",
+			Title:       "Restart Node Services",
+			Command:     "# SSH to node and run: sudo systemctl restart rke2-server",
+			Description: "Restart RKE2 server service on the affected node",
+			Warning:     "This will temporarily disrupt workloads on the node",
+		}}
+	case "pdb":
+		pdbPrefix := t.Name
+		if parts := strings.Split(t.Name, "-"); len(parts) > 3 {
+			pdbPrefix = strings.Join(parts[:3], "-")
+		}
+		return []models.IssueStep{
+			{
+				ID:          "backup-pdb-config",
+				Title:       "Backup PDB Configuration",
+				Command:     fmt.Sprintf("kubectl get pdb %s -n longhorn-system -o yaml > pdb-%s-backup.yaml", t.Name, t.Name),
+				Description: "Save current PDB configuration before deletion",
+				Warning:     "Keep this backup in case rollback is needed",
+			},
+			{
+				ID:          "delete-problematic-pdb",
+				Title:       "Delete Problematic PDB",
+				Command:     fmt.Sprintf("kubectl delete pdb %s -n longhorn-system", t.Name),
+				Description: "Remove the misconfigured PDB - Longhorn will recreate it correctly",
+				Warning:     "Only run this if volume health verification passed",
+			},
+			{
+				ID:             "verify-pdb-recreation",
+				Title:          "Verify PDB Recreation",
+				Command:        fmt.Sprintf("kubectl get pdb -n longhorn-system | grep %s", pdbPrefix),
+				Description:    "Check that Longhorn recreated the PDB with correct configuration",
+				ExpectedOutput: "Should show new PDB with same name pattern within 30 seconds",
+			},
+			{
+				ID:          "confirm-node-draining",
+				Title:       "Test Node Draining (Optional)",
+				Command:     "kubectl drain <node-name> --dry-run=client --ignore-daemonsets",
+				Description: "Test if node can be drained now (dry run)",
+				Warning:     "Only run if this issue was blocking an upgrade",
+			},
+		}
+	case "attachment-tickets-multiple":
+		return []models.IssueStep{
+			{
+				ID:          "verify-volume-safety-state",
+				Title:       "Verify Volume Safety State",
+				Command:     fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status.state}'", t.Name),
+				Description: "SAFETY FIRST: Ensure volume is in stable state before any remediation",
+				Warning:     "Do not proceed with any ticket removal if volume is in attaching/detaching state",
+			},
+			{
+				ID:          "restart-attachment-controllers",
+				Title:       "Restart Volume Attachment Controllers (Safest Option)",
+				Command:     "kubectl delete pods -n longhorn-system -l app=longhorn-manager",
+				Description: "Restart Longhorn managers to resolve ticket conflicts gracefully",
+				Warning:     "This restarts all Longhorn managers - expect brief control plane disruption",
+			},
+			{
+				ID:          "manual-ticket-analysis",
+				Title:       "Manual Ticket Analysis (Advanced Users)",
+				Command:     fmt.Sprintf("kubectl get volumeattachments.longhorn.io %s -n longhorn-system -o yaml", t.Name),
+				Description: "Review full YAML to understand ticket sources before manual removal",
+				Warning:     "Only proceed with manual ticket removal if you understand the implications",
+			},
+		}
+	case "attachment-tickets-stuck-migration":
+		return []models.IssueStep{
+			{
+				ID:          "assess-migration-duration",
+				Title:       "Assess Migration Duration Risk",
+				Command:     "# Based on timeline analysis from verification",
+				Description: "RISK ASSESSMENT: If migration has been running > 24 hours, this is CRITICAL",
+				Warning:     "Long-running migrations (>1 day) pose serious data corruption risk",
+			},
+			{
+				ID:          "shutdown-vm-safe",
+				Title:       "Shutdown VM (Safest for Long-Running Migrations)",
+				Command:     fmt.Sprintf(`kubectl patch vm %s -n %s --type='merge' -p='{"spec":{"runStrategy":"Halted"}}'`, t.VMName, t.VMNamespace),
+				Description: "RECOMMENDED for migrations stuck >24h: Gracefully shutdown VM to prevent data corruption",
+				Warning:     "This stops the VM but prevents potential data corruption from split-brain scenario",
+			},
+			{
+				ID:          "verify-pod-migration-status",
+				Title:       "Verify Pod Migration Status (For Recent Migrations)",
+				Command:     fmt.Sprintf("kubectl get pods -n %s -o wide | grep %s && kubectl get events -n %s | grep %s", t.VMNamespace, t.VMName, t.VMNamespace, t.VMName),
+				Description: "For migrations <24h: Check if pod is actually migrating or stuck",
+				Warning:     "Only proceed if pod migration is truly stuck and VM timeline shows <24h duration",
+			},
+			{
+				ID:          "cancel-stuck-migration",
+				Title:       "Cancel Stuck Migration (Recent Migrations Only)",
+				Command:     fmt.Sprintf(`kubectl delete vmim -n %s $(kubectl get vmim -n %s -o jsonpath='{range .items[?(@.spec.vmiName=="%s")]}{.metadata.name}{" "}{end}')`, t.VMNamespace, t.VMNamespace, t.VMName),
+				Description: "Cancel the stuck migration for recent migrations (<24h)",
+				Warning:     "Only use for migrations stuck <24 hours. For longer migrations, use VM shutdown instead",
+			},
+			{
+				ID:          "restart-vm-after-cleanup",
+				Title:       "Restart VM After Cleanup",
+				Command:     fmt.Sprintf(`kubectl patch vm %s -n %s --type='merge' -p='{"spec":{"runStrategy":"RerunOnFailure"}}'`, t.VMName, t.VMNamespace),
+				Description: "After cleanup, restart the VM to establish clean storage attachment",
+				Warning:     "Only run after confirming attachment tickets are cleaned up",
+			},
+		}
+	case "attachment-tickets-stale-ui":
+		return []models.IssueStep{
+			{
+				ID:          "verify-no-active-ui-sessions",
+				Title:       "Verify No Active UI Sessions",
+				Command:     fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status}' | jq '{state: .state, robustness: .robustness}'", t.Name),
+				Description: "Ensure no active Longhorn UI operations on this volume",
+				Warning:     "Do not proceed if volume shows active operations",
+			},
+			{
+				ID:          "remove-stale-ui-ticket",
+				Title:       "Remove Stale longhorn-api Ticket",
+				Command:     fmt.Sprintf(`kubectl patch volumeattachments.longhorn.io %s -n longhorn-system --type='json' -p='[{"op": "remove", "path": "/spec/attachmentTickets/<TICKET_ID>"}]'`, t.Name),
+				Description: "Remove the specific longhorn-api ticket identified in verification",
+				Warning:     "Replace <TICKET_ID> with actual ID from verification step. This is irreversible.",
+			},
+		}
+	case "attachment-tickets-mixed-types":
+		return []models.IssueStep{
+			{
+				ID:          "wait-for-operations-completion",
+				Title:       "Wait for Operations to Complete",
+				Command:     fmt.Sprintf("kubectl get backups.longhorn.io,snapshots.longhorn.io -n longhorn-system | grep %s", t.Name),
+				Description: "Mixed tickets are often normal - wait for backup/snapshot completion",
+				Warning:     "Do not interrupt backup or snapshot operations unless they are truly stuck",
+			},
+			{
+				ID:          "check-operation-timeout",
+				Title:       "Check if Operations Are Stuck",
+				Command:     fmt.Sprintf(`kubectl get backups.longhorn.io,snapshots.longhorn.io -n longhorn-system -o json | jq '.items[] | select(.spec.volumeName == "%s") | {name: .metadata.name, state: .status.state, creationTime: .metadata.creationTimestamp}'`, t.Name),
+				Description: "Identify operations stuck for abnormally long time (>30 minutes)",
+				Warning:     "Only intervene if operations are stuck for over 30 minutes",
+			},
+		}
+	case "attachment-tickets-unsatisfied":
+		return []models.IssueStep{
+			{
+				ID:          "check-longhorn-manager-logs",
+				Title:       "Check Longhorn Manager Logs",
+				Command:     fmt.Sprintf(`kubectl logs -n longhorn-system -l app=longhorn-manager --tail=100 | grep -i "%s\|attachment"`, t.Name),
+				Description: "Look for attachment-related errors in Longhorn manager logs",
+			},
+			{
+				ID:          "check-csi-attacher-logs",
+				Title:       "Check CSI Attacher Logs",
+				Command:     fmt.Sprintf(`kubectl logs -n longhorn-system -l app=longhorn-csi-plugin --tail=50 | grep -i "%s\|attach"`, t.Name),
+				Description: "Check CSI attacher component for attachment issues",
+			},
+			{
+				ID:          "force-volume-detach-attach",
+				Title:       "Force Volume Re-attachment",
+				Command:     fmt.Sprintf(`kubectl annotate volumeattachments.longhorn.io %s -n longhorn-system volume.longhorn.io/detach-manually="true"`, t.Name),
+				Description: "Forces Longhorn to detach and re-attach the volume",
+				Warning:     "This may cause brief I/O interruption",
+			},
+		}
+	case "attachment-condition-failed":
+		return []models.IssueStep{{
+			ID:          "restart-csi-driver",
+			Title:       "Restart CSI Driver Pod",
+			Command:     fmt.Sprintf("kubectl delete pods -n longhorn-system -l app=longhorn-csi-plugin --field-selector spec.nodeName=$(kubectl get vmi %s -n %s -o jsonpath='{.status.nodeName}')", t.VMName, t.VMNamespace),
+			Description: "Restart CSI driver on the target node to resolve attachment issues",
+		}}
+	}
+	return []models.IssueStep{}
+}
+
+func healthCheckVerificationSteps(checkName string) []models.IssueStep {
+	switch checkName {
+	case "nodes":
+		return []models.IssueStep{
+			{
+				ID:             "check-node-status",
+				Title:          "Check Node Status",
+				Command:        "kubectl get nodes -o wide",
+				ExpectedOutput: "All nodes should be Ready",
+				Description:    "Verify node readiness and scheduling status",
+			},
+			{
+				ID:             "check-upgrade-context",
+				Title:          "Check for Active Upgrades",
+				Command:        "kubectl get upgrades -n harvester-system",
+				ExpectedOutput: "Shows if upgrade is in progress",
+				Description:    "Determine if cordoning is due to maintenance",
+			},
+		}
+	case "error_pods":
+		return []models.IssueStep{{
+			ID:             "check-pod-status",
+			Title:          "Check Pod Status",
+			Command:        "kubectl get pods --all-namespaces | grep -v Running | grep -v Completed",
+			ExpectedOutput: "No error pods should be listed",
+			Description:    "Find pods that are not running or completed",
+		}}
+	}
+	return []models.IssueStep{}
+}
+
+func healthCheckRemediationSteps(checkName string) []models.IssueStep {
+	switch checkName {
+	case "nodes":
+		return []models.IssueStep{
+			{
+				ID:          "check-upgrade-status",
+				Title:       "Check Upgrade Progress",
+				Command:     "kubectl get upgrades -n harvester-system -o wide",
+				Description: "Monitor upgrade progress before taking action",
+			},
+			{
+				ID:          "wait-or-investigate",
+				Title:       "Wait for Upgrade or Investigate",
+				Command:     "kubectl get nodes -o wide",
+				Description: "If upgrade in progress: wait. If stuck >1hr: investigate",
+			},
+			{
+				ID:          "uncordon-if-safe",
+				Title:       "Uncordon Only If No Upgrade",
+				Command:     "kubectl uncordon <node-name>",
+				Description: "Only if node is cordoned outside of upgrade operations",
+				Warning:     "Do not uncordon during active upgrades",
+			},
+		}
+	case "error_pods":
+		return []models.IssueStep{{
+			ID:          "restart-pods",
+			Title:       "Restart Failed Pods",
+			Command:     "kubectl delete pod <pod-name> -n <namespace>",
+			Description: "Restart failed pods to recover",
+		}}
+	}
+	return []models.IssueStep{}
+}
+
+func podVerificationSteps(pod models.PodError) []models.IssueStep {
+	steps := []models.IssueStep{
+		{
+			ID:             "check-pod-status",
+			Title:          "Check Pod Status",
+			Command:        fmt.Sprintf("kubectl get pod %s -n %s -o wide", pod.Name, pod.Namespace),
+			ExpectedOutput: "Pod current status and placement",
+			Description:    "Get basic pod status and node placement",
+		},
+		{
+			ID:             "describe-pod",
+			Title:          "Get Pod Events",
+			Command:        fmt.Sprintf("kubectl describe pod %s -n %s", pod.Name, pod.Namespace),
+			ExpectedOutput: "Pod events and error details",
+			Description:    "See detailed events and conditions",
+		},
+	}
+
+	switch {
+	case pod.ErrorState == "":
+	case pod.ErrorState == "CrashLoopBackOff":
+		steps = append(steps, models.IssueStep{
+			ID:             "check-crash-logs",
+			Title:          "Check Crash Logs",
+			Command:        fmt.Sprintf("kubectl logs %s -n %s --previous", pod.Name, pod.Namespace),
+			ExpectedOutput: "Previous container logs",
+			Description:    "Check why the container crashed",
+		})
+	case strings.Contains(pod.ErrorState, "ImagePull"):
+		steps = append(steps, models.IssueStep{
+			ID:             "check-image-details",
+			Title:          "Check Image Configuration",
+			Command:        fmt.Sprintf("kubectl get pod %s -n %s -o jsonpath='{.spec.containers[*].image}'", pod.Name, pod.Namespace),
+			ExpectedOutput: "Container image names",
+			Description:    "Verify image names and registry access",
+		})
+	default:
+		steps = append(steps, models.IssueStep{
+			ID:             "check-logs",
+			Title:          "Check Container Logs",
+			Command:        fmt.Sprintf("kubectl logs %s -n %s --tail=20", pod.Name, pod.Namespace),
+			ExpectedOutput: "Recent container logs",
+			Description:    "Check recent logs for error messages",
+		})
+	}
+	return steps
+}
+
+func podRemediationSteps(pod models.PodError) []models.IssueStep {
+	isFleetAgent := pod.Namespace == "cattle-fleet-system" && strings.Contains(pod.Name, "fleet-agent")
+
+	// Known Fleet upgrade race: the agent never finishes initializing until
+	// the fleet chart is rolled back
+	if isFleetAgent && pod.ErrorState == "PodInitializing" {
+		return []models.IssueStep{
+			{
+				ID:          "get-fleet-history",
+				Title:       "Get Fleet Helm History",
+				Command:     "helm history -n cattle-fleet-system fleet",
+				Description: "Find the last successfully deployed revision (status: deployed)",
+				Warning:     `Look for the highest revision with "deployed" status, not "pending-upgrade"`,
+			},
+			{
+				ID:          "rollback-fleet",
+				Title:       "Rollback Fleet to Working Revision",
+				Command:     "helm rollback fleet -n cattle-fleet-system <last-deployed-revision>",
+				Description: "Replace <last-deployed-revision> with the revision number from the previous step. This fixes the known Fleet upgrade race condition.",
+				Warning:     "This resolves the Fleet initialization issue during Harvester upgrades",
+			},
+			{
+				ID:          "verify-fleet-pods",
+				Title:       "Verify Fleet Pods Recovery",
+				Command:     "kubectl get pods -n cattle-fleet-system",
+				Description: "Ensure Fleet agent pods are no longer stuck in PodInitializing",
+			},
+			{
+				ID:          "check-upgrade-progress",
+				Title:       "Check Upgrade Progress",
+				Command:     "kubectl logs -n harvester-system -l harvesterhci.io/upgradeComponent=manifest -f",
+				Description: "Monitor that the upgrade continues after Fleet recovery",
+			},
+			{
+				ID:          "reference-docs",
+				Title:       "Reference Documentation",
+				Command:     "# See: https://docs.harvesterhci.io/v1.4/upgrade/v1-3-2-to-v1-4-0/#3-upgrade-stuck-on-waiting-for-fleet",
+				Description: "Official documentation for this known Fleet upgrade issue",
+			},
+		}
+	}
+
+	var steps []models.IssueStep
+	switch {
+	case pod.ErrorState == "ImagePullBackOff" || pod.ErrorState == "ErrImagePull":
+		steps = append(steps, models.IssueStep{
+			ID:          "fix-image-access",
+			Title:       "Check Image Registry Access",
+			Command:     fmt.Sprintf("kubectl get events -n %s --field-selector involvedObject.name=%s", pod.Namespace, pod.Name),
+			Description: "Check events for image pull errors and verify registry access",
+		})
+	case pod.ErrorState == "CrashLoopBackOff":
+		steps = append(steps, models.IssueStep{
+			ID:          "investigate-crash",
+			Title:       "Investigate Container Crash",
+			Command:     fmt.Sprintf("kubectl logs %s -n %s --previous --tail=50", pod.Name, pod.Namespace),
+			Description: "Check previous logs to understand why container crashed",
+		})
+	case pod.ErrorState == "CreateContainerConfigError":
+		steps = append(steps, models.IssueStep{
+			ID:          "check-config",
+			Title:       "Check Container Configuration",
+			Command:     fmt.Sprintf("kubectl get pod %s -n %s -o yaml", pod.Name, pod.Namespace),
+			Description: "Review pod configuration for errors in env vars, volumes, etc.",
+		})
+	case strings.HasPrefix(pod.ErrorState, "HighRestarts"):
+		steps = append(steps, models.IssueStep{
+			ID:          "investigate-restarts",
+			Title:       "Investigate High Restart Count",
+			Command:     fmt.Sprintf("kubectl logs %s -n %s --tail=50", pod.Name, pod.Namespace),
+			Description: "Check logs to understand why container keeps restarting",
+		})
+	}
+
+	if !isFleetAgent {
+		steps = append(steps, models.IssueStep{
+			ID:          "restart-pod",
+			Title:       "Restart Pod",
+			Command:     fmt.Sprintf("kubectl delete pod %s -n %s", pod.Name, pod.Namespace),
+			Description: "Delete pod to trigger restart/recreation",
+			Warning:     "This will cause temporary service interruption",
+		})
+	}
+	return steps
+}
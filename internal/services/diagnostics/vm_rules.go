@@ -0,0 +1,413 @@
+package diagnostics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// vmErrorRule reports the errors collected while building each VM
+type vmErrorRule struct{}
+
+func (vmErrorRule) Name() string { return "vm-errors" }
+
+func (vmErrorRule) Check(data *models.FullClusterData) []models.Issue {
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		for _, vmErr := range vm.Errors {
+			if vmErr.Severity == "info" || vmErr.Severity == "information" {
+				continue
+			}
+			severity := vmErr.Severity
+			if severity == "" {
+				severity = SeverityWarning
+			}
+			issues = append(issues, withDefaultSteps(models.Issue{
+				ID:               fmt.Sprintf("vm-error-%s-%s-%s", vm.Namespace, vm.Name, vmErr.Type),
+				Title:            fmt.Sprintf("%s Issue", strings.ToUpper(vmErr.Type)),
+				Severity:         severity,
+				Category:         "VM Resource",
+				Description:      vmErr.Message,
+				AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+				ResourceType:     vmErr.Type,
+				ResourceName:     vmErr.Resource,
+				Resources:        []models.ResourceRef{vmRef(vm)},
+				VMName:           vm.Name,
+				VMNamespace:      vm.Namespace,
+			}))
+		}
+	}
+	return issues
+}
+
+// vmPendingRule reports VMs with storage that are stuck in Pending
+type vmPendingRule struct{}
+
+func (vmPendingRule) Name() string { return "vm-pending" }
+
+func (vmPendingRule) Check(data *models.FullClusterData) []models.Issue {
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		if vm.PrintableStatus != "Pending" || vm.ClaimNames == "" {
+			continue
+		}
+		issues = append(issues, withDefaultSteps(models.Issue{
+			ID:               fmt.Sprintf("vm-pending-%s-%s", vm.Namespace, vm.Name),
+			Title:            "VM Stuck in Pending State",
+			Severity:         SeverityHigh,
+			Category:         "Scheduling",
+			Description:      fmt.Sprintf("VM %s/%s is stuck in Pending state, likely due to scheduling or storage issues.", vm.Namespace, vm.Name),
+			AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+			ResourceType:     "vm-pending",
+			ResourceName:     vm.Name,
+			Resources:        []models.ResourceRef{vmRef(vm)},
+			Evidence:         []string{"printableStatus: Pending"},
+			VMName:           vm.Name,
+			VMNamespace:      vm.Namespace,
+		}))
+	}
+	return issues
+}
+
+// vmStuckTerminatingRule reports VMs held in Terminating by finalizers after
+// their VMI and pods are gone
+type vmStuckTerminatingRule struct{}
+
+func (vmStuckTerminatingRule) Name() string { return "vm-stuck-terminating" }
+
+func (vmStuckTerminatingRule) Check(data *models.FullClusterData) []models.Issue {
+	isUpgrading := data.UpgradeInfo != nil &&
+		(data.UpgradeInfo.State == "Upgrading" || data.UpgradeInfo.State == "Running")
+
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		if vm.PrintableStatus != "Terminating" || len(vm.VMIInfo) > 0 || len(vm.PodInfo) > 0 || len(vm.Finalizers) == 0 {
+			continue
+		}
+
+		// Blocking an upgrade makes this more urgent
+		severity := SeverityMedium
+		upgradeNote := ""
+		if isUpgrading {
+			severity = SeverityHigh
+			upgradeNote = " This may block the cluster upgrade process."
+		}
+
+		cause := "Finalizers are preventing deletion."
+		if vm.RemovedPVCs != "" {
+			cause = "PVCs were already removed but finalizers remain."
+		}
+
+		var claimNames []string
+		for _, disk := range vm.Disks {
+			claimNames = append(claimNames, disk.ClaimName)
+		}
+		claimPattern := strings.Join(claimNames, "|")
+		if claimPattern == "" {
+			claimPattern = vm.ClaimNames
+		}
+		if claimPattern == "" {
+			claimPattern = "disk"
+		}
+
+		issues = append(issues, models.Issue{
+			ID:       fmt.Sprintf("vm-stuck-terminating-%s-%s", vm.Namespace, vm.Name),
+			Title:    "VM Stuck in Terminating State",
+			Severity: severity,
+			Category: "VM Lifecycle",
+			Description: fmt.Sprintf("VM %s/%s is stuck terminating with no VMI or pods. %s Blocking finalizers: %s%s",
+				vm.Namespace, vm.Name, cause, strings.Join(vm.Finalizers, ", "), upgradeNote),
+			AffectedResource: fmt.Sprintf("VM: %s/%s", vm.Namespace, vm.Name),
+			ResourceType:     "vm-stuck-terminating",
+			ResourceName:     vm.Name,
+			Resources:        []models.ResourceRef{vmRef(vm)},
+			Evidence:         []string{"finalizers: " + strings.Join(vm.Finalizers, ", ")},
+			VMName:           vm.Name,
+			VMNamespace:      vm.Namespace,
+			VerificationSteps: []models.IssueStep{
+				{
+					ID:          "check-vm-yaml",
+					Title:       "Get VM YAML to check volumes",
+					Description: "Check which PVCs this VM references",
+					Command:     fmt.Sprintf(`kubectl get vm %s -n %s -o yaml | grep -A 5 "volumes:"`, vm.Name, vm.Namespace),
+				},
+				{
+					ID:          "verify-vmi-pods",
+					Title:       "Verify VMI and Pods are deleted",
+					Description: "Confirm no active VMI or pods exist",
+					Command:     fmt.Sprintf("kubectl get vmi,pod -n %s | grep %s", vm.Namespace, vm.Name),
+				},
+				{
+					ID:          "check-pvcs",
+					Title:       "Verify PVCs from VM spec are deleted",
+					Description: "Check if PVCs referenced by the VM still exist",
+					Command:     fmt.Sprintf("kubectl get pvc -n %s | grep -E '%s'", vm.Namespace, claimPattern),
+				},
+				{
+					ID:          "remove-finalizer",
+					Title:       "Remove Finalizer (only if all resources gone)",
+					Description: "Safe to remove only after confirming VMI, pods, and PVCs are deleted",
+					Command:     fmt.Sprintf(`kubectl patch vm %s -n %s --type json -p '[{"op": "remove", "path": "/metadata/finalizers"}]'`, vm.Name, vm.Namespace),
+				},
+			},
+			RemediationSteps: []models.IssueStep{},
+		})
+	}
+	return issues
+}
+
+// replicaRule reports faulted replicas and replicas pointing at an engine
+// that no longer exists, once per disk so a faulted data disk is not hidden
+// behind the root disk
+type replicaRule struct{}
+
+func (replicaRule) Name() string { return "replica-health" }
+
+func (replicaRule) Check(data *models.FullClusterData) []models.Issue {
+	var issues []models.Issue
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		for _, disk := range vm.DiskViews() {
+			if len(disk.ReplicaInfo) == 0 {
+				continue
+			}
+			if issue, ok := faultedReplicaIssue(vm, disk); ok {
+				issues = append(issues, issue)
+			}
+			if issue, ok := orphanedReplicaIssue(vm, disk); ok {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+func faultedReplicaIssue(vm *models.VMInfo, disk models.VMDisk) (models.Issue, bool) {
+	var faulted []models.ReplicaInfo
+	for _, r := range disk.ReplicaInfo {
+		if r.CurrentState != "running" || !r.Started {
+			faulted = append(faulted, r)
+		}
+	}
+	if len(faulted) == 0 {
+		return models.Issue{}, false
+	}
+
+	var affectedNodes []string
+	seenNodes := make(map[string]bool)
+	for _, r := range faulted {
+		if r.NodeID != "" && !seenNodes[r.NodeID] {
+			seenNodes[r.NodeID] = true
+			affectedNodes = append(affectedNodes, r.NodeID)
+		}
+	}
+
+	// Universal diagnostic steps, run in order for any replica failure
+	var steps []models.IssueStep
+
+	// Replica spec: desired vs current state, retry count, when it last worked
+	for i, r := range faulted {
+		steps = append(steps, models.IssueStep{
+			ID:          fmt.Sprintf("replica-spec-%d", i),
+			Title:       fmt.Sprintf("Check Replica State: %s", shortName(r.Name)),
+			Description: "Shows desireState vs currentState, rebuildRetryCount, lastFailedAt, and lastHealthyAt. This tells you how long the replica has been broken and how many restart attempts have been made.",
+			Command:     fmt.Sprintf(`kubectl get replica.longhorn.io %s -n longhorn-system -o go-template=$'node: {{.spec.nodeID}}\ndisk: {{.spec.diskID}}\ndesireState: {{.spec.desireState}}\ncurrentState: {{.status.currentState}}\nrebuildRetries: {{.spec.rebuildRetryCount}}\nlastFailed: {{if .spec.lastFailedAt}}{{.spec.lastFailedAt}}{{else}}-{{end}}\nlastHealthy: {{if .spec.lastHealthyAt}}{{.spec.lastHealthyAt}}{{else}}-{{end}}'`, r.Name),
+		})
+	}
+
+	// Disk health on each affected node: schedulability, pressure, available vs scheduled
+	for i, nodeID := range affectedNodes {
+		diskKey := ""
+		for _, r := range faulted {
+			if r.NodeID != nodeID {
+				continue
+			}
+			if r.DiskPath != "" {
+				diskKey = r.DiskPath[strings.LastIndex(r.DiskPath, "/")+1:]
+			}
+			if diskKey == "" {
+				diskKey = r.DiskID
+			}
+			break
+		}
+
+		command := fmt.Sprintf(`kubectl get node.longhorn.io %s -n longhorn-system -o jsonpath='{.status.diskStatus}' | python3 -c "import sys,json; d=json.load(sys.stdin); [print(k, 'schedulable:', next((x.get('status') for x in v.get('conditions',[]) if x.get('type')=='Schedulable'),'-')) for k,v in d.items()]"`, nodeID)
+		if diskKey != "" {
+			command = fmt.Sprintf(`kubectl get node.longhorn.io %s -n longhorn-system -o jsonpath='{.status.diskStatus.%s}' | python3 -c "import sys,json; d=json.load(sys.stdin); s=d.get('conditions',[{}]); c=next((x for x in s if x.get('type')=='Schedulable'),{}); print('schedulable:', c.get('status')); print('reason:', c.get('reason','-')); print('message:', c.get('message','-')); print('available:', round(d.get('storageAvailable',0)/1e9,1), 'GB'); print('scheduled:', round(d.get('storageScheduled',0)/1e9,1), 'GB'); print('maximum:', round(d.get('storageMaximum',0)/1e9,1), 'GB')"`, nodeID, diskKey)
+		}
+		steps = append(steps, models.IssueStep{
+			ID:          fmt.Sprintf("disk-health-%d", i),
+			Title:       fmt.Sprintf("Check Disk Schedulability on %s", nodeID),
+			Description: "Checks if the disk is marked Schedulable=False (DiskPressure). If storageScheduled exceeds storageMaximum, Longhorn cannot start new replica processes on this disk.",
+			Command:     command,
+		})
+	}
+
+	// Node conditions: is the Longhorn node itself Ready?
+	for i, nodeID := range affectedNodes {
+		steps = append(steps, models.IssueStep{
+			ID:          fmt.Sprintf("node-conditions-%d", i),
+			Title:       fmt.Sprintf("Check Longhorn Node Conditions: %s", nodeID),
+			Description: "Checks Ready, Schedulable, MountPropagation, and KernelModules conditions. A node that is NotReady or missing kernel modules will prevent all replica processes from starting.",
+			Command:     fmt.Sprintf(`kubectl get node.longhorn.io %s -n longhorn-system -o jsonpath='{.status.conditions}' | python3 -c "import sys,json; [print(c['type']+': '+c['status']+((' ('+c['message']+')') if c.get('message') else '')) for c in json.load(sys.stdin)]"`, nodeID),
+		})
+	}
+
+	// Instance manager: is it running and does it have this replica's process?
+	for i, nodeID := range affectedNodes {
+		steps = append(steps, models.IssueStep{
+			ID:          fmt.Sprintf("instance-manager-%d", i),
+			Title:       fmt.Sprintf("Check Instance Manager on %s", nodeID),
+			Description: "The instance manager is the process that actually runs replica instances. If it is not Running, or if it has no instances, the replica process cannot start regardless of disk or node health.",
+			Command:     fmt.Sprintf("kubectl get instancemanager -n longhorn-system -l longhorn.io/node=%s -o custom-columns='NAME:.metadata.name,STATE:.status.currentState,TYPE:.spec.type'", nodeID),
+		})
+	}
+
+	// Controller logs: Longhorn's own reason for not starting the replica
+	for i, r := range faulted {
+		steps = append(steps, models.IssueStep{
+			ID:          fmt.Sprintf("controller-logs-%d", i),
+			Title:       fmt.Sprintf("Check Controller Logs for %s", shortName(r.Name)),
+			Description: `Filters longhorn-manager logs for this replica. Look for: "concurrent limit" (rebuild throttle), "cannot attach" (migration conflict), "failed to get" (connection refused), or "WaitForBackingImage". This is the most direct evidence of why Longhorn is refusing to start the replica.`,
+			Command:     fmt.Sprintf(`kubectl logs -n longhorn-system -l app=longhorn-manager --since=1h 2>/dev/null | grep "%s" | grep -iv "Creating instance\|reason: 'Start'" | uniq`, r.Name),
+		})
+	}
+
+	severity := SeverityHigh
+	if len(faulted) == len(disk.ReplicaInfo) {
+		severity = SeverityCritical
+	}
+
+	resources := []models.ResourceRef{vmRef(vm), volumeRef(disk.VolumeName)}
+	var evidence []string
+	for _, r := range faulted {
+		resources = append(resources, models.ResourceRef{Kind: "Replica", Namespace: "longhorn-system", Name: r.Name})
+		evidence = append(evidence, fmt.Sprintf("replica %s on %s: currentState=%s started=%t", r.Name, r.NodeID, r.CurrentState, r.Started))
+	}
+
+	return withDefaultSteps(models.Issue{
+		ID:                fmt.Sprintf("replica-issues-%s-%s-%s", vm.Namespace, vm.Name, disk.VolumeName),
+		Title:             "Storage Replica Issues",
+		Severity:          severity,
+		Category:          "Storage",
+		Description:       fmt.Sprintf("%d out of %d replicas are faulted for volume %s.", len(faulted), len(disk.ReplicaInfo), disk.VolumeName),
+		AffectedResource:  fmt.Sprintf("Volume: %s (VM: %s/%s)", disk.VolumeName, vm.Namespace, vm.Name),
+		ResourceType:      "replica-faulted",
+		ResourceName:      disk.VolumeName,
+		Resources:         resources,
+		Evidence:          evidence,
+		VMName:            vm.Name,
+		VMNamespace:       vm.Namespace,
+		VerificationSteps: steps,
+	}), true
+}
+
+// orphanedReplicaIssue detects replicas pointing to a deleted engine, left
+// behind by an incomplete stuck-migration cleanup (Longhorn bug 11479)
+func orphanedReplicaIssue(vm *models.VMInfo, disk models.VMDisk) (models.Issue, bool) {
+	if len(disk.EngineInfo) == 0 {
+		return models.Issue{}, false
+	}
+
+	existingEngines := make(map[string]bool)
+	var existingNames []string
+	for _, e := range disk.EngineInfo {
+		if !existingEngines[e.Name] {
+			existingEngines[e.Name] = true
+			existingNames = append(existingNames, e.Name)
+		}
+	}
+
+	var orphaned []string
+	var missingEngines []string
+	seenMissing := make(map[string]bool)
+	for _, r := range disk.ReplicaInfo {
+		if r.EngineName == "" || existingEngines[r.EngineName] {
+			continue
+		}
+		orphaned = append(orphaned, r.Name)
+		if !seenMissing[r.EngineName] {
+			seenMissing[r.EngineName] = true
+			missingEngines = append(missingEngines, r.EngineName)
+		}
+	}
+	if len(orphaned) == 0 {
+		return models.Issue{}, false
+	}
+
+	podName := vm.PodName
+	if podName == "" {
+		podName = "virt-launcher-" + vm.Name
+	}
+
+	var evidence []string
+	for _, r := range disk.ReplicaInfo {
+		if r.EngineName != "" && !existingEngines[r.EngineName] {
+			evidence = append(evidence, fmt.Sprintf("replica %s -> engine %s (missing)", r.Name, r.EngineName))
+		}
+	}
+	sort.Strings(evidence)
+
+	return withDefaultSteps(models.Issue{
+		ID:               fmt.Sprintf("orphaned-replicas-%s-%s-%s", vm.Namespace, vm.Name, disk.VolumeName),
+		Title:            "Orphaned Replicas - Engine Mismatch",
+		Severity:         SeverityCritical,
+		Category:         "Storage",
+		Description:      fmt.Sprintf("%d replicas point to deleted engine(s): %s. This prevents VM startup. Likely caused by incomplete stuck migration cleanup (Longhorn bug 11479).", len(orphaned), strings.Join(missingEngines, ", ")),
+		AffectedResource: fmt.Sprintf("Volume: %s (VM: %s/%s)", disk.VolumeName, vm.Namespace, vm.Name),
+		ResourceType:     "orphaned-replicas",
+		ResourceName:     disk.VolumeName,
+		Resources:        []models.ResourceRef{vmRef(vm), volumeRef(disk.VolumeName)},
+		Evidence:         evidence,
+		VMName:           vm.Name,
+		VMNamespace:      vm.Namespace,
+		AttachmentDetails: &models.AttachmentDetails{
+			OrphanedReplicas: orphaned,
+			MissingEngines:   missingEngines,
+			ExistingEngines:  existingNames,
+		},
+		VerificationSteps: []models.IssueStep{
+			{
+				ID:             "check-volume-state",
+				Title:          "Check Volume Status",
+				Description:    "Verify volume is detached and showing robustness=unknown",
+				Command:        fmt.Sprintf("kubectl get volumes.longhorn.io %s -n longhorn-system -o jsonpath='{.status.state} {.status.robustness}'", disk.VolumeName),
+				ExpectedOutput: "detached unknown",
+			},
+			{
+				ID:             "list-existing-engines",
+				Title:          "List Existing Engines",
+				Description:    "Find the actual engine that exists for this volume",
+				Command:        fmt.Sprintf(`kubectl get engines.longhorn.io -n longhorn-system -l longhornvolume=%s -o jsonpath='{range .items[*]}{.metadata.name}{" "}{.status.currentReplicaAddressMap}{"\n"}{end}'`, disk.VolumeName),
+				ExpectedOutput: fmt.Sprintf("Shows engine name (like %s-e-0) with empty or populated replica map", disk.VolumeName),
+			},
+			{
+				ID:             "check-replica-engine-refs",
+				Title:          "Check Replica Engine References",
+				Description:    "See which engine names replicas are pointing to",
+				Command:        fmt.Sprintf(`kubectl get replicas.longhorn.io -n longhorn-system -l longhornvolume=%s -o jsonpath='{range .items[*]}{.metadata.name}{" -> "}{.spec.engineName}{" ("}{.status.currentState}{")\n"}{end}'`, disk.VolumeName),
+				ExpectedOutput: fmt.Sprintf("Shows replicas pointing to missing engine (%s)", missingEngines[0]),
+			},
+			{
+				ID:             "check-vm-pod-error",
+				Title:          "Check VM Pod Error Message",
+				Description:    "See the actual attachment failure error",
+				Command:        fmt.Sprintf(`kubectl describe pod -n %s %s | grep -A 5 "FailedAttachVolume"`, vm.Namespace, podName),
+				ExpectedOutput: `Shows "no healthy or scheduled replica for starting"`,
+			},
+		},
+	}), true
+}
+
+// shortName returns the last 8 characters of a generated resource name
+func shortName(name string) string {
+	if len(name) <= 8 {
+		return name
+	}
+	return name[len(name)-8:]
+}
@@ -0,0 +1,242 @@
+// Package diff compares two snapshots of the cluster
+package diff
+
+import (
+	"sort"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// Compare returns what changed between two snapshots. Resources are only
+// compared where both snapshots carry them, so a snapshot without engines or
+// health checks, e.g. one rebuilt from history or read from a bundle, does not
+// show them all as removed.
+func Compare(from, to *models.FullClusterData) *models.Changeset {
+	c := &models.Changeset{
+		VMsAdded:       []string{},
+		VMsRemoved:     []string{},
+		StatusChanges:  []models.StatusChange{},
+		ReplicaChanges: []models.ReplicaChange{},
+		EngineRestarts: []models.EngineChange{},
+		DiskChanges:    []models.DiskChange{},
+		HealthChanges:  []models.StatusChange{},
+	}
+	compareVMs(c, from, to)
+	compareVolumes(c, volumes(from), volumes(to))
+	compareDisks(c, from, to)
+	compareHealth(c, from, to)
+	return c
+}
+
+func compareVMs(c *models.Changeset, from, to *models.FullClusterData) {
+	before := make(map[string]*models.VMInfo)
+	for i := range from.VMs {
+		before[vmKey(&from.VMs[i])] = &from.VMs[i]
+	}
+	after := make(map[string]*models.VMInfo)
+	for i := range to.VMs {
+		after[vmKey(&to.VMs[i])] = &to.VMs[i]
+	}
+
+	for _, key := range sortedKeys(after) {
+		old, ok := before[key]
+		if !ok {
+			c.VMsAdded = append(c.VMsAdded, key)
+			continue
+		}
+		if status := after[key].PrintableStatus; status != old.PrintableStatus {
+			c.StatusChanges = append(c.StatusChanges, models.StatusChange{
+				Kind: "vm", Name: key, Field: "printableStatus", From: old.PrintableStatus, To: status,
+			})
+		}
+	}
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			c.VMsRemoved = append(c.VMsRemoved, key)
+		}
+	}
+}
+
+func compareVolumes(c *models.Changeset, before, after map[string]models.VMDisk) {
+	for _, name := range sortedKeys(after) {
+		old, ok := before[name]
+		if !ok {
+			continue
+		}
+		disk := after[name]
+		if disk.VolumeRobustness != old.VolumeRobustness {
+			c.StatusChanges = append(c.StatusChanges, models.StatusChange{
+				Kind: "volume", Name: name, Field: "robustness", From: old.VolumeRobustness, To: disk.VolumeRobustness,
+			})
+		}
+		if disk.VolumeState != old.VolumeState {
+			c.StatusChanges = append(c.StatusChanges, models.StatusChange{
+				Kind: "volume", Name: name, Field: "state", From: old.VolumeState, To: disk.VolumeState,
+			})
+		}
+		compareReplicas(c, name, old.ReplicaInfo, disk.ReplicaInfo)
+		compareEngines(c, name, old.EngineInfo, disk.EngineInfo)
+	}
+}
+
+func compareReplicas(c *models.Changeset, volume string, before, after []models.ReplicaInfo) {
+	old := make(map[string]models.ReplicaInfo)
+	for _, r := range before {
+		old[r.Name] = r
+	}
+	current := make(map[string]bool)
+
+	for _, r := range after {
+		current[r.Name] = true
+		prev, ok := old[r.Name]
+		change := models.ReplicaChange{Volume: volume, Name: r.Name, ToNode: r.NodeID, ToState: r.CurrentState}
+		switch {
+		case !ok:
+			change.Change = "added"
+		case prev.NodeID != r.NodeID:
+			change.Change = "moved"
+		case prev.CurrentState != r.CurrentState:
+			change.Change = "state"
+		default:
+			continue
+		}
+		if ok {
+			change.FromNode, change.FromState = prev.NodeID, prev.CurrentState
+		}
+		c.ReplicaChanges = append(c.ReplicaChanges, change)
+	}
+	for _, r := range before {
+		if !current[r.Name] {
+			c.ReplicaChanges = append(c.ReplicaChanges, models.ReplicaChange{
+				Volume: volume, Name: r.Name, Change: "removed", FromNode: r.NodeID, FromState: r.CurrentState,
+			})
+		}
+	}
+}
+
+// compareEngines reports engines that were recreated under a new name, moved
+// node with the volume, or went through a state change
+func compareEngines(c *models.Changeset, volume string, before, after []models.EngineInfo) {
+	if len(before) == 0 || len(after) == 0 {
+		return
+	}
+	old := make(map[string]models.EngineInfo)
+	for _, e := range before {
+		old[e.Name] = e
+	}
+	current := make(map[string]bool)
+	for _, e := range after {
+		current[e.Name] = true
+	}
+	var gone []models.EngineInfo
+	for _, e := range before {
+		if !current[e.Name] {
+			gone = append(gone, e)
+		}
+	}
+
+	for _, e := range after {
+		change := models.EngineChange{Volume: volume, Name: e.Name, ToNode: e.NodeID, ToState: e.CurrentState}
+		prev, ok := old[e.Name]
+		switch {
+		case !ok && len(gone) > 0:
+			prev = gone[0]
+			gone = gone[1:]
+			change.Reason = "replaced"
+			change.Previous = prev.Name
+		case !ok:
+			continue
+		case prev.NodeID != e.NodeID:
+			change.Reason = "moved"
+		case prev.CurrentState != e.CurrentState:
+			change.Reason = "state"
+		default:
+			continue
+		}
+		change.FromNode, change.FromState = prev.NodeID, prev.CurrentState
+		c.EngineRestarts = append(c.EngineRestarts, change)
+	}
+}
+
+func compareDisks(c *models.Changeset, from, to *models.FullClusterData) {
+	before := make(map[string]models.DiskInfo)
+	for _, node := range from.Nodes {
+		for _, disk := range node.NodeInfo.Disks {
+			before[node.NodeInfo.Name+"/"+disk.Name] = disk
+		}
+	}
+	for _, node := range to.Nodes {
+		for _, disk := range node.NodeInfo.Disks {
+			old, ok := before[node.NodeInfo.Name+"/"+disk.Name]
+			if !ok || old.IsSchedulable == disk.IsSchedulable {
+				continue
+			}
+			c.DiskChanges = append(c.DiskChanges, models.DiskChange{
+				Node:            node.NodeInfo.Name,
+				Disk:            disk.Name,
+				Schedulable:     disk.IsSchedulable,
+				PressureReason:  disk.PressureReason,
+				PressureMessage: disk.PressureMessage,
+			})
+		}
+	}
+	sort.SliceStable(c.DiskChanges, func(i, j int) bool {
+		a, b := c.DiskChanges[i], c.DiskChanges[j]
+		return a.Node+"/"+a.Disk < b.Node+"/"+b.Disk
+	})
+}
+
+func compareHealth(c *models.Changeset, from, to *models.FullClusterData) {
+	if from.HealthChecks == nil || to.HealthChecks == nil {
+		return
+	}
+	before := make(map[string]string)
+	for _, r := range from.HealthChecks.Results {
+		before[r.CheckName] = r.Status
+	}
+	after := make(map[string]string)
+	for _, r := range to.HealthChecks.Results {
+		after[r.CheckName] = r.Status
+	}
+
+	names := sortedKeys(before)
+	for _, name := range sortedKeys(after) {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if before[name] != after[name] {
+			c.HealthChanges = append(c.HealthChanges, models.StatusChange{
+				Kind: "health", Name: name, Field: "status", From: before[name], To: after[name],
+			})
+		}
+	}
+}
+
+// volumes indexes every PVC-backed disk by its Longhorn volume
+func volumes(data *models.FullClusterData) map[string]models.VMDisk {
+	result := make(map[string]models.VMDisk)
+	for i := range data.VMs {
+		for _, disk := range data.VMs[i].DiskViews() {
+			if _, seen := result[disk.VolumeName]; disk.VolumeName != "" && !seen {
+				result[disk.VolumeName] = disk
+			}
+		}
+	}
+	return result
+}
+
+func vmKey(vm *models.VMInfo) string {
+	return vm.Namespace + "/" + vm.Name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"testing"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func cluster(vmStatus, replicaNode, engineName string, schedulable bool, health string) *models.FullClusterData {
+	return &models.FullClusterData{
+		VMs: []models.VMInfo{{
+			Name:            "vm1",
+			Namespace:       "default",
+			PrintableStatus: vmStatus,
+			Disks: []models.VMDisk{{
+				VolumeName:       "pvc-1",
+				VolumeRobustness: "healthy",
+				ReplicaInfo: []models.ReplicaInfo{
+					{Name: "r-a", NodeID: replicaNode, CurrentState: "running"},
+					{Name: "r-b", NodeID: "node-3", CurrentState: "running"},
+				},
+				EngineInfo: []models.EngineInfo{{Name: engineName, NodeID: "node-1", CurrentState: "running"}},
+			}},
+		}},
+		Nodes: []models.NodeWithMetrics{{NodeInfo: models.NodeInfo{
+			Name:  "node-1",
+			Disks: []models.DiskInfo{{Name: "default-disk", IsSchedulable: schedulable}},
+		}}},
+		HealthChecks: &models.HealthCheckSummary{Results: []models.HealthCheckResult{{CheckName: "nodes", Status: health}}},
+	}
+}
+
+func TestCompare_ReportsEachKindOfChange(t *testing.T) {
+	before := cluster("Running", "node-1", "pvc-1-e-0", true, "passed")
+	after := cluster("Stopped", "node-2", "pvc-1-e-1", false, "failed")
+	after.VMs = append(after.VMs, models.VMInfo{Name: "vm2", Namespace: "default"})
+
+	c := Compare(before, after)
+
+	if len(c.VMsAdded) != 1 || c.VMsAdded[0] != "default/vm2" || len(c.VMsRemoved) != 0 {
+		t.Errorf("unexpected VM changes %v %v", c.VMsAdded, c.VMsRemoved)
+	}
+	if len(c.StatusChanges) != 1 || c.StatusChanges[0].From != "Running" || c.StatusChanges[0].To != "Stopped" {
+		t.Errorf("unexpected status changes %+v", c.StatusChanges)
+	}
+	if len(c.ReplicaChanges) != 1 || c.ReplicaChanges[0].Change != "moved" || c.ReplicaChanges[0].ToNode != "node-2" {
+		t.Errorf("unexpected replica changes %+v", c.ReplicaChanges)
+	}
+	if len(c.EngineRestarts) != 1 || c.EngineRestarts[0].Reason != "replaced" || c.EngineRestarts[0].Previous != "pvc-1-e-0" {
+		t.Errorf("unexpected engine changes %+v", c.EngineRestarts)
+	}
+	if len(c.DiskChanges) != 1 || c.DiskChanges[0].Schedulable {
+		t.Errorf("unexpected disk changes %+v", c.DiskChanges)
+	}
+	if len(c.HealthChanges) != 1 || c.HealthChanges[0].To != "failed" {
+		t.Errorf("unexpected health changes %+v", c.HealthChanges)
+	}
+}
+
+func TestCompare_IgnoresDataMissingFromOneSide(t *testing.T) {
+	before := cluster("Running", "node-1", "pvc-1-e-0", true, "passed")
+	after := cluster("Running", "node-1", "pvc-1-e-0", true, "passed")
+	after.HealthChecks = nil
+	after.VMs[0].Disks[0].EngineInfo = nil
+
+	c := Compare(before, after)
+	if len(c.EngineRestarts) != 0 || len(c.HealthChanges) != 0 {
+		t.Errorf("expected no changes, got %+v", c)
+	}
+}
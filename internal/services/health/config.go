@@ -0,0 +1,69 @@
+package health
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCheckTimeout bounds a single check so one slow List cannot
+	// hold up the whole health summary
+	DefaultCheckTimeout = 15 * time.Second
+	// DefaultConcurrency is how many checks run at once
+	DefaultConcurrency = 4
+	// DefaultInterval is how often the dashboard re-runs the checks
+	DefaultInterval = 2 * time.Minute
+)
+
+// Config selects which health checks run and how
+type Config struct {
+	// Enabled lists the checks to run. Empty runs every registered check.
+	Enabled []string
+	// Disabled lists checks to skip, even when enabled
+	Disabled []string
+	// Timeout is the deadline for each check
+	Timeout time.Duration
+	// Concurrency is how many checks run at once
+	Concurrency int
+	// Interval is how often the checks are re-run; fetches in between
+	// serve the last result
+	Interval time.Duration
+}
+
+// DefaultConfig runs every registered check with the default timeout
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     DefaultCheckTimeout,
+		Concurrency: DefaultConcurrency,
+		Interval:    DefaultInterval,
+	}
+}
+
+// IsEnabled reports whether the named check should run
+func (c Config) IsEnabled(name string) bool {
+	for _, disabled := range c.Disabled {
+		if disabled == name {
+			return false
+		}
+	}
+	if len(c.Enabled) == 0 {
+		return true
+	}
+	for _, enabled := range c.Enabled {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCheckList splits a comma-separated list of check names
+func ParseCheckList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -2,74 +2,163 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	models "github.com/rk280392/harvesterNavigator/internal/models"
 )
 
+// defaultMinimalAvailablePercentage is Longhorn's default for the
+// storage-minimal-available-percentage setting
+const defaultMinimalAvailablePercentage = 25
+
+var (
+	fleetBundlesGVR     = schema.GroupVersionResource{Group: "fleet.cattle.io", Version: "v1alpha1", Resource: "bundles"}
+	managedChartsGVR    = schema.GroupVersionResource{Group: "management.cattle.io", Version: "v3", Resource: "managedcharts"}
+	capiClustersGVR     = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "clusters"}
+	capiMachinesGVR     = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machines"}
+	longhornVolumesGVR  = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "volumes"}
+	longhornNodesGVR    = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "nodes"}
+	longhornSettingsGVR = schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "settings"}
+)
+
 type HealthChecker struct {
-	clientset   *kubernetes.Clientset
-	upgradeInfo *models.UpgradeInfo
+	clientset     *kubernetes.Clientset
+	dynamicClient dynamic.Interface
+	upgradeInfo   *models.UpgradeInfo
+	config        Config
+	registry      *Registry
 }
 
-func CreateHealthChecker(clientset *kubernetes.Clientset, upgradeInfo *models.UpgradeInfo) *HealthChecker {
+// CreateHealthChecker creates a health checker that runs the checks of
+// DefaultRegistry selected by config
+func CreateHealthChecker(clientset *kubernetes.Clientset, dynamicClient dynamic.Interface, upgradeInfo *models.UpgradeInfo, config Config) *HealthChecker {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultCheckTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
 	return &HealthChecker{
-		clientset:   clientset,
-		upgradeInfo: upgradeInfo,
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		upgradeInfo:   upgradeInfo,
+		config:        config,
+		registry:      DefaultRegistry,
 	}
 }
 
+// Clientset returns the typed client registered checks can use
+func (h *HealthChecker) Clientset() *kubernetes.Clientset { return h.clientset }
+
+// DynamicClient returns the dynamic client registered checks can use
+func (h *HealthChecker) DynamicClient() dynamic.Interface { return h.dynamicClient }
+
+// UpgradeInfo returns the upgrade in progress, if any
+func (h *HealthChecker) UpgradeInfo() *models.UpgradeInfo { return h.upgradeInfo }
+
+// RunAllChecks runs the enabled checks concurrently, each under its own
+// deadline, and returns their results in registration order
 func (h *HealthChecker) RunAllChecks(ctx context.Context) *models.HealthCheckSummary {
 	startTime := time.Now()
 
-	checks := []func(context.Context) models.HealthCheckResult{
-		h.checkBundles,
-		h.checkHarvesterBundle,
-		h.checkNodes,
-		h.checkCluster,
-		h.checkMachines,
-		h.checkVolumes,
-		h.checkAttachedVolumes,
-		h.checkErrorPods,
-		h.checkFreeSpace,
-	}
+	checks := h.registry.build(h, h.config)
+	results := make([]models.HealthCheckResult, len(checks))
 
-	var results []models.HealthCheckResult
-
-	for _, checkFunc := range checks {
-		result := checkFunc(ctx)
-		results = append(results, result)
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, h.config.Concurrency)
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results[i] = h.runCheck(ctx, check)
+		}()
 	}
+	wg.Wait()
 
-	// Calculate summary
-	passed := 0
-	failed := 0
-	warnings := 0
-
+	summary := &models.HealthCheckSummary{
+		TotalChecks: len(results),
+		LastRun:     startTime,
+		Results:     results,
+	}
 	for _, result := range results {
 		switch result.Status {
 		case "passed":
-			passed++
+			summary.PassedChecks++
 		case "failed":
-			failed++
+			summary.FailedChecks++
 		case "warning":
-			warnings++
+			summary.WarningChecks++
+		case "timeout":
+			summary.TimeoutChecks++
+		}
+	}
+	return summary
+}
+
+// runCheck runs one check under the configured deadline. A check that
+// overruns it, or fails because of it, is reported as timed out.
+func (h *HealthChecker) runCheck(ctx context.Context, check HealthCheck) models.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan models.HealthCheckResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- models.HealthCheckResult{
+					CheckName: check.Name(),
+					Status:    "failed",
+					Error:     fmt.Sprintf("Check panicked: %v", r),
+					Timestamp: start,
+					Duration:  time.Since(start).String(),
+				}
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+
+	var result models.HealthCheckResult
+	select {
+	case result = <-done:
+		if result.Status == "failed" && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result = timeoutResult(check, start, h.config.Timeout)
 		}
+	case <-ctx.Done():
+		result = timeoutResult(check, start, h.config.Timeout)
 	}
 
-	return &models.HealthCheckSummary{
-		TotalChecks:   len(results),
-		PassedChecks:  passed,
-		FailedChecks:  failed,
-		WarningChecks: warnings,
-		LastRun:       startTime,
-		Results:       results,
+	if result.CheckName == "" {
+		result.CheckName = check.Name()
+	}
+	result.Category = check.Category()
+	return result
+}
+
+func timeoutResult(check HealthCheck, start time.Time, timeout time.Duration) models.HealthCheckResult {
+	return models.HealthCheckResult{
+		CheckName: check.Name(),
+		Status:    "timeout",
+		Error:     fmt.Sprintf("Check did not complete within %v", timeout),
+		Timestamp: start,
+		Duration:  time.Since(start).String(),
 	}
 }
 
@@ -80,11 +169,30 @@ func (h *HealthChecker) checkBundles(ctx context.Context) models.HealthCheckResu
 		Timestamp: start,
 	}
 
-	// For now, we'll simulate this check since Fleet bundles require dynamic client setup
-	// You can implement the full kubectl equivalent later
-	result.Status = "passed"
-	result.Message = "Bundle check simulated - implement with Fleet API"
+	bundles, err := h.dynamicClient.Resource(fleetBundlesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Failed to list Fleet bundles: %v", err)
+		result.Duration = time.Since(start).String()
+		return result
+	}
+
+	var notReady []string
+	for _, bundle := range bundles.Items {
+		if detail, ready := bundleReadiness(&bundle); !ready {
+			notReady = append(notReady, detail)
+		}
+	}
+
 	result.Duration = time.Since(start).String()
+	if len(notReady) > 0 {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("%d of %d Fleet bundles are not ready", len(notReady), len(bundles.Items))
+		result.Details = notReady
+	} else {
+		result.Status = "passed"
+		result.Message = fmt.Sprintf("All %d Fleet bundles are ready", len(bundles.Items))
+	}
 
 	return result
 }
@@ -96,10 +204,52 @@ func (h *HealthChecker) checkHarvesterBundle(ctx context.Context) models.HealthC
 		Timestamp: start,
 	}
 
-	// Simulate for now
-	result.Status = "passed"
-	result.Message = "Harvester bundle check simulated"
+	var issues []string
+
+	// The harvester ManagedChart is rolled out through the mcc-harvester bundle
+	chart, err := h.dynamicClient.Resource(managedChartsGVR).Namespace("fleet-local").Get(ctx, "harvester", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		issues = append(issues, "ManagedChart fleet-local/harvester not found")
+	case err != nil:
+		issues = append(issues, fmt.Sprintf("Failed to get ManagedChart fleet-local/harvester: %v", err))
+	default:
+		conditions, _, _ := unstructured.NestedSlice(chart.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			conditionType, _, _ := unstructured.NestedString(condition, "type")
+			status, _, _ := unstructured.NestedString(condition, "status")
+			if conditionType == "Ready" && status != "True" {
+				message, _, _ := unstructured.NestedString(condition, "message")
+				issues = append(issues, fmt.Sprintf("ManagedChart fleet-local/harvester is not Ready: %s", message))
+			}
+		}
+	}
+
+	bundle, err := h.dynamicClient.Resource(fleetBundlesGVR).Namespace("fleet-local").Get(ctx, "mcc-harvester", metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		issues = append(issues, "Bundle fleet-local/mcc-harvester not found")
+	case err != nil:
+		issues = append(issues, fmt.Sprintf("Failed to get bundle fleet-local/mcc-harvester: %v", err))
+	default:
+		if detail, ready := bundleReadiness(bundle); !ready {
+			issues = append(issues, detail)
+		}
+	}
+
 	result.Duration = time.Since(start).String()
+	if len(issues) > 0 {
+		result.Status = "failed"
+		result.Error = "Harvester bundle is not ready"
+		result.Details = issues
+	} else {
+		result.Status = "passed"
+		result.Message = "Harvester ManagedChart and bundle are ready"
+	}
 
 	return result
 }
@@ -237,10 +387,35 @@ func (h *HealthChecker) checkCluster(ctx context.Context) models.HealthCheckResu
 		Timestamp: start,
 	}
 
-	// Simulate cluster check for now - requires CAPI setup
-	result.Status = "passed"
-	result.Message = "Cluster check simulated"
+	clusters, err := h.dynamicClient.Resource(capiClustersGVR).Namespace("fleet-local").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Failed to list CAPI clusters: %v", err)
+		result.Duration = time.Since(start).String()
+		return result
+	}
+
+	var notProvisioned []string
+	for _, cluster := range clusters.Items {
+		phase, _, _ := unstructured.NestedString(cluster.Object, "status", "phase")
+		if phase != "Provisioned" {
+			notProvisioned = append(notProvisioned, fmt.Sprintf("Cluster %s/%s is in phase %s", cluster.GetNamespace(), cluster.GetName(), orUnknown(phase)))
+		}
+	}
+
 	result.Duration = time.Since(start).String()
+	switch {
+	case len(clusters.Items) == 0:
+		result.Status = "failed"
+		result.Error = "No CAPI cluster found in fleet-local"
+	case len(notProvisioned) > 0:
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("%d clusters are not provisioned", len(notProvisioned))
+		result.Details = notProvisioned
+	default:
+		result.Status = "passed"
+		result.Message = "Cluster is provisioned"
+	}
 
 	return result
 }
@@ -252,10 +427,32 @@ func (h *HealthChecker) checkMachines(ctx context.Context) models.HealthCheckRes
 		Timestamp: start,
 	}
 
-	// Simulate machines check for now
-	result.Status = "passed"
-	result.Message = "Machines check simulated"
+	machines, err := h.dynamicClient.Resource(capiMachinesGVR).Namespace("fleet-local").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Failed to list CAPI machines: %v", err)
+		result.Duration = time.Since(start).String()
+		return result
+	}
+
+	var notRunning []string
+	for _, machine := range machines.Items {
+		phase, _, _ := unstructured.NestedString(machine.Object, "status", "phase")
+		if phase != "Running" {
+			nodeName, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name")
+			notRunning = append(notRunning, fmt.Sprintf("Machine %s (node %s) is in phase %s", machine.GetName(), orUnknown(nodeName), orUnknown(phase)))
+		}
+	}
+
 	result.Duration = time.Since(start).String()
+	if len(notRunning) > 0 {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("%d of %d machines are not running", len(notRunning), len(machines.Items))
+		result.Details = notRunning
+	} else {
+		result.Status = "passed"
+		result.Message = fmt.Sprintf("All %d machines are running", len(machines.Items))
+	}
 
 	return result
 }
@@ -315,10 +512,41 @@ func (h *HealthChecker) checkAttachedVolumes(ctx context.Context) models.HealthC
 		Timestamp: start,
 	}
 
-	// Simulate for now
-	result.Status = "passed"
-	result.Message = "No stale Longhorn volumes detected"
+	volumes, err := h.dynamicClient.Resource(longhornVolumesGVR).Namespace("longhorn-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Failed to list Longhorn volumes: %v", err)
+		result.Duration = time.Since(start).String()
+		return result
+	}
+
+	// An attached volume without a running pod blocks draining its node
+	var stale []string
+	for _, volume := range volumes.Items {
+		state, _, _ := unstructured.NestedString(volume.Object, "status", "state")
+		if state != "attached" || hasRunningWorkload(&volume) {
+			continue
+		}
+		nodeID, _, _ := unstructured.NestedString(volume.Object, "status", "currentNodeID")
+		pvcName, _, _ := unstructured.NestedString(volume.Object, "status", "kubernetesStatus", "pvcName")
+		pvcNamespace, _, _ := unstructured.NestedString(volume.Object, "status", "kubernetesStatus", "namespace")
+
+		detail := fmt.Sprintf("Volume %s is attached to node %s with no running workload", volume.GetName(), orUnknown(nodeID))
+		if pvcName != "" {
+			detail += fmt.Sprintf(" (PVC %s/%s)", pvcNamespace, pvcName)
+		}
+		stale = append(stale, detail)
+	}
+
 	result.Duration = time.Since(start).String()
+	if len(stale) > 0 {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("%d attached volumes have no running workload", len(stale))
+		result.Details = stale
+	} else {
+		result.Status = "passed"
+		result.Message = "No stale Longhorn volumes detected"
+	}
 
 	return result
 }
@@ -509,10 +737,120 @@ func (h *HealthChecker) checkFreeSpace(ctx context.Context) models.HealthCheckRe
 		Timestamp: start,
 	}
 
-	// This would require Prometheus metrics - simulate for now
-	result.Status = "passed"
-	result.Message = "Free space check simulated - requires Prometheus integration"
+	minimalPercentage := h.minimalAvailablePercentage(ctx)
+
+	nodes, err := h.dynamicClient.Resource(longhornNodesGVR).Namespace("longhorn-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Failed to list Longhorn nodes: %v", err)
+		result.Duration = time.Since(start).String()
+		return result
+	}
+
+	var lowDisks []string
+	diskCount := 0
+	for _, node := range nodes.Items {
+		diskStatus, _, _ := unstructured.NestedMap(node.Object, "status", "diskStatus")
+		for diskName, status := range diskStatus {
+			disk, ok := status.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			available, _, _ := unstructured.NestedInt64(disk, "storageAvailable")
+			maximum, _, _ := unstructured.NestedInt64(disk, "storageMaximum")
+			if maximum <= 0 {
+				continue
+			}
+			diskCount++
+
+			percentage := available * 100 / maximum
+			if percentage < minimalPercentage {
+				lowDisks = append(lowDisks, fmt.Sprintf("Node %s disk %s: %s of %s free (%d%%, minimum %d%%)",
+					node.GetName(), diskName, formatBytes(available), formatBytes(maximum), percentage, minimalPercentage))
+			}
+		}
+	}
+	sort.Strings(lowDisks)
+
 	result.Duration = time.Since(start).String()
+	if len(lowDisks) > 0 {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("%d disks are below %d%% free space", len(lowDisks), minimalPercentage)
+		result.Details = lowDisks
+	} else {
+		result.Status = "passed"
+		result.Message = fmt.Sprintf("All %d disks have at least %d%% free space", diskCount, minimalPercentage)
+	}
 
 	return result
 }
+
+// minimalAvailablePercentage returns Longhorn's storage-minimal-available-percentage
+// setting, below which a disk no longer takes new replicas
+func (h *HealthChecker) minimalAvailablePercentage(ctx context.Context) int64 {
+	setting, err := h.dynamicClient.Resource(longhornSettingsGVR).Namespace("longhorn-system").Get(ctx, "storage-minimal-available-percentage", metav1.GetOptions{})
+	if err != nil {
+		log.Printf("Warning: Could not read storage-minimal-available-percentage, using %d%%: %v", defaultMinimalAvailablePercentage, err)
+		return defaultMinimalAvailablePercentage
+	}
+
+	value, _, _ := unstructured.NestedString(setting.Object, "value")
+	percentage, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Printf("Warning: Invalid storage-minimal-available-percentage %q, using %d%%", value, defaultMinimalAvailablePercentage)
+		return defaultMinimalAvailablePercentage
+	}
+	return percentage
+}
+
+// bundleReadiness compares a Fleet bundle's ready and desired counts and
+// describes the first resource that is not ready
+func bundleReadiness(bundle *unstructured.Unstructured) (string, bool) {
+	desired, _, _ := unstructured.NestedInt64(bundle.Object, "status", "summary", "desiredReady")
+	ready, _, _ := unstructured.NestedInt64(bundle.Object, "status", "summary", "ready")
+	if ready >= desired {
+		return "", true
+	}
+
+	detail := fmt.Sprintf("Bundle %s/%s: %d/%d ready", bundle.GetNamespace(), bundle.GetName(), ready, desired)
+	nonReady, _, _ := unstructured.NestedSlice(bundle.Object, "status", "summary", "nonReadyResources")
+	if len(nonReady) > 0 {
+		if resource, ok := nonReady[0].(map[string]interface{}); ok {
+			state, _, _ := unstructured.NestedString(resource, "bundleState")
+			message, _, _ := unstructured.NestedString(resource, "message")
+			detail += fmt.Sprintf(" (%s", orUnknown(state))
+			if message != "" {
+				detail += ": " + message
+			}
+			detail += ")"
+		}
+	}
+	return detail, false
+}
+
+// hasRunningWorkload reports whether any pod using the volume is running
+func hasRunningWorkload(volume *unstructured.Unstructured) bool {
+	workloads, _, _ := unstructured.NestedSlice(volume.Object, "status", "kubernetesStatus", "workloadsStatus")
+	for _, w := range workloads {
+		workload, ok := w.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if status, _, _ := unstructured.NestedString(workload, "podStatus"); status == "Running" {
+			return true
+		}
+	}
+	return false
+}
+
+func formatBytes(bytes int64) string {
+	const gib = 1 << 30
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/gib)
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func object(gvr schema.GroupVersionResource, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func newTestChecker(objects ...runtime.Object) *HealthChecker {
+	listKinds := map[schema.GroupVersionResource]string{
+		fleetBundlesGVR:     "BundleList",
+		managedChartsGVR:    "ManagedChartList",
+		capiClustersGVR:     "ClusterList",
+		capiMachinesGVR:     "MachineList",
+		longhornVolumesGVR:  "VolumeList",
+		longhornNodesGVR:    "NodeList",
+		longhornSettingsGVR: "SettingList",
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	return CreateHealthChecker(nil, client, nil, DefaultConfig())
+}
+
+func TestCheckBundles_ReportsNotReadyBundles(t *testing.T) {
+	h := newTestChecker(
+		object(fleetBundlesGVR, "Bundle", "fleet-local", "ok", map[string]interface{}{
+			"status": map[string]interface{}{"summary": map[string]interface{}{"desiredReady": int64(1), "ready": int64(1)}},
+		}),
+		object(fleetBundlesGVR, "Bundle", "fleet-local", "broken", map[string]interface{}{
+			"status": map[string]interface{}{"summary": map[string]interface{}{
+				"desiredReady": int64(1),
+				"ready":        int64(0),
+				"nonReadyResources": []interface{}{
+					map[string]interface{}{"bundleState": "ErrApplied", "message": "helm failed"},
+				},
+			}},
+		}),
+	)
+
+	result := h.checkBundles(context.Background())
+	if result.Status != "failed" {
+		t.Fatalf("expected failed, got %s", result.Status)
+	}
+	if len(result.Details) != 1 || !strings.Contains(result.Details[0], "fleet-local/broken") || !strings.Contains(result.Details[0], "helm failed") {
+		t.Errorf("unexpected details %v", result.Details)
+	}
+}
+
+func TestCheckAttachedVolumes_FlagsVolumesWithoutWorkload(t *testing.T) {
+	h := newTestChecker(
+		object(longhornVolumesGVR, "Volume", "longhorn-system", "pvc-in-use", map[string]interface{}{
+			"status": map[string]interface{}{
+				"state": "attached",
+				"kubernetesStatus": map[string]interface{}{
+					"workloadsStatus": []interface{}{map[string]interface{}{"podName": "virt-launcher-a", "podStatus": "Running"}},
+				},
+			},
+		}),
+		object(longhornVolumesGVR, "Volume", "longhorn-system", "pvc-stale", map[string]interface{}{
+			"status": map[string]interface{}{"state": "attached", "currentNodeID": "node-1"},
+		}),
+		object(longhornVolumesGVR, "Volume", "longhorn-system", "pvc-detached", map[string]interface{}{
+			"status": map[string]interface{}{"state": "detached"},
+		}),
+	)
+
+	result := h.checkAttachedVolumes(context.Background())
+	if result.Status != "failed" {
+		t.Fatalf("expected failed, got %s", result.Status)
+	}
+	if len(result.Details) != 1 || !strings.Contains(result.Details[0], "pvc-stale") {
+		t.Errorf("unexpected details %v", result.Details)
+	}
+}
+
+func TestCheckFreeSpace_UsesLonghornSetting(t *testing.T) {
+	const gib = int64(1 << 30)
+	h := newTestChecker(
+		object(longhornSettingsGVR, "Setting", "longhorn-system", "storage-minimal-available-percentage", map[string]interface{}{
+			"value": "10",
+		}),
+		object(longhornNodesGVR, "Node", "longhorn-system", "node-1", map[string]interface{}{
+			"status": map[string]interface{}{"diskStatus": map[string]interface{}{
+				"default-disk": map[string]interface{}{"storageAvailable": 15 * gib, "storageMaximum": 100 * gib},
+				"extra-disk":   map[string]interface{}{"storageAvailable": 5 * gib, "storageMaximum": 100 * gib},
+			}},
+		}),
+	)
+
+	result := h.checkFreeSpace(context.Background())
+	if result.Status != "failed" {
+		t.Fatalf("expected failed, got %s", result.Status)
+	}
+	if len(result.Details) != 1 || !strings.Contains(result.Details[0], "extra-disk") {
+		t.Errorf("unexpected details %v", result.Details)
+	}
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// HealthCheck is a single cluster health check
+type HealthCheck interface {
+	Name() string
+	// Category groups related checks, e.g. "storage" or "fleet"
+	Category() string
+	// Run performs the check. It must return once ctx is done.
+	Run(ctx context.Context) models.HealthCheckResult
+}
+
+// CheckFactory builds a check for one run of the health checker, which
+// provides the clients and upgrade state the check runs against
+type CheckFactory func(h *HealthChecker) HealthCheck
+
+// Registry holds the health checks that can be run, in registration order
+type Registry struct {
+	mutex     sync.RWMutex
+	names     []string
+	factories map[string]CheckFactory
+}
+
+// DefaultRegistry holds the built-in checks and any registered with Register
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]CheckFactory)}
+}
+
+// Register adds a check under name, which is how it is enabled and disabled
+func (r *Registry) Register(name string, factory CheckFactory) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("health check %q is already registered", name)
+	}
+	r.names = append(r.names, name)
+	r.factories[name] = factory
+	return nil
+}
+
+// Names returns the registered check names in registration order
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]string{}, r.names...)
+}
+
+// build creates the checks enabled by config for one run
+func (r *Registry) build(h *HealthChecker, config Config) []HealthCheck {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var checks []HealthCheck
+	for _, name := range r.names {
+		if config.IsEnabled(name) {
+			checks = append(checks, r.factories[name](h))
+		}
+	}
+	return checks
+}
+
+// Register adds a check to DefaultRegistry. It is meant to be called from an
+// init function and panics if the name is taken.
+func Register(name string, factory CheckFactory) {
+	if err := DefaultRegistry.Register(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// NewCheck adapts a function to a HealthCheck
+func NewCheck(name, category string, run func(ctx context.Context) models.HealthCheckResult) HealthCheck {
+	return &funcCheck{name: name, category: category, run: run}
+}
+
+type funcCheck struct {
+	name     string
+	category string
+	run      func(ctx context.Context) models.HealthCheckResult
+}
+
+func (c *funcCheck) Name() string     { return c.name }
+func (c *funcCheck) Category() string { return c.category }
+
+func (c *funcCheck) Run(ctx context.Context) models.HealthCheckResult {
+	return c.run(ctx)
+}
+
+func init() {
+	builtins := []struct {
+		name     string
+		category string
+		run      func(h *HealthChecker, ctx context.Context) models.HealthCheckResult
+	}{
+		{"bundles", "fleet", (*HealthChecker).checkBundles},
+		{"harvester_bundle", "fleet", (*HealthChecker).checkHarvesterBundle},
+		{"nodes", "cluster", (*HealthChecker).checkNodes},
+		{"cluster", "cluster", (*HealthChecker).checkCluster},
+		{"machines", "cluster", (*HealthChecker).checkMachines},
+		{"volumes", "storage", (*HealthChecker).checkVolumes},
+		{"attached_volumes", "storage", (*HealthChecker).checkAttachedVolumes},
+		{"error_pods", "workloads", (*HealthChecker).checkErrorPods},
+		{"free_space", "storage", (*HealthChecker).checkFreeSpace},
+	}
+
+	for _, b := range builtins {
+		Register(b.name, func(h *HealthChecker) HealthCheck {
+			return NewCheck(b.name, b.category, func(ctx context.Context) models.HealthCheckResult {
+				return b.run(h, ctx)
+			})
+		})
+	}
+}
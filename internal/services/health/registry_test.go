@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func staticCheck(name, status string, delay time.Duration) CheckFactory {
+	return func(*HealthChecker) HealthCheck {
+		return NewCheck(name, "test", func(ctx context.Context) models.HealthCheckResult {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return models.HealthCheckResult{CheckName: name, Status: "failed", Error: ctx.Err().Error()}
+			}
+			return models.HealthCheckResult{CheckName: name, Status: status}
+		})
+	}
+}
+
+func newRegistryChecker(t *testing.T, config Config, factories map[string]CheckFactory, order []string) *HealthChecker {
+	t.Helper()
+	registry := NewRegistry()
+	for _, name := range order {
+		if err := registry.Register(name, factories[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := CreateHealthChecker(nil, nil, nil, config)
+	h.registry = registry
+	return h
+}
+
+func TestRunAllChecks_TimeoutAndOrder(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = 50 * time.Millisecond
+	h := newRegistryChecker(t, config, map[string]CheckFactory{
+		"slow":   staticCheck("slow", "passed", time.Second),
+		"fast":   staticCheck("fast", "passed", 0),
+		"broken": staticCheck("broken", "failed", 0),
+	}, []string{"slow", "fast", "broken"})
+
+	summary := h.RunAllChecks(context.Background())
+	if summary.TotalChecks != 3 {
+		t.Fatalf("expected 3 checks, got %d", summary.TotalChecks)
+	}
+	want := []struct{ name, status string }{{"slow", "timeout"}, {"fast", "passed"}, {"broken", "failed"}}
+	for i, w := range want {
+		got := summary.Results[i]
+		if got.CheckName != w.name || got.Status != w.status {
+			t.Errorf("result %d: expected %s/%s, got %s/%s", i, w.name, w.status, got.CheckName, got.Status)
+		}
+		if got.Category != "test" {
+			t.Errorf("result %d: expected category test, got %q", i, got.Category)
+		}
+	}
+	if summary.TimeoutChecks != 1 || summary.PassedChecks != 1 || summary.FailedChecks != 1 {
+		t.Errorf("unexpected counts %+v", summary)
+	}
+}
+
+func TestRunAllChecks_EnabledAndDisabled(t *testing.T) {
+	factories := map[string]CheckFactory{
+		"a": staticCheck("a", "passed", 0),
+		"b": staticCheck("b", "passed", 0),
+		"c": staticCheck("c", "passed", 0),
+	}
+	config := DefaultConfig()
+	config.Enabled = []string{"a", "b"}
+	config.Disabled = []string{"b"}
+	h := newRegistryChecker(t, config, factories, []string{"a", "b", "c"})
+
+	summary := h.RunAllChecks(context.Background())
+	if len(summary.Results) != 1 || summary.Results[0].CheckName != "a" {
+		t.Errorf("expected only check a, got %+v", summary.Results)
+	}
+}
+
+func TestRegistry_RejectsDuplicates(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("a", staticCheck("a", "passed", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("a", staticCheck("a", "passed", 0)); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+}
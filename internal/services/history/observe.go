@@ -0,0 +1,116 @@
+package history
+
+import (
+	"strconv"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// Resource kinds whose state is tracked
+const (
+	KindVM          = "vm"
+	KindVolume      = "volume"
+	KindReplica     = "replica"
+	KindNode        = "node"
+	KindUpgrade     = "upgrade"
+	KindHealthCheck = "health"
+)
+
+// Kinds lists every tracked resource kind
+var Kinds = []string{KindVM, KindVolume, KindReplica, KindNode, KindUpgrade, KindHealthCheck}
+
+// ResourceKey identifies a resource in queries, e.g. "volume/pvc-1234"
+func ResourceKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// observations collects the tracked state of a snapshot. Each state is only
+// reported once; later duplicates, e.g. a volume shared by two VMs, are dropped.
+type observations struct {
+	entries []Entry
+	seen    map[string]bool
+	sources map[string]bool
+}
+
+func (o *observations) add(kind, name, field, value string, resources ...string) {
+	e := Entry{
+		Kind:      kind,
+		Name:      name,
+		Field:     field,
+		Value:     value,
+		Resources: append([]string{ResourceKey(kind, name)}, resources...),
+	}
+	if o.seen[e.key()] {
+		return
+	}
+	o.seen[e.key()] = true
+	o.sources[e.source()] = true
+	o.entries = append(o.entries, e)
+}
+
+// observe extracts the tracked state of every resource in data
+func observe(data *models.FullClusterData) *observations {
+	o := &observations{seen: make(map[string]bool), sources: make(map[string]bool)}
+
+	for i := range data.VMs {
+		vm := &data.VMs[i]
+		vmKey := ResourceKey(KindVM, vm.Namespace+"/"+vm.Name)
+		o.add(KindVM, vm.Namespace+"/"+vm.Name, "printableStatus", vm.PrintableStatus)
+
+		for _, disk := range vm.DiskViews() {
+			if disk.VolumeName == "" {
+				continue
+			}
+			volumeKey := ResourceKey(KindVolume, disk.VolumeName)
+			o.add(KindVolume, disk.VolumeName, "robustness", disk.VolumeRobustness, vmKey)
+			o.add(KindVolume, disk.VolumeName, "state", disk.VolumeState, vmKey)
+
+			for _, replica := range disk.ReplicaInfo {
+				nodeKey := ResourceKey(KindNode, replica.NodeID)
+				o.add(KindReplica, replica.Name, "currentState", replica.CurrentState, volumeKey, nodeKey, vmKey)
+				o.add(KindReplica, replica.Name, "node", replica.NodeID, volumeKey, nodeKey, vmKey)
+			}
+		}
+	}
+
+	for i := range data.Nodes {
+		node := &data.Nodes[i]
+		name := node.NodeInfo.Name
+		for _, c := range node.NodeInfo.Conditions {
+			o.add(KindNode, name, "longhorn/"+c.Type, c.Status)
+		}
+		for _, disk := range node.NodeInfo.Disks {
+			o.add(KindNode, name, diskSchedulableField(disk.Name), strconv.FormatBool(disk.IsSchedulable))
+		}
+		if node.KubernetesNodeInfo != nil {
+			for _, c := range node.KubernetesNodeInfo.Conditions {
+				o.add(KindNode, name, "condition/"+c.Type, c.Status)
+			}
+			cordoned := "false"
+			if node.KubernetesNodeInfo.Unschedulable {
+				cordoned = "true"
+			}
+			o.add(KindNode, name, "cordoned", cordoned)
+		}
+	}
+
+	if upgrade := data.UpgradeInfo; upgrade != nil {
+		upgradeKey := ResourceKey(KindUpgrade, upgrade.Version)
+		o.add(KindUpgrade, upgrade.Version, "state", upgrade.State)
+		for nodeName, state := range upgrade.NodeStatuses {
+			o.add(KindNode, nodeName, "upgradeState", state, upgradeKey)
+		}
+	}
+
+	if data.HealthChecks != nil {
+		for _, result := range data.HealthChecks.Results {
+			o.add(KindHealthCheck, result.CheckName, "status", result.Status)
+		}
+	}
+
+	return o
+}
+
+func diskSchedulableField(disk string) string {
+	return "disk/" + disk + "/schedulable"
+}
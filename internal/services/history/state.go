@@ -0,0 +1,200 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// StateAt rebuilds the cluster as it was recorded at the given time. Only the
+// tracked fields are filled in, so engines, pods and other details are absent.
+func (s *Store) StateAt(at time.Time) (*models.FullClusterData, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.entries) == 0 {
+		return nil, fmt.Errorf("no history recorded in %s", s.path)
+	}
+	if first := s.entries[0].Time; at.Before(first) {
+		return nil, fmt.Errorf("no history before %s", first.Format(time.RFC3339))
+	}
+	// Transitions older than the retention may have been dropped. The
+	// retention of a read-only store is not known.
+	if oldest := time.Now().Add(-s.retention); s.retention > 0 && at.Before(oldest) {
+		return nil, fmt.Errorf("%s is older than the history retention of %v", at.Format(time.RFC3339), s.retention)
+	}
+
+	state := make(map[string]Entry)
+	for _, e := range s.entries {
+		if e.Time.After(at) {
+			break
+		}
+		if e.Removed {
+			delete(state, e.key())
+		} else {
+			state[e.key()] = e
+		}
+	}
+
+	entries := make([]Entry, 0, len(state))
+	for _, e := range state {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key() < entries[j].key() })
+	return rebuild(entries), nil
+}
+
+// rebuild turns the tracked state back into cluster data
+func rebuild(entries []Entry) *models.FullClusterData {
+	data := &models.FullClusterData{}
+	vms := make(map[string]*models.VMInfo)
+	nodes := make(map[string]*models.NodeWithMetrics)
+	var vmOrder, nodeOrder []string
+
+	vm := func(key string) *models.VMInfo {
+		if vms[key] == nil {
+			namespace, name, _ := strings.Cut(key, "/")
+			vms[key] = &models.VMInfo{Namespace: namespace, Name: name}
+			vmOrder = append(vmOrder, key)
+		}
+		return vms[key]
+	}
+	disk := func(vmKey, volumeName string) *models.VMDisk {
+		v := vm(vmKey)
+		for i := range v.Disks {
+			if v.Disks[i].VolumeName == volumeName {
+				return &v.Disks[i]
+			}
+		}
+		v.Disks = append(v.Disks, models.VMDisk{VolumeName: volumeName})
+		return &v.Disks[len(v.Disks)-1]
+	}
+	node := func(name string) *models.NodeWithMetrics {
+		if nodes[name] == nil {
+			nodes[name] = &models.NodeWithMetrics{NodeInfo: models.NodeInfo{Name: name}}
+			nodeOrder = append(nodeOrder, name)
+		}
+		return nodes[name]
+	}
+
+	// Replicas are attached once their volume's disk exists, whatever order
+	// the entries come in
+	replicas := make(map[string]*models.ReplicaInfo)
+	replicaOwners := make(map[string][2]string)
+
+	for _, e := range entries {
+		switch e.Kind {
+		case KindVM:
+			vm(e.Name).PrintableStatus = e.Value
+		case KindVolume:
+			vmKey, ok := related(e, KindVM)
+			if !ok {
+				continue
+			}
+			d := disk(vmKey, e.Name)
+			switch e.Field {
+			case "robustness":
+				d.VolumeRobustness = e.Value
+			case "state":
+				d.VolumeState = e.Value
+			}
+		case KindReplica:
+			vmKey, hasVM := related(e, KindVM)
+			volumeName, hasVolume := related(e, KindVolume)
+			if !hasVM || !hasVolume {
+				continue
+			}
+			if replicas[e.Name] == nil {
+				replicas[e.Name] = &models.ReplicaInfo{Name: e.Name}
+				replicaOwners[e.Name] = [2]string{vmKey, volumeName}
+			}
+			switch e.Field {
+			case "currentState":
+				replicas[e.Name].CurrentState = e.Value
+			case "node":
+				replicas[e.Name].NodeID = e.Value
+			}
+		case KindNode:
+			n := node(e.Name)
+			switch {
+			case strings.HasPrefix(e.Field, "longhorn/"):
+				n.NodeInfo.Conditions = append(n.NodeInfo.Conditions, models.NodeCondition{
+					Type: strings.TrimPrefix(e.Field, "longhorn/"), Status: e.Value,
+				})
+			case strings.HasPrefix(e.Field, "condition/"):
+				k8s := kubernetesInfo(n)
+				k8s.Conditions = append(k8s.Conditions, models.NodeCondition{
+					Type: strings.TrimPrefix(e.Field, "condition/"), Status: e.Value,
+				})
+			case e.Field == "cordoned":
+				kubernetesInfo(n).Unschedulable = e.Value == "true"
+			case strings.HasPrefix(e.Field, "disk/") && strings.HasSuffix(e.Field, "/schedulable"):
+				name := strings.TrimSuffix(strings.TrimPrefix(e.Field, "disk/"), "/schedulable")
+				n.NodeInfo.Disks = append(n.NodeInfo.Disks, models.DiskInfo{Name: name, IsSchedulable: e.Value == "true"})
+			case e.Field == "upgradeState":
+				upgrade := upgradeInfo(data)
+				if upgrade.NodeStatuses == nil {
+					upgrade.NodeStatuses = make(map[string]string)
+				}
+				upgrade.NodeStatuses[e.Name] = e.Value
+			}
+		case KindUpgrade:
+			upgrade := upgradeInfo(data)
+			upgrade.Version = e.Name
+			upgrade.State = e.Value
+		case KindHealthCheck:
+			if data.HealthChecks == nil {
+				data.HealthChecks = &models.HealthCheckSummary{}
+			}
+			data.HealthChecks.Results = append(data.HealthChecks.Results, models.HealthCheckResult{
+				CheckName: e.Name, Status: e.Value,
+			})
+		}
+	}
+
+	replicaNames := make([]string, 0, len(replicas))
+	for name := range replicas {
+		replicaNames = append(replicaNames, name)
+	}
+	sort.Strings(replicaNames)
+	for _, name := range replicaNames {
+		owner := replicaOwners[name]
+		d := disk(owner[0], owner[1])
+		d.ReplicaInfo = append(d.ReplicaInfo, *replicas[name])
+	}
+
+	for _, key := range vmOrder {
+		data.VMs = append(data.VMs, *vms[key])
+	}
+	for _, name := range nodeOrder {
+		data.Nodes = append(data.Nodes, *nodes[name])
+	}
+	return data
+}
+
+// related returns the name of the first resource of kind an entry is listed under
+func related(e Entry, kind string) (string, bool) {
+	for _, r := range e.Resources {
+		if k, name, ok := strings.Cut(r, "/"); ok && k == kind {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func kubernetesInfo(n *models.NodeWithMetrics) *models.KubernetesNodeInfo {
+	if n.KubernetesNodeInfo == nil {
+		n.KubernetesNodeInfo = &models.KubernetesNodeInfo{Name: n.NodeInfo.Name}
+	}
+	return n.KubernetesNodeInfo
+}
+
+func upgradeInfo(data *models.FullClusterData) *models.UpgradeInfo {
+	if data.UpgradeInfo == nil {
+		data.UpgradeInfo = &models.UpgradeInfo{}
+	}
+	return data.UpgradeInfo
+}
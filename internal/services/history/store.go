@@ -0,0 +1,374 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+const (
+	// DefaultRetention is how long transitions are kept
+	DefaultRetention = 7 * 24 * time.Hour
+	// DefaultMaxEntries bounds the store so a flapping resource cannot grow
+	// it without limit
+	DefaultMaxEntries = 200000
+)
+
+// Entry is one observed state transition of a cluster resource
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Field    string    `json:"field"`
+	Value    string    `json:"value"`
+	Previous string    `json:"previous,omitempty"`
+	// Removed marks the resource, or this field of it, disappearing
+	Removed bool `json:"removed,omitempty"`
+	// Resources are the keys the entry is listed under, e.g. a replica is
+	// part of the timeline of its volume, node and VM
+	Resources []string `json:"resources"`
+}
+
+func (e Entry) key() string {
+	return e.Kind + "/" + e.Name + "/" + e.Field
+}
+
+// source names the part of a snapshot the entry is read from. The Kubernetes
+// nodes and the upgrade are fetched apart from the Longhorn nodes, so node
+// fields taken from them can be missing on their own.
+func (e Entry) source() string {
+	if e.Kind != KindNode {
+		return e.Kind
+	}
+	switch {
+	case strings.HasPrefix(e.Field, "condition/"), e.Field == "cordoned":
+		return "kubernetes-node"
+	case e.Field == "upgradeState":
+		return KindUpgrade
+	}
+	return e.Kind
+}
+
+// Store records state transitions of cluster resources in an append-only
+// JSONL file. Entries are also kept in memory for queries; the file is
+// rewritten without expired entries as it grows.
+type Store struct {
+	mutex      sync.RWMutex
+	path       string
+	file       *os.File
+	retention  time.Duration
+	maxEntries int
+	entries    []Entry
+	// current is the latest entry of every tracked field that still exists
+	current map[string]Entry
+	// appended counts entries written since the file was last compacted
+	appended int
+	// readOnly stores are loaded for queries while another process records
+	readOnly bool
+}
+
+// OpenStore opens or creates the store at path and replays its entries
+func OpenStore(path string, retention time.Duration, maxEntries int) (*Store, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	s := &Store{
+		path:       path,
+		retention:  retention,
+		maxEntries: maxEntries,
+		current:    make(map[string]Entry),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadStore loads the store at path for queries only. The file is neither
+// written nor compacted, so it is safe to read while a server records to it.
+func ReadStore(path string) (*Store, error) {
+	s := &Store{
+		path:     path,
+		current:  make(map[string]Entry),
+		readOnly: true,
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replays the file into memory, skipping lines it cannot parse
+func (s *Store) load() error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	skipped := 0
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			skipped++
+			continue
+		}
+		s.apply(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+	if skipped > 0 {
+		log.Printf("Warning: Skipped %d unreadable history entries in %s", skipped, s.path)
+	}
+	return nil
+}
+
+// apply adds an entry to memory
+func (s *Store) apply(e Entry) {
+	s.entries = append(s.entries, e)
+	if e.Removed {
+		delete(s.current, e.key())
+	} else {
+		s.current[e.key()] = e
+	}
+}
+
+// Record compares a snapshot with the last known state and stores every
+// field that changed, appeared or disappeared. It returns the number of
+// transitions recorded.
+func (s *Store) Record(data *models.FullClusterData, at time.Time) (int, error) {
+	if s.readOnly {
+		return 0, fmt.Errorf("history store %s is read-only", s.path)
+	}
+	observed := observe(data)
+	at = at.UTC()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var changes []Entry
+	for _, e := range observed.entries {
+		last, known := s.current[e.key()]
+		if known && last.Value == e.Value {
+			continue
+		}
+		e.Time = at
+		if known {
+			e.Previous = last.Value
+		}
+		changes = append(changes, e)
+	}
+
+	for key, last := range s.current {
+		// A source missing from the whole snapshot was most likely not
+		// fetched, rather than deleted
+		if observed.seen[key] || !observed.sources[last.source()] {
+			continue
+		}
+		changes = append(changes, Entry{
+			Time:      at,
+			Kind:      last.Kind,
+			Name:      last.Name,
+			Field:     last.Field,
+			Previous:  last.Value,
+			Removed:   true,
+			Resources: last.Resources,
+		})
+	}
+
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	if err := s.append(changes); err != nil {
+		return 0, err
+	}
+
+	if s.appended > s.maxEntries/4 || len(s.entries) > s.maxEntries {
+		if err := s.compact(at); err != nil {
+			log.Printf("Warning: Could not compact history: %v", err)
+		}
+	}
+	return len(changes), nil
+}
+
+// append writes entries to the file and memory
+func (s *Store) append(entries []Entry) error {
+	if s.file == nil {
+		file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open history file: %w", err)
+		}
+		s.file = file
+	}
+
+	writer := bufio.NewWriter(s.file)
+	encoder := json.NewEncoder(writer)
+	for _, e := range entries {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	for _, e := range entries {
+		s.apply(e)
+	}
+	s.appended += len(entries)
+	return nil
+}
+
+// compact drops entries older than the retention, and the oldest entries
+// beyond maxEntries, then rewrites the file. The latest entry of each field
+// that still exists is always kept so long-standing states keep their start.
+func (s *Store) compact(now time.Time) error {
+	cutoff := now.Add(-s.retention)
+	overflow := len(s.entries) - s.maxEntries
+
+	kept := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		isCurrent := !e.Removed && s.current[e.key()].Time.Equal(e.Time)
+		if !isCurrent && (e.Time.Before(cutoff) || overflow > 0) {
+			overflow--
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(kept) == len(s.entries) && s.appended == 0 {
+		return nil
+	}
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create history file: %w", err)
+	}
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, e := range kept {
+		if err := encoder.Encode(e); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close history file: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace history file: %w", err)
+	}
+
+	s.entries = kept
+	s.appended = 0
+	return nil
+}
+
+// Query returns the transitions of a resource, e.g. "volume/pvc-1234", at or
+// after since, oldest first
+func (s *Store) Query(resource string, since time.Time) []Entry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []Entry{}
+	for _, e := range s.entries {
+		if e.Time.Before(since) {
+			continue
+		}
+		for _, r := range e.Resources {
+			if r == resource {
+				result = append(result, e)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Current returns the latest entry of every field of a resource that still
+// exists, including ones that last changed before the retention window
+func (s *Store) Current(resource string) []Entry {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []Entry{}
+	for _, e := range s.current {
+		if ResourceKey(e.Kind, e.Name) == resource {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Close closes the history file
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Run records a snapshot from fetch every interval until ctx is cancelled
+func (s *Store) Run(ctx context.Context, interval time.Duration, fetch func() (models.FullClusterData, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, err := fetch()
+		if err != nil {
+			log.Printf("Warning: Could not fetch cluster data for history: %v", err)
+		} else if n, err := s.Record(&data, time.Now()); err != nil {
+			log.Printf("Warning: Could not record history: %v", err)
+		} else if n > 0 {
+			log.Printf("Recorded %d state transitions in history", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func snapshot(robustness string, replicaNode string) *models.FullClusterData {
+	return &models.FullClusterData{
+		VMs: []models.VMInfo{{
+			Name:            "vm1",
+			Namespace:       "default",
+			PrintableStatus: "Running",
+			Disks: []models.VMDisk{{
+				VolumeName:       "pvc-1",
+				VolumeRobustness: robustness,
+				VolumeState:      "attached",
+				ReplicaInfo: []models.ReplicaInfo{
+					{Name: "pvc-1-r-a", NodeID: replicaNode, CurrentState: "running"},
+				},
+			}},
+		}},
+	}
+}
+
+func TestStore_RecordsOnlyTransitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store, err := OpenStore(path, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+
+	if n, err := store.Record(snapshot("healthy", "node-1"), start); err != nil || n != 5 {
+		t.Fatalf("expected 5 initial entries, got %d (%v)", n, err)
+	}
+	if n, _ := store.Record(snapshot("healthy", "node-1"), start.Add(time.Minute)); n != 0 {
+		t.Errorf("expected no entries for an unchanged snapshot, got %d", n)
+	}
+	if n, _ := store.Record(snapshot("degraded", "node-2"), start.Add(2*time.Minute)); n != 2 {
+		t.Errorf("expected 2 transitions, got %d", n)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening replays the file
+	store, err = OpenStore(path, time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	entries := store.Query(ResourceKey(KindVolume, "pvc-1"), start.Add(time.Second))
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 volume entries, got %+v", entries)
+	}
+	for _, e := range entries {
+		switch e.Kind + "/" + e.Field {
+		case "volume/robustness":
+			if e.Previous != "healthy" || e.Value != "degraded" {
+				t.Errorf("unexpected robustness transition %+v", e)
+			}
+		case "replica/node":
+			if e.Previous != "node-1" || e.Value != "node-2" {
+				t.Errorf("unexpected replica transition %+v", e)
+			}
+		default:
+			t.Errorf("unexpected entry %+v", e)
+		}
+	}
+	if got := store.Query(ResourceKey(KindNode, "node-2"), start); len(got) != 1 {
+		t.Errorf("expected the moved replica under node-2, got %+v", got)
+	}
+}
+
+func TestStore_RecordsRemovalOnlyForObservedKinds(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.jsonl"), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	start := time.Now()
+
+	data := snapshot("healthy", "node-1")
+	data.HealthChecks = &models.HealthCheckSummary{Results: []models.HealthCheckResult{{CheckName: "nodes", Status: "passed"}}}
+	if _, err := store.Record(data, start); err != nil {
+		t.Fatal(err)
+	}
+
+	// Health checks were skipped in this snapshot; the VM was deleted
+	if _, err := store.Record(&models.FullClusterData{VMs: []models.VMInfo{{Name: "other", Namespace: "default"}}}, start.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	vm := store.Query(ResourceKey(KindVM, "default/vm1"), start.Add(time.Second))
+	if len(vm) != 1 || !vm[0].Removed || vm[0].Previous != "Running" {
+		t.Errorf("expected vm removal, got %+v", vm)
+	}
+	if got := store.Current(ResourceKey(KindHealthCheck, "nodes")); len(got) != 1 {
+		t.Errorf("expected health check state to survive an unchecked snapshot, got %+v", got)
+	}
+}
+
+func TestStore_KeepsKubernetesNodeFieldsWhenOnlyLonghornNodesLoad(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.jsonl"), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	start := time.Now()
+
+	node := models.NodeWithMetrics{
+		NodeInfo: models.NodeInfo{Name: "node-1", Conditions: []models.NodeCondition{{Type: "Ready", Status: "True"}}},
+		KubernetesNodeInfo: &models.KubernetesNodeInfo{
+			Conditions: []models.NodeCondition{{Type: "Ready", Status: "True"}},
+		},
+	}
+	if _, err := store.Record(&models.FullClusterData{Nodes: []models.NodeWithMetrics{node}}, start); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Kubernetes node list failed; the Longhorn node still loaded
+	node.KubernetesNodeInfo = nil
+	if n, err := store.Record(&models.FullClusterData{Nodes: []models.NodeWithMetrics{node}}, start.Add(time.Minute)); err != nil || n != 0 {
+		t.Fatalf("expected no entries without the Kubernetes nodes, got %d (%v)", n, err)
+	}
+	if got := store.Current(ResourceKey(KindNode, "node-1")); len(got) != 3 {
+		t.Errorf("expected the longhorn condition, kubernetes condition and cordoned state, got %+v", got)
+	}
+}
+
+func TestStore_StateAt(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.jsonl"), time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	start := time.Now()
+
+	if _, err := store.Record(snapshot("healthy", "node-1"), start.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Record(snapshot("degraded", "node-2"), start); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := store.StateAt(start.Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.VMs) != 1 || len(data.VMs[0].Disks) != 1 {
+		t.Fatalf("expected one VM with one disk, got %+v", data.VMs)
+	}
+	disk := data.VMs[0].Disks[0]
+	if disk.VolumeRobustness != "healthy" || len(disk.ReplicaInfo) != 1 || disk.ReplicaInfo[0].NodeID != "node-1" {
+		t.Errorf("unexpected disk state %+v", disk)
+	}
+
+	if _, err := store.StateAt(start.Add(-time.Hour)); err == nil {
+		t.Error("expected an error before the first entry")
+	}
+}
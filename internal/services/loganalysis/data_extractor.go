@@ -1,48 +1,151 @@
 package loganalysis
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+
 	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/diagnostics"
 )
 
-// ExtractTroubleshootingData extracts comprehensive data from VM and node info
-// This is what the AI agent uses for multi-layered analysis
-func ExtractTroubleshootingData(
-	vmInfo *types.VMInfo,
-	allNodes []types.NodeWithMetrics,
-) (
-	nodeDiskStatus []types.NodeDiskInfo,
-	replicaDetails []types.ReplicaDetail,
-	podDistribution []types.PodLocation,
-	attachmentState *types.AttachmentState,
-	migrationState *types.MigrationState,
-) {
+// ErrIssueNotFound is returned by EnrichRequest for an issue ID that is not
+// among the cluster's current issues
+var ErrIssueNotFound = errors.New("issue not found")
+
+// EnrichRequest fills a request from cluster data, so a client only needs to
+// send the issue ID. The issue gives the type, VM and node; the VM disk the
+// issue is about gives the volume and every troubleshooting layer, replacing
+// whatever the client sent. Without an issue ID the VM is looked up by
+// VMName and Namespace. csiAttachments are the storage.k8s.io
+// VolumeAttachment objects, used to detect attachment conflicts.
+func EnrichRequest(req *types.LogAnalysisRequest, data *types.FullClusterData, csiAttachments []map[string]interface{}) error {
+	volumeHint := req.VolumeName
+	if req.IssueID != "" {
+		issue := findIssue(data.Issues, req.IssueID)
+		if issue == nil {
+			return fmt.Errorf("%w: %s", ErrIssueNotFound, req.IssueID)
+		}
+		req.IssueType = issue.ResourceType
+		req.VMName = issue.VMName
+		req.Namespace = issue.VMNamespace
+		if issue.NodeName != "" {
+			req.NodeName = issue.NodeName
+		}
+		volumeHint = issue.ResourceName
+		if issue.AttachmentDetails != nil && issue.AttachmentDetails.VolumeName != "" {
+			volumeHint = issue.AttachmentDetails.VolumeName
+		}
+	}
 
-	// Extract node disk status
-	nodeDiskStatus = extractNodeDiskStatus(allNodes)
+	vm := findVM(data.VMs, req.VMName, req.Namespace)
+	if vm == nil {
+		// Node and pod issues have no VM; their node's disks still matter
+		if req.NodeName != "" {
+			req.NodeDiskStatus = extractNodeDiskStatus(data.Nodes, map[string]bool{req.NodeName: true})
+		}
+		return nil
+	}
 
-	// Extract replica details
-	if vmInfo != nil {
-		replicaDetails = extractReplicaDetails(vmInfo.ReplicaInfo)
-		podDistribution = extractPodDistribution(vmInfo)
+	disk := findDisk(vm, volumeHint)
+	if disk != nil {
+		req.VolumeName = disk.VolumeName
+		req.VolumeRobustness = disk.VolumeRobustness
+		req.VolumeState = disk.VolumeState
+		req.ReplicaCount = len(disk.ReplicaInfo)
+		req.FaultedCount = 0
+		for _, replica := range disk.ReplicaInfo {
+			if replica.CurrentState == "error" || !replica.Started {
+				req.FaultedCount++
+			}
+		}
+		req.ReplicaDetails = extractReplicaDetails(disk.ReplicaInfo)
+		req.EngineDetails = extractEngineDetails(disk.EngineInfo)
+		req.AttachmentState = extractAttachmentState(vm, disk, csiAttachments)
+	}
+	req.PodDistribution = extractPodDistribution(vm)
+	req.MigrationState = extractMigrationState(vm, disk)
+	if migration := latestMigration(vm); migration != nil {
+		req.SourceNode = migration.SourceNode
+		req.TargetNode = migration.TargetNode
+	}
+	req.NodeDiskStatus = extractNodeDiskStatus(data.Nodes, relatedNodes(req))
+	return nil
+}
+
+func findIssue(issues []types.Issue, id string) *types.Issue {
+	for i := range issues {
+		if issues[i].ID == id {
+			return &issues[i]
+		}
 	}
+	return nil
+}
 
-	// Extract attachment state (if volume info available)
-	if vmInfo != nil && vmInfo.VolumeName != "" {
-		attachmentState = extractAttachmentState(vmInfo)
+func findVM(vms []types.VMInfo, name, namespace string) *types.VMInfo {
+	if name == "" {
+		return nil
+	}
+	for i := range vms {
+		if vms[i].Name == name && (namespace == "" || vms[i].Namespace == namespace) {
+			return &vms[i]
+		}
 	}
+	return nil
+}
 
-	// Extract migration state (if available in VMI info)
-	if vmInfo != nil && len(vmInfo.VMIInfo) > 0 {
-		migrationState = extractMigrationState(vmInfo)
+// findDisk returns the disk backed by the named volume or claim, or else the
+// VM's first disk with a volume
+func findDisk(vm *types.VMInfo, name string) *types.VMDisk {
+	var first *types.VMDisk
+	disks := vm.DiskViews()
+	for i := range disks {
+		disk := &disks[i]
+		if name != "" && (disk.VolumeName == name || disk.ClaimName == name || disk.PVName == name) {
+			return disk
+		}
+		if first == nil && disk.VolumeName != "" {
+			first = disk
+		}
 	}
+	return first
+}
 
-	return
+// relatedNodes returns the nodes a request's VM and volume run on
+func relatedNodes(req *types.LogAnalysisRequest) map[string]bool {
+	nodes := make(map[string]bool)
+	add := func(name string) {
+		if name != "" {
+			nodes[name] = true
+		}
+	}
+	add(req.NodeName)
+	add(req.SourceNode)
+	add(req.TargetNode)
+	for _, replica := range req.ReplicaDetails {
+		add(replica.NodeName)
+	}
+	for _, engine := range req.EngineDetails {
+		add(engine.NodeName)
+	}
+	for _, pod := range req.PodDistribution {
+		add(pod.NodeName)
+	}
+	if req.AttachmentState != nil {
+		add(req.AttachmentState.CurrentNodeID)
+	}
+	return nodes
 }
 
-func extractNodeDiskStatus(nodes []types.NodeWithMetrics) []types.NodeDiskInfo {
+// extractNodeDiskStatus returns the disks of the given nodes, or of every
+// node when none are given
+func extractNodeDiskStatus(nodes []types.NodeWithMetrics, only map[string]bool) []types.NodeDiskInfo {
 	var diskStatus []types.NodeDiskInfo
 
 	for _, node := range nodes {
+		if len(only) > 0 && !only[node.NodeInfo.Name] {
+			continue
+		}
 		// NodeInfo is embedded, so fields are directly accessible
 		for _, disk := range node.Disks {
 			diskInfo := types.NodeDiskInfo{
@@ -65,13 +168,14 @@ func extractReplicaDetails(replicaInfo []types.ReplicaInfo) []types.ReplicaDetai
 
 	for _, replica := range replicaInfo {
 		detail := types.ReplicaDetail{
-			Name:     replica.Name,
-			NodeName: replica.NodeID,
-			State:    replica.CurrentState,
-			Mode:     "", // Not available in ReplicaInfo
-			FailedAt: "", // Not directly available
-			Started:  replica.Started,
-			DiskPath: replica.DiskID, // Use DiskID as path info
+			Name:            replica.Name,
+			NodeName:        replica.NodeID,
+			State:           replica.CurrentState,
+			Mode:            "", // Not available in ReplicaInfo
+			FailedAt:        "", // Not directly available
+			Started:         replica.Started,
+			DiskPath:        replica.DiskPath,
+			InstanceManager: replica.InstanceManager,
 		}
 		details = append(details, detail)
 	}
@@ -79,60 +183,143 @@ func extractReplicaDetails(replicaInfo []types.ReplicaInfo) []types.ReplicaDetai
 	return details
 }
 
+func extractEngineDetails(engineInfo []types.EngineInfo) []types.EngineDetail {
+	var details []types.EngineDetail
+	for _, engine := range engineInfo {
+		details = append(details, types.EngineDetail{
+			Name:     engine.Name,
+			NodeName: engine.NodeID,
+			State:    engine.CurrentState,
+			Active:   engine.Active,
+		})
+	}
+	return details
+}
+
+// extractPodDistribution returns every virt-launcher pod of the VM; more
+// than one outside a migration points at split-brain
 func extractPodDistribution(vmInfo *types.VMInfo) []types.PodLocation {
-	var pods []types.PodLocation
+	if len(vmInfo.VMIInfo) == 0 {
+		return nil
+	}
+	vmi := vmInfo.VMIInfo[0]
 
-	// Get node from VMIInfo
-	var nodeName string
-	var phase string
-	if len(vmInfo.VMIInfo) > 0 {
-		nodeName = vmInfo.VMIInfo[0].NodeName
-		phase = vmInfo.VMIInfo[0].Phase
+	var pods []types.PodLocation
+	for uid, podName := range vmi.ActivePodNames {
+		nodeName := vmi.ActivePods[uid]
+		phase := ""
+		for _, pod := range vmInfo.PodInfo {
+			if pod.Name == podName {
+				phase = pod.Status
+				break
+			}
+		}
+		pods = append(pods, types.PodLocation{PodName: podName, NodeName: nodeName, Phase: phase})
 	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].PodName < pods[j].PodName })
 
-	// Add VMI pod
-	if vmInfo.PodName != "" && nodeName != "" {
+	// Older VMIs may not list their active pods
+	if len(pods) == 0 && vmInfo.PodName != "" && vmi.NodeName != "" {
 		pods = append(pods, types.PodLocation{
 			PodName:  vmInfo.PodName,
-			NodeName: nodeName,
-			Phase:    phase,
+			NodeName: vmi.NodeName,
+			Phase:    vmi.Phase,
 		})
 	}
 
 	return pods
 }
 
-func extractAttachmentState(vmInfo *types.VMInfo) *types.AttachmentState {
-	// Get current node from VMIInfo
-	var currentNode string
+// extractAttachmentState compares where Longhorn has the volume attached
+// with where its attachment tickets and CSI VolumeAttachments want it. A CSI
+// attachment, or a ticket while the volume is attached, on any node other
+// than Longhorn's current node or migration target is a conflict.
+func extractAttachmentState(vmInfo *types.VMInfo, disk *types.VMDisk, csiAttachments []map[string]interface{}) *types.AttachmentState {
+	state := &types.AttachmentState{
+		CurrentNodeID:    disk.VolumeNodeID,
+		LonghornAttached: disk.VolumeState == "attached",
+	}
 	if len(vmInfo.VMIInfo) > 0 {
-		currentNode = vmInfo.VMIInfo[0].NodeName
+		state.DesiredNodeID = vmInfo.VMIInfo[0].NodeName
 	}
 
-	state := &types.AttachmentState{
-		CurrentNodeID:    currentNode,
-		LonghornAttached: vmInfo.VolumeState == "attached",
-		HasConflict:      false, // TODO: Detect CSI conflicts from attachment tickets
+	expected := map[string]bool{disk.VolumeNodeID: true, disk.VolumeMigrationNodeID: true}
+	conflict := func(node string) {
+		if node != "" && !expected[node] {
+			state.HasConflict = true
+		}
 	}
 
+	// Tickets for another node are expected while a detached volume attaches
+	if disk.VolumeNodeID != "" {
+		tickets := diagnostics.MergeAttachmentTickets(disk.AttachmentTicketsStatusRaw, disk.AttachmentTicketsSpecRaw)
+		for _, ticket := range tickets {
+			node, _ := ticket["nodeID"].(string)
+			conflict(node)
+		}
+	}
+
+	pvName := disk.PVName
+	if pvName == "" {
+		pvName = disk.VolumeName
+	}
+	for _, attachment := range csiAttachments {
+		spec, _ := attachment["spec"].(map[string]interface{})
+		source, _ := spec["source"].(map[string]interface{})
+		if pv, _ := source["persistentVolumeName"].(string); pv == "" || pv != pvName {
+			continue
+		}
+		metadata, _ := attachment["metadata"].(map[string]interface{})
+		status, _ := attachment["status"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		node, _ := spec["nodeName"].(string)
+		attached, _ := status["attached"].(bool)
+		state.CSIAttachments = append(state.CSIAttachments, fmt.Sprintf("%s on %s (attached=%t)", name, node, attached))
+		if attached {
+			conflict(node)
+		}
+	}
+	sort.Strings(state.CSIAttachments)
+
 	return state
 }
 
-func extractMigrationState(vmInfo *types.VMInfo) *types.MigrationState {
-	// Check if there's migration info in VMIInfo
-	var hasMigration bool
-	if len(vmInfo.VMIInfo) > 0 && vmInfo.VMIInfo[0].MigrationInfo != nil {
-		hasMigration = true
+// extractMigrationState reports the volume's migration target. It is
+// dangling when no VM migration is still running to clear it.
+func extractMigrationState(vmInfo *types.VMInfo, disk *types.VMDisk) *types.MigrationState {
+	latest := latestMigration(vmInfo)
+	if (disk == nil || disk.VolumeMigrationNodeID == "") && latest == nil {
+		return nil
 	}
 
-	state := &types.MigrationState{
-		IsDangling: false, // TODO: Detect dangling migration from volume backend
+	state := &types.MigrationState{}
+	if latest != nil {
+		state.LastMigrationAttempt = fmt.Sprintf("%s (%s)", latest.Name, latest.Phase)
 	}
-
-	if hasMigration {
-		// Migration is active or recent
-		state.IsDangling = false
+	if disk == nil || disk.VolumeMigrationNodeID == "" {
+		return state
 	}
+	state.CurrentMigrationNodeID = disk.VolumeMigrationNodeID
 
+	state.IsDangling = true
+	for _, migration := range vmInfo.VMIMInfo {
+		if migration.Phase != "Succeeded" && migration.Phase != "Failed" {
+			state.IsDangling = false
+			state.MigrationStarted = migration.StartTimestamp
+		}
+	}
 	return state
 }
+
+// latestMigration returns the VM's most recently started migration
+func latestMigration(vmInfo *types.VMInfo) *types.VMIMInfo {
+	var latest *types.VMIMInfo
+	for i := range vmInfo.VMIMInfo {
+		migration := &vmInfo.VMIMInfo[i]
+		// RFC 3339 timestamps sort as strings
+		if latest == nil || migration.StartTimestamp > latest.StartTimestamp {
+			latest = migration
+		}
+	}
+	return latest
+}
@@ -0,0 +1,110 @@
+package loganalysis
+
+import (
+	"errors"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func TestEnrichRequest(t *testing.T) {
+	data := &types.FullClusterData{
+		Issues: []types.Issue{{ID: "replica-faulted-vm1", ResourceType: "replica-faulted", ResourceName: volumeA, VMName: "vm1", VMNamespace: "default"}},
+		VMs: []types.VMInfo{{
+			Name:      "vm1",
+			Namespace: "default",
+			Disks: []types.VMDisk{
+				{ClaimName: "vm1-root", VolumeName: "pvc-root"},
+				{
+					ClaimName:             "vm1-data",
+					VolumeName:            volumeA,
+					PVName:                volumeA,
+					VolumeState:           "attached",
+					VolumeRobustness:      "degraded",
+					VolumeNodeID:          "node-a",
+					VolumeMigrationNodeID: "node-b",
+					ReplicaInfo: []types.ReplicaInfo{
+						{Name: volumeA + "-r-1", NodeID: "node-a", CurrentState: "running", Started: true},
+						{Name: volumeA + "-r-2", NodeID: "node-c", CurrentState: "error"},
+					},
+					AttachmentTicketsSpecRaw: map[string]interface{}{
+						"csi-aaa": map[string]interface{}{"nodeID": "node-a", "type": "csi-attacher"},
+					},
+				},
+			},
+			VMIInfo:  []types.VMIInfo{{NodeName: "node-a", ActivePods: map[string]string{"uid": "node-a"}, ActivePodNames: map[string]string{"uid": "virt-launcher-vm1-x"}}},
+			VMIMInfo: []types.VMIMInfo{{Name: "vm1-migration", Phase: "Failed", SourceNode: "node-a", TargetNode: "node-b", StartTimestamp: "2024-03-02T12:00:00Z"}},
+		}},
+		Nodes: []types.NodeWithMetrics{
+			{NodeInfo: types.NodeInfo{Name: "node-a", Disks: []types.DiskInfo{{Path: "/var/lib/harvester/defaultdisk", IsSchedulable: true}}}},
+			{NodeInfo: types.NodeInfo{Name: "node-d", Disks: []types.DiskInfo{{Path: "/var/lib/harvester/defaultdisk"}}}},
+		},
+	}
+	csiAttachments := []map[string]interface{}{{
+		"metadata": map[string]interface{}{"name": "csi-bbb"},
+		"spec": map[string]interface{}{
+			"nodeName": "node-c",
+			"source":   map[string]interface{}{"persistentVolumeName": volumeA},
+		},
+		"status": map[string]interface{}{"attached": true},
+	}}
+
+	req := types.LogAnalysisRequest{IssueID: "replica-faulted-vm1", Provider: "stub"}
+	if err := EnrichRequest(&req, data, csiAttachments); err != nil {
+		t.Fatalf("EnrichRequest: %v", err)
+	}
+	if req.IssueType != "replica-faulted" || req.VolumeName != volumeA || req.ReplicaCount != 2 || req.FaultedCount != 1 {
+		t.Errorf("request = %+v, want the issue's data volume with 1 of 2 replicas faulted", req)
+	}
+	if req.SourceNode != "node-a" || req.TargetNode != "node-b" || len(req.PodDistribution) != 1 {
+		t.Errorf("source=%s target=%s pods=%v", req.SourceNode, req.TargetNode, req.PodDistribution)
+	}
+	if len(req.NodeDiskStatus) != 1 || req.NodeDiskStatus[0].NodeName != "node-a" {
+		t.Errorf("node disks = %+v, want only the disks of nodes the VM uses", req.NodeDiskStatus)
+	}
+
+	attachment := req.AttachmentState
+	if attachment == nil || !attachment.HasConflict || len(attachment.CSIAttachments) != 1 || attachment.CurrentNodeID != "node-a" {
+		t.Errorf("attachment = %+v, want a conflict with the CSI attachment on node-c", attachment)
+	}
+	migration := req.MigrationState
+	if migration == nil || !migration.IsDangling || migration.CurrentMigrationNodeID != "node-b" {
+		t.Errorf("migration = %+v, want node-b dangling after the failed migration", migration)
+	}
+
+	// The same attachment on the migration target is expected
+	csiAttachments[0]["spec"].(map[string]interface{})["nodeName"] = "node-b"
+	data.VMs[0].VMIMInfo[0].Phase = "Running"
+	req = types.LogAnalysisRequest{IssueID: "replica-faulted-vm1"}
+	if err := EnrichRequest(&req, data, csiAttachments); err != nil {
+		t.Fatalf("EnrichRequest: %v", err)
+	}
+	if req.AttachmentState.HasConflict || req.MigrationState.IsDangling {
+		t.Errorf("attachment = %+v, migration = %+v, want neither flagged during a running migration",
+			req.AttachmentState, req.MigrationState)
+	}
+
+	req = types.LogAnalysisRequest{IssueID: "gone"}
+	if err := EnrichRequest(&req, data, nil); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("EnrichRequest of an unknown issue = %v, want ErrIssueNotFound", err)
+	}
+}
+
+func TestExtractPodDistribution_SameNode(t *testing.T) {
+	vm := &types.VMInfo{
+		VMIInfo: []types.VMIInfo{{
+			ActivePods:     map[string]string{"uid-1": "node-a", "uid-2": "node-a"},
+			ActivePodNames: map[string]string{"uid-1": "virt-launcher-vm1-old", "uid-2": "virt-launcher-vm1-new"},
+		}},
+		PodInfo: []types.PodInfo{
+			{Name: "virt-launcher-vm1-old", NodeID: "node-a", Status: "Failed"},
+			{Name: "virt-launcher-vm1-new", NodeID: "node-a", Status: "Running"},
+		},
+	}
+
+	pods := extractPodDistribution(vm)
+	if len(pods) != 2 || pods[0].PodName != "virt-launcher-vm1-new" || pods[0].Phase != "Running" ||
+		pods[1].PodName != "virt-launcher-vm1-old" || pods[1].Phase != "Failed" {
+		t.Errorf("pods = %+v, want each launcher pod with its own phase", pods)
+	}
+}
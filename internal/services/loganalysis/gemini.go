@@ -2,7 +2,6 @@ package loganalysis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/google/generative-ai-go/genai"
@@ -13,29 +12,40 @@ import (
 type GeminiAnalyzer struct {
 	client *genai.Client
 	model  *genai.GenerativeModel
+	llmOptions
 }
 
-func NewGeminiAnalyzer(ctx context.Context, apiKey string) (*GeminiAnalyzer, error) {
+// geminiCost is the gemini-2.5-flash price list
+var geminiCost = CostTable{InputPerMillion: 0.075, OutputPerMillion: 0.30}
+
+func NewGeminiAnalyzer(ctx context.Context, apiKey, model string) (*GeminiAnalyzer, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("gemini API key is required")
 	}
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
 
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gemini client: %w", err)
 	}
 
-	model := client.GenerativeModel("gemini-2.5-flash")
-	model.ResponseMIMEType = "application/json"
+	generativeModel := client.GenerativeModel(model)
+	generativeModel.ResponseMIMEType = "application/json"
 
 	return &GeminiAnalyzer{
-		client: client,
-		model:  model,
+		client:     client,
+		model:      generativeModel,
+		llmOptions: llmOptions{cost: geminiCost, retries: DefaultRetries},
 	}, nil
 }
 
 func (g *GeminiAnalyzer) Analyze(ctx context.Context, prompt string) (*types.LogAnalysisResult, error) {
+	return analyzeWithRetries(ctx, "gemini", g.complete, prompt, &g.llmOptions)
+}
 
+func (g *GeminiAnalyzer) complete(ctx context.Context, prompt string) (*Completion, error) {
 	fullPrompt := prompt + `
 Return JSON with these exact fields:
 {
@@ -59,21 +69,13 @@ Return JSON with these exact fields:
 		return nil, fmt.Errorf("unexpected response format from Gemini")
 	}
 
-	var result types.LogAnalysisResult
-	if err := json.Unmarshal([]byte(textPart), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse Gemini response: %w", err)
-	}
-
-	result.Provider = "gemini"
+	completion := &Completion{Text: string(textPart)}
 	if resp.UsageMetadata != nil {
-		result.TokensUsed = int(resp.UsageMetadata.TotalTokenCount)
-
-		inputCost := float64(resp.UsageMetadata.PromptTokenCount) * 0.075 / 1000000
-		outputCost := float64(resp.UsageMetadata.CandidatesTokenCount) * 0.30 / 1000000
-
-		result.EstimatedCost = inputCost + outputCost
+		completion.PromptTokens = int(resp.UsageMetadata.PromptTokenCount)
+		completion.CompletionTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+		completion.TotalTokens = int(resp.UsageMetadata.TotalTokenCount)
 	}
-	return &result, nil
+	return completion, nil
 }
 
 func (g *GeminiAnalyzer) Name() string {
@@ -88,6 +90,13 @@ func (g *GeminiAnalyzer) Close() error {
 }
 
 func (g *GeminiAnalyzer) EstimatedCost() float64 {
-	// Average of input ($0.075) and output ($0.30) per 1M tokens
-	return 0.0002 // Approximately $0.0002 per 1000 tokens
+	return g.cost.PerThousand()
+}
+
+// Ping checks the API key by looking up the model
+func (g *GeminiAnalyzer) Ping(ctx context.Context) error {
+	if _, err := g.model.Info(ctx); err != nil {
+		return fmt.Errorf("gemini model lookup failed: %w", err)
+	}
+	return nil
 }
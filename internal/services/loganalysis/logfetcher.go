@@ -4,18 +4,26 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 	"strings"
+	"time"
 
 	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-func FetchPodLogs(ctx context.Context, clientset *kubernetes.Clientset, namespace, podName, containerName string, tailLines int64) (string, error) {
+// FetchPodLogs returns the relevant lines of a container log from the API server
+func FetchPodLogs(ctx context.Context, clientset *kubernetes.Clientset, namespace, podName string, opts LogOptions) (string, error) {
 	podLogOpts := &corev1.PodLogOptions{
-		Container: containerName,
-		TailLines: &tailLines,
+		Container: opts.Container,
+		TailLines: &opts.TailLines,
+		Previous:  opts.Previous,
+	}
+	if opts.Since > 0 {
+		sinceSeconds := int64(opts.Since.Seconds())
+		podLogOpts.SinceSeconds = &sinceSeconds
 	}
 
 	req := clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOpts)
@@ -33,109 +41,85 @@ func FetchPodLogs(ctx context.Context, clientset *kubernetes.Clientset, namespac
 		return "", fmt.Errorf("failed to read logs: %w", err)
 	}
 
-	logContent := string(logs)
-	lines := strings.Split(logContent, "\n")
-	var relevantLines []string
-
-	for _, line := range lines {
-		lowerLine := strings.ToLower(line)
-		if strings.Contains(lowerLine, "error") ||
-			strings.Contains(lowerLine, "warn") ||
-			strings.Contains(lowerLine, "fail") ||
-			strings.Contains(lowerLine, "fatal") ||
-			strings.Contains(lowerLine, "level=error") ||
-			strings.Contains(lowerLine, "level=warn") ||
-			strings.Contains(lowerLine, "degraded") ||
-			strings.Contains(lowerLine, "faulted") ||
-			strings.Contains(lowerLine, "replica") ||
-			strings.Contains(lowerLine, "robustness") ||
-			strings.Contains(lowerLine, "not schedulable") ||
-			strings.Contains(lowerLine, "no healthy") {
-			relevantLines = append(relevantLines, line)
-		}
-	}
+	// The API server already applied the window, relative to now
+	opts.Since = 0
+	return filterLogs(string(logs), opts), nil
+}
 
-	if len(relevantLines) > 0 {
-		return strings.Join(relevantLines, "\n"), nil
-	}
+// isRelevantLine reports whether a line looks like an error, a warning or a
+// Longhorn state change
+func isRelevantLine(line string) bool {
+	lowerLine := strings.ToLower(line)
+	return strings.Contains(lowerLine, "error") ||
+		strings.Contains(lowerLine, "warn") ||
+		strings.Contains(lowerLine, "fail") ||
+		strings.Contains(lowerLine, "fatal") ||
+		strings.Contains(lowerLine, "level=error") ||
+		strings.Contains(lowerLine, "level=warn") ||
+		strings.Contains(lowerLine, "degraded") ||
+		strings.Contains(lowerLine, "faulted") ||
+		strings.Contains(lowerLine, "replica") ||
+		strings.Contains(lowerLine, "robustness") ||
+		strings.Contains(lowerLine, "not schedulable") ||
+		strings.Contains(lowerLine, "no healthy")
+}
 
-	// If no errors found, return last 50 lines as fallback
-	if len(lines) > 50 {
-		return strings.Join(lines[len(lines)-50:], "\n"), nil
+// CollectLogsForIssue reads the logs named by the collection plan of the
+// issue type, preferring pods on the nodes of the affected resources
+func CollectLogsForIssue(ctx context.Context, logs LogSource, req types.LogAnalysisRequest) (string, error) {
+	plan, ok := CollectionPlanFor(req.IssueType)
+	if !ok {
+		return "", fmt.Errorf("log collection not implemented for issue type: %s", req.IssueType)
 	}
-	return logContent, nil
-}
 
-func CollectLogsForIssue(ctx context.Context, clientset *kubernetes.Clientset, req types.LogAnalysisRequest) (string, error) {
-	var logParts []string
+	since := timeWindow(req.TimeWindow)
+	scope := issueScope(req)
 
-	switch req.IssueType {
-	case "replica-faulted":
-		// 1. Longhorn manager pods — contain volume/replica state-change events
-		//    e.g. "volume robustness changed to degraded", "replica X is in error state"
-		managerPods, err := clientset.CoreV1().Pods("longhorn-system").List(ctx, metav1.ListOptions{
-			LabelSelector: "app=longhorn-manager",
-		})
+	var logParts []string
+	for _, target := range plan(req) {
+		pods, err := logs.ListPods(ctx, target.Namespace, target.LabelSelector)
 		if err != nil {
-			logParts = append(logParts, fmt.Sprintf("(failed to list longhorn-manager pods: %v)", err))
-		} else {
-			limit := 2
-			if len(managerPods.Items) < limit {
-				limit = len(managerPods.Items)
-			}
-			for i := 0; i < limit; i++ {
-				podName := managerPods.Items[i].Name
-				logs, err := FetchPodLogs(ctx, clientset, "longhorn-system", podName, "longhorn-manager", 500)
-				if err != nil {
-					logParts = append(logParts, fmt.Sprintf("(failed to get logs from longhorn-manager %s: %v)", podName, err))
-				} else {
-					logParts = append(logParts, fmt.Sprintf("=== Longhorn Manager: %s ===", podName))
-					logParts = append(logParts, logs)
-				}
+			logParts = append(logParts, fmt.Sprintf("(failed to list %s pods: %v)", target.Title, err))
+			continue
+		}
+		if len(pods) == 0 {
+			if !target.Optional {
+				logParts = append(logParts, fmt.Sprintf("(no %s pods found in %s)", target.Title, target.Namespace))
 			}
+			continue
 		}
 
-		// 2. Instance-manager pods — contain engine/replica process errors
-		imPods, err := clientset.CoreV1().Pods("longhorn-system").List(ctx, metav1.ListOptions{
-			LabelSelector: "longhorn.io/component=instance-manager",
-		})
-		if err != nil {
-			logParts = append(logParts, fmt.Sprintf("(failed to list instance-manager pods: %v)", err))
-		} else {
-			limit := 2
-			if len(imPods.Items) < limit {
-				limit = len(imPods.Items)
-			}
-			for i := 0; i < limit; i++ {
-				podName := imPods.Items[i].Name
-				logs, err := FetchPodLogs(ctx, clientset, "longhorn-system", podName, "instance-manager", 200)
-				if err != nil {
-					logParts = append(logParts, fmt.Sprintf("(failed to get logs from instance-manager %s: %v)", podName, err))
-				} else {
-					logParts = append(logParts, fmt.Sprintf("=== Instance Manager: %s ===", podName))
-					logParts = append(logParts, logs)
-				}
+		selected := selectPods(target, pods)
+		if len(selected) == 0 {
+			if len(target.Nodes) > 0 {
+				logParts = append(logParts, fmt.Sprintf("(no %s pods on %s)", target.Title, strings.Join(target.Nodes, ", ")))
+			} else {
+				logParts = append(logParts, fmt.Sprintf("(no %s pods named %s)", target.Title, strings.Join(target.PodNames, ", ")))
 			}
+			continue
 		}
+		for _, pod := range selected {
+			opts := LogOptions{Container: target.Container, TailLines: target.TailLines, Since: since, Scope: scope}
+			podLogs, err := logs.PodLogs(ctx, target.Namespace, pod.Name, opts)
+			if err != nil {
+				logParts = append(logParts, fmt.Sprintf("(failed to get logs from %s %s: %v)", target.Title, pod.Name, err))
+				continue
+			}
+			logParts = append(logParts, podHeading(target, pod))
+			logParts = append(logParts, podLogs)
 
-		// 3. Volume-specific replica pods (if volume name provided)
-		if req.VolumeName != "" {
-			replicaPods, err := clientset.CoreV1().Pods("longhorn-system").List(ctx, metav1.ListOptions{
-				LabelSelector: fmt.Sprintf("longhornvolume=%s", req.VolumeName),
-			})
-			if err == nil && len(replicaPods.Items) > 0 {
-				for _, pod := range replicaPods.Items {
-					logs, err := FetchPodLogs(ctx, clientset, "longhorn-system", pod.Name, "", 200)
-					if err == nil {
-						logParts = append(logParts, fmt.Sprintf("=== Replica pod for volume %s: %s ===", req.VolumeName, pod.Name))
-						logParts = append(logParts, logs)
-					}
+			// The crash that caused a restart is only in the previous instance
+			if restarts := pod.Restarts[target.Container]; target.Container != "" && restarts > 0 {
+				opts.Previous = true
+				previousLogs, err := logs.PodLogs(ctx, target.Namespace, pod.Name, opts)
+				if err != nil {
+					logParts = append(logParts, fmt.Sprintf("(no previous logs from %s %s: %v)", target.Title, pod.Name, err))
+					continue
 				}
+				logParts = append(logParts, fmt.Sprintf("=== %s: %s previous container (restarted %d times) ===", target.Title, pod.Name, restarts))
+				logParts = append(logParts, previousLogs)
 			}
 		}
-
-	default:
-		return "", fmt.Errorf("log collection not implemented for issue type: %s", req.IssueType)
 	}
 
 	if len(logParts) == 0 {
@@ -144,3 +128,41 @@ func CollectLogsForIssue(ctx context.Context, clientset *kubernetes.Clientset, r
 
 	return strings.Join(logParts, "\n\n"), nil
 }
+
+// timeWindow parses the request's window like "30m", "1h" or "2d",
+// returning 0 for no window
+func timeWindow(window string) time.Duration {
+	if window == "" {
+		return 0
+	}
+	now := time.Now()
+	at, err := logrecord.ParseSince(window, now)
+	if err != nil {
+		log.Printf("Warning: Ignoring invalid time window %q", window)
+		return 0
+	}
+	return now.Sub(at)
+}
+
+// issueScope returns the names of the resources an issue is about, so logs
+// about other volumes can be dropped
+func issueScope(req types.LogAnalysisRequest) []string {
+	var scope []string
+	if req.VolumeName != "" {
+		scope = append(scope, req.VolumeName)
+	}
+	for _, replica := range req.ReplicaDetails {
+		if replica.Name != "" {
+			scope = append(scope, replica.Name)
+		}
+	}
+	for _, engine := range req.EngineDetails {
+		if engine.Name != "" {
+			scope = append(scope, engine.Name)
+		}
+	}
+	if req.VMName != "" {
+		scope = append(scope, req.VMName)
+	}
+	return scope
+}
@@ -0,0 +1,154 @@
+package loganalysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/bundle"
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogSource lists pods and reads their logs, either from the live cluster or
+// from a support bundle
+type LogSource interface {
+	ListPods(ctx context.Context, namespace, labelSelector string) ([]Pod, error)
+	PodLogs(ctx context.Context, namespace, podName string, opts LogOptions) (string, error)
+}
+
+// Pod is a pod that logs can be collected from
+type Pod struct {
+	Name     string
+	NodeName string
+	// Restarts is the restart count of each container
+	Restarts map[string]int32
+}
+
+// LogOptions selects which container log is read and how much of it
+type LogOptions struct {
+	// Container is the container to read; empty reads the first container
+	Container string
+	TailLines int64
+	// Previous reads the log of the container's previous instance
+	Previous bool
+	// Since drops lines older than this window
+	Since time.Duration
+	// Scope names the resources of interest; lines only about other volumes
+	// are dropped
+	Scope []string
+}
+
+// ClusterLogSource reads pod logs from the API server
+type ClusterLogSource struct {
+	clientset *kubernetes.Clientset
+}
+
+// CreateClusterLogSource creates a log source backed by the API server
+func CreateClusterLogSource(clientset *kubernetes.Clientset) *ClusterLogSource {
+	return &ClusterLogSource{clientset: clientset}
+}
+
+// ListPods returns the pods matching labelSelector
+func (s *ClusterLogSource) ListPods(ctx context.Context, namespace, labelSelector string) ([]Pod, error) {
+	pods, err := s.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		restarts := make(map[string]int32, len(pod.Status.ContainerStatuses))
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts[status.Name] = status.RestartCount
+		}
+		result = append(result, Pod{Name: pod.Name, NodeName: pod.Spec.NodeName, Restarts: restarts})
+	}
+	return result, nil
+}
+
+// PodLogs returns the relevant lines from a container's recent logs
+func (s *ClusterLogSource) PodLogs(ctx context.Context, namespace, podName string, opts LogOptions) (string, error) {
+	return FetchPodLogs(ctx, s.clientset, namespace, podName, opts)
+}
+
+// BundleLogSource reads pod logs captured in a support bundle
+type BundleLogSource struct {
+	bundle *bundle.Bundle
+}
+
+// CreateBundleLogSource creates a log source backed by a support bundle
+func CreateBundleLogSource(b *bundle.Bundle) *BundleLogSource {
+	return &BundleLogSource{bundle: b}
+}
+
+// ListPods returns the bundled pods matching labelSelector
+func (s *BundleLogSource) ListPods(ctx context.Context, namespace, labelSelector string) ([]Pod, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", labelSelector, err)
+	}
+
+	pods, err := s.bundle.List(cache.Pods, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Pod
+	for _, pod := range pods {
+		metadata, _ := pod["metadata"].(map[string]interface{})
+		spec, _ := pod["spec"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		status, _ := pod["status"].(map[string]interface{})
+		nodeName, _ := spec["nodeName"].(string)
+		podLabels := make(labels.Set)
+		if rawLabels, ok := metadata["labels"].(map[string]interface{}); ok {
+			for key, value := range rawLabels {
+				if str, ok := value.(string); ok {
+					podLabels[key] = str
+				}
+			}
+		}
+		restarts := make(map[string]int32)
+		containerStatuses, _ := status["containerStatuses"].([]interface{})
+		for _, raw := range containerStatuses {
+			containerStatus, _ := raw.(map[string]interface{})
+			containerName, _ := containerStatus["name"].(string)
+			switch count := containerStatus["restartCount"].(type) {
+			case int64:
+				restarts[containerName] = int32(count)
+			case float64:
+				restarts[containerName] = int32(count)
+			}
+		}
+		if name != "" && selector.Matches(podLabels) {
+			result = append(result, Pod{Name: name, NodeName: nodeName, Restarts: restarts})
+		}
+	}
+	return result, nil
+}
+
+// PodLogs returns the relevant lines from the last TailLines lines of a
+// bundled container log. The window is measured back from the newest line,
+// since the bundle was collected at some unknown time.
+func (s *BundleLogSource) PodLogs(ctx context.Context, namespace, podName string, opts LogOptions) (string, error) {
+	readLog := s.bundle.PodLog
+	if opts.Previous {
+		readLog = s.bundle.PreviousPodLog
+	}
+	logContent, err := readLog(namespace, podName, opts.Container)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimRight(logContent, "\n"), "\n")
+	if opts.TailLines > 0 && int64(len(lines)) > opts.TailLines {
+		lines = lines[int64(len(lines))-opts.TailLines:]
+	}
+	return filterLogs(strings.Join(lines, "\n"), opts), nil
+}
@@ -6,9 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"strings"
 
 	types "github.com/rk280392/harvesterNavigator/internal/models"
 )
@@ -16,6 +14,7 @@ import (
 type OllamaAnalyzer struct {
 	baseURL string
 	model   string
+	llmOptions
 }
 
 func NewOllamaAnalyzer(baseURL, model string) (*OllamaAnalyzer, error) {
@@ -28,8 +27,9 @@ func NewOllamaAnalyzer(baseURL, model string) (*OllamaAnalyzer, error) {
 	}
 
 	return &OllamaAnalyzer{
-		baseURL: baseURL,
-		model:   model,
+		baseURL:    baseURL,
+		model:      model,
+		llmOptions: llmOptions{retries: DefaultRetries},
 	}, nil
 }
 
@@ -41,15 +41,20 @@ type OllamaRequest struct {
 }
 
 type OllamaResponse struct {
-	Model         string `json:"model"`
-	Response      string `json:"response"`
-	Done          bool   `json:"done"`
-	Context       []int  `json:"context,omitempty"`
-	TotalDuration int64  `jsosn:"total_duration,omitempty"`
-	EvalCount     int    `json:"eval_count,omitempty"`
+	Model           string `json:"model"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	Context         []int  `json:"context,omitempty"`
+	TotalDuration   int64  `json:"total_duration,omitempty"`
+	PromptEvalCount int    `json:"prompt_eval_count,omitempty"`
+	EvalCount       int    `json:"eval_count,omitempty"`
 }
 
 func (o *OllamaAnalyzer) Analyze(ctx context.Context, prompt string) (*types.LogAnalysisResult, error) {
+	return analyzeWithRetries(ctx, "ollama-"+o.model, o.complete, prompt, &o.llmOptions)
+}
+
+func (o *OllamaAnalyzer) complete(ctx context.Context, prompt string) (*Completion, error) {
 	// Add JSON schema instruction to prompt
 	fullPrompt := prompt + `
 
@@ -107,63 +112,11 @@ RULES:
 		return nil, fmt.Errorf("failed to decode ollama response: %w", err)
 	}
 
-	// Debug: Log the raw response
-	//	log.Printf("===== OLLAMA RAW RESPONSE =====")
-	//	log.Printf("%s", ollamaResp.Response)
-	//	log.Printf("===== END OLLAMA RESPONSE =====")
-
-	// Extract JSON from response (might be wrapped in markdown or have text around it)
-	responseText := ollamaResp.Response
-
-	// Try to find JSON in markdown code blocks first
-	jsonStart := strings.Index(responseText, "```json")
-	jsonEnd := strings.LastIndex(responseText, "```")
-
-	if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-		// Extract JSON from markdown block
-		responseText = responseText[jsonStart+7 : jsonEnd]
-	} else {
-		// Try to find raw JSON object
-		jsonStart = strings.Index(responseText, "{")
-		jsonEnd = strings.LastIndex(responseText, "}")
-
-		if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-			responseText = responseText[jsonStart : jsonEnd+1]
-		}
-	}
-
-	responseText = strings.TrimSpace(responseText)
-
-	// Sanitize JSON: remove ALL backslash-underscore and similar invalid escapes
-	// These commonly appear when LLMs try to escape underscores incorrectly
-	responseText = strings.ReplaceAll(responseText, `\_`, "_")
-	responseText = strings.ReplaceAll(responseText, `\-`, "-")
-	responseText = strings.ReplaceAll(responseText, `\.`, ".")
-
-	// Also fix if they appear in the raw response before extraction
-	responseText = strings.ReplaceAll(responseText, `root\_cause`, `root_cause`)
-	responseText = strings.ReplaceAll(responseText, `error\_lines`, `error_lines`)
-	responseText = strings.ReplaceAll(responseText, `failing\_component`, `failing_component`)
-	responseText = strings.ReplaceAll(responseText, `recommended\_action`, `recommended_action`)
-
-	log.Printf("===== SANITIZED JSON =====")
-	log.Printf("%s", responseText)
-	log.Printf("===== END SANITIZED =====")
-
-	// Parse the extracted JSON
-	var result types.LogAnalysisResult
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		log.Printf("Failed to parse JSON from Ollama response. Response was: %s", responseText)
-		log.Printf("Parse error: %v", err)
-		return nil, fmt.Errorf("failed to parse analysis result: %w", err)
-	}
-
-	// Add metadata
-	result.Provider = "ollama-" + o.model
-	result.TokensUsed = ollamaResp.EvalCount
-	result.EstimatedCost = 0.0 // Local, no cost
-
-	return &result, nil
+	return &Completion{
+		Text:             ollamaResp.Response,
+		PromptTokens:     ollamaResp.PromptEvalCount,
+		CompletionTokens: ollamaResp.EvalCount,
+	}, nil
 }
 
 // Name returns the provider name
@@ -171,7 +124,12 @@ func (o *OllamaAnalyzer) Name() string {
 	return "ollama"
 }
 
-// EstimatedCost returns cost estimate (always 0 for local)
+// EstimatedCost returns cost estimate (0 for local models without a cost table)
 func (o *OllamaAnalyzer) EstimatedCost() float64 {
-	return 0.0
+	return o.cost.PerThousand()
+}
+
+// Ping checks that the Ollama server answers
+func (o *OllamaAnalyzer) Ping(ctx context.Context) error {
+	return pingURL(ctx, o.baseURL+"/api/tags", "")
 }
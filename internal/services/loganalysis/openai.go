@@ -0,0 +1,174 @@
+package loganalysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// OpenAIAnalyzer talks to any server with an OpenAI-compatible chat
+// completions API, such as vLLM, llama.cpp server, LocalAI or LiteLLM
+type OpenAIAnalyzer struct {
+	baseURL    string
+	basePath   string
+	model      string
+	apiKey     string
+	jsonSchema bool
+	client     *http.Client
+	llmOptions
+}
+
+type OpenAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type OpenAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Strict bool           `json:"strict"`
+	Schema map[string]any `json:"schema"`
+}
+
+type OpenAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []OpenAIMessage       `json:"messages"`
+	ResponseFormat *OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type OpenAIChoice struct {
+	Message      OpenAIMessage `json:"message"`
+	FinishReason string        `json:"finish_reason"`
+}
+
+type OpenAIResponse struct {
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   OpenAIUsage    `json:"usage"`
+}
+
+// openAISystemPrompt is sent as the system message in place of the prompt's
+// own preamble and task; the issue details and logs follow as the user
+// message
+const openAISystemPrompt = "You are an expert in Harvester, KubeVirt and Longhorn troubleshooting. " +
+	"Find the root cause of the issue the user describes from the cluster state and logs they provide. " +
+	"Reply with a single JSON object with the fields root_cause, error_lines, failing_component, " +
+	"recommended_action and confidence (high, medium or low), and nothing else."
+
+// NewOpenAIAnalyzer creates an analyzer for the chat completions API under
+// baseURL+basePath; basePath defaults to /v1. With jsonSchema the request
+// asks for output matching the analysis schema, which not every server
+// supports.
+func NewOpenAIAnalyzer(baseURL, basePath, model, apiKey string, jsonSchema bool) (*OpenAIAnalyzer, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("baseURL is required for OpenAI-compatible analyzer")
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model is required for OpenAI-compatible analyzer")
+	}
+	if basePath == "" {
+		basePath = "/v1"
+	}
+
+	return &OpenAIAnalyzer{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		basePath:   "/" + strings.Trim(basePath, "/"),
+		model:      model,
+		apiKey:     apiKey,
+		jsonSchema: jsonSchema,
+		client:     &http.Client{},
+		llmOptions: llmOptions{retries: DefaultRetries},
+	}, nil
+}
+
+func (o *OpenAIAnalyzer) Analyze(ctx context.Context, prompt string) (*types.LogAnalysisResult, error) {
+	return analyzeWithRetries(ctx, "openai-"+o.model, o.complete, prompt, &o.llmOptions)
+}
+
+func (o *OpenAIAnalyzer) complete(ctx context.Context, prompt string) (*Completion, error) {
+	reqBody := OpenAIRequest{
+		Model: o.model,
+		Messages: []OpenAIMessage{
+			{Role: "system", Content: openAISystemPrompt},
+			{Role: "user", Content: promptEvidence(prompt)},
+		},
+	}
+	if o.jsonSchema {
+		reqBody.ResponseFormat = &OpenAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &OpenAIJSONSchema{Name: "log_analysis", Strict: true, Schema: analysisSchema},
+		}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url("/chat/completions"), bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI-compatible API call failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("OpenAI-compatible API error: %s - %s", resp.Status, string(body))
+	}
+
+	var chatResp OpenAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	return &Completion{
+		Text:             chatResp.Choices[0].Message.Content,
+		PromptTokens:     chatResp.Usage.PromptTokens,
+		CompletionTokens: chatResp.Usage.CompletionTokens,
+		TotalTokens:      chatResp.Usage.TotalTokens,
+	}, nil
+}
+
+func (o *OpenAIAnalyzer) Name() string {
+	return "openai"
+}
+
+func (o *OpenAIAnalyzer) EstimatedCost() float64 {
+	return o.cost.PerThousand()
+}
+
+// Ping checks the server and API key by listing the models
+func (o *OpenAIAnalyzer) Ping(ctx context.Context) error {
+	return pingURL(ctx, o.url("/models"), o.apiKey)
+}
+
+func (o *OpenAIAnalyzer) url(endpoint string) string {
+	return o.baseURL + strings.TrimSuffix(o.basePath, "/") + endpoint
+}
@@ -0,0 +1,79 @@
+package loganalysis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func TestOpenAIAnalyzer_Analyze(t *testing.T) {
+	var got OpenAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/openai/v1/models":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+			return
+		case r.URL.Path != "/openai/v1/chat/completions":
+			http.NotFound(w, r)
+			return
+		case r.Header.Get("Authorization") != "Bearer secret":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{
+			"model": "qwen2.5-7b",
+			"choices": [{"message": {"role": "assistant", "content": "Here you go:\n` + "```json" + `\n{\"root_cause\": \"replica rebuild failed\", \"error_lines\": [], \"failing_component\": \"longhorn-engine\", \"recommended_action\": \"check disk\", \"confidence\": \"medium\"}\n` + "```" + `"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 900, "completion_tokens": 100, "total_tokens": 1000}
+		}`))
+	}))
+	defer server.Close()
+
+	analyzer, err := NewOpenAIAnalyzer(server.URL+"/", "openai/v1/", "qwen2.5-7b", "secret", true)
+	if err != nil {
+		t.Fatalf("NewOpenAIAnalyzer: %v", err)
+	}
+	analyzer.cost = CostTable{InputPerMillion: 1, OutputPerMillion: 2}
+
+	prompt := BuildPrompt(types.LogAnalysisRequest{IssueType: "replica-faulted"}, "", 0).Text
+	result, err := analyzer.Analyze(context.Background(), prompt)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if result.RootCause != "replica rebuild failed" || result.Confidence != "medium" {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Provider != "openai-qwen2.5-7b" || result.TokensUsed != 1000 || result.EstimatedCost != 0.0011 {
+		t.Errorf("provider=%s tokens=%d cost=%v, want openai-qwen2.5-7b, 1000 tokens, 0.0011", result.Provider, result.TokensUsed, result.EstimatedCost)
+	}
+
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Role != "user" ||
+		!strings.Contains(got.Messages[1].Content, "Issue Type: replica-faulted") {
+		t.Errorf("messages = %+v, want a system message then the prompt", got.Messages)
+	}
+	// The system message carries the role and task in place of the prompt's own
+	if user := got.Messages[1].Content; strings.Contains(user, "You are analyzing") || strings.Contains(user, "TASK:") {
+		t.Errorf("user message repeats the instructions:\n%s", user)
+	}
+	if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_schema" || got.ResponseFormat.JSONSchema.Name != "log_analysis" {
+		t.Errorf("response_format = %+v, want json_schema", got.ResponseFormat)
+	} else if schema, _ := json.Marshal(got.ResponseFormat.JSONSchema.Schema); strings.Contains(string(schema), "minLength") {
+		t.Errorf("strict schema uses minLength, which OpenAI rejects: %s", schema)
+	}
+
+	if err := analyzer.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+
+	analyzer.apiKey = "wrong"
+	if _, err := analyzer.Analyze(context.Background(), "prompt"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Analyze with a bad key = %v, want a 401 error", err)
+	}
+}
@@ -6,9 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"strings"
 
 	types "github.com/rk280392/harvesterNavigator/internal/models"
 )
@@ -53,6 +51,7 @@ type OpenWebUIAnalyzer struct {
 	apiKey       string
 	collectionID string
 	client       *http.Client
+	llmOptions
 }
 
 func NewOpenwebuiAnalyzer(baseURL, model, apiKey, collectionID string) (*OpenWebUIAnalyzer, error) {
@@ -73,10 +72,15 @@ func NewOpenwebuiAnalyzer(baseURL, model, apiKey, collectionID string) (*OpenWeb
 		apiKey:       apiKey,
 		collectionID: collectionID,
 		client:       &http.Client{},
+		llmOptions:   llmOptions{retries: DefaultRetries},
 	}, nil
 }
 
 func (o *OpenWebUIAnalyzer) Analyze(ctx context.Context, prompt string) (*types.LogAnalysisResult, error) {
+	return analyzeWithRetries(ctx, "openwebui-"+o.model, o.complete, prompt, &o.llmOptions)
+}
+
+func (o *OpenWebUIAnalyzer) complete(ctx context.Context, prompt string) (*Completion, error) {
 	reqBody := OpenWebUIRequest{
 		Model: o.model,
 		Messages: []OpenWebUIMessage{
@@ -121,37 +125,11 @@ func (o *OpenWebUIAnalyzer) Analyze(ctx context.Context, prompt string) (*types.
 	if len(owuResp.Choices) == 0 {
 		return nil, fmt.Errorf("no choices in response")
 	}
-	responseText := owuResp.Choices[0].Message.Content
-
-	// Strip markdown code blocks if present
-	jsonStart := strings.Index(responseText, "```json")
-	jsonEnd := strings.LastIndex(responseText, "```")
-
-	if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-		responseText = responseText[jsonStart+7 : jsonEnd]
-	} else {
-		jsonStart = strings.Index(responseText, "{")
-		jsonEnd = strings.LastIndex(responseText, "}")
-		if jsonStart != -1 && jsonEnd != -1 && jsonEnd > jsonStart {
-			responseText = responseText[jsonStart : jsonEnd+1]
-		}
-	}
-
-	responseText = strings.TrimSpace(responseText)
-	log.Printf("===== OPENWEBUI RAW RESPONSE =====")
-	log.Printf("%s", responseText)
-	log.Printf("===== END RESPONSE =====")
-
-	var result types.LogAnalysisResult
-	if err := json.Unmarshal([]byte(responseText), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse analysis result: %w", err)
-	}
-
-	result.Provider = "openwebui-" + o.model
-	result.TokensUsed = owuResp.Usage.CompletionTokens
-	result.EstimatedCost = 0.0
-
-	return &result, nil
+	return &Completion{
+		Text:             owuResp.Choices[0].Message.Content,
+		PromptTokens:     owuResp.Usage.PromptTokens,
+		CompletionTokens: owuResp.Usage.CompletionTokens,
+	}, nil
 }
 
 func (o *OpenWebUIAnalyzer) Name() string {
@@ -159,5 +137,10 @@ func (o *OpenWebUIAnalyzer) Name() string {
 }
 
 func (o *OpenWebUIAnalyzer) EstimatedCost() float64 {
-	return 0.0
+	return o.cost.PerThousand()
+}
+
+// Ping checks the URL and API key by listing the models
+func (o *OpenWebUIAnalyzer) Ping(ctx context.Context) error {
+	return pingURL(ctx, o.baseURL+"/api/models", o.apiKey)
 }
@@ -0,0 +1,366 @@
+package loganalysis
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// defaultPodLimit is how many pods of a target are read when the issue does
+// not point at particular nodes
+const defaultPodLimit = 2
+
+// LogTarget is a group of pods whose container logs are collected for an issue
+type LogTarget struct {
+	// Title heads the collected logs, e.g. "Longhorn Manager"
+	Title         string
+	Namespace     string
+	LabelSelector string
+	// Container is read from each pod; empty reads the first container
+	Container string
+	TailLines int64
+	// PodNames picks these pods by name, along with the pods on Nodes
+	PodNames []string
+	// Nodes restricts the target to pods on these nodes
+	Nodes []string
+	// Limit caps the pods read when neither PodNames nor Nodes is set
+	Limit int
+	// Optional targets are skipped silently when they match no pod
+	Optional bool
+}
+
+// CollectionPlan returns the log targets for an issue
+type CollectionPlan func(req types.LogAnalysisRequest) []LogTarget
+
+var (
+	plansMutex sync.RWMutex
+	plans      = make(map[string]CollectionPlan)
+)
+
+// RegisterCollectionPlan sets the plan used for issues of issueType,
+// replacing any plan registered before
+func RegisterCollectionPlan(issueType string, plan CollectionPlan) {
+	plansMutex.Lock()
+	defer plansMutex.Unlock()
+	plans[issueType] = plan
+}
+
+// CollectionPlanFor returns the plan registered for issueType
+func CollectionPlanFor(issueType string) (CollectionPlan, bool) {
+	plansMutex.RLock()
+	defer plansMutex.RUnlock()
+	plan, ok := plans[issueType]
+	return plan, ok
+}
+
+// CollectionPlanTypes lists the issue types that have a plan
+func CollectionPlanTypes() []string {
+	plansMutex.RLock()
+	defer plansMutex.RUnlock()
+	issueTypes := make([]string, 0, len(plans))
+	for issueType := range plans {
+		issueTypes = append(issueTypes, issueType)
+	}
+	sort.Strings(issueTypes)
+	return issueTypes
+}
+
+func init() {
+	for _, issueType := range []string{"replica-faulted", "orphaned-replicas", "disk-not-schedulable"} {
+		RegisterCollectionPlan(issueType, replicaPlan)
+	}
+	for _, issueType := range []string{"migration-stuck", "vm-migration-stuck", "migration-scheduling-failed", "migration-affinity-mismatch"} {
+		RegisterCollectionPlan(issueType, migrationPlan)
+	}
+	for _, issueType := range []string{"attachment-tickets-unsatisfied", "attachment-condition-failed", "volume-attachment-conflict"} {
+		RegisterCollectionPlan(issueType, attachmentPlan)
+	}
+	RegisterCollectionPlan("upgrade-blocked-migration", upgradePlan)
+	RegisterCollectionPlan("vm-pending", vmLifecyclePlan)
+	RegisterCollectionPlan("vm-stuck-terminating", vmLifecyclePlan)
+	RegisterCollectionPlan("node-not-ready", nodePlan)
+}
+
+// ── Common targets ──────────────────────────────────────────────────────────
+
+func longhornManager(nodes []string) LogTarget {
+	return LogTarget{
+		Title:         "Longhorn Manager",
+		Namespace:     "longhorn-system",
+		LabelSelector: "app=longhorn-manager",
+		Container:     "longhorn-manager",
+		TailLines:     500,
+		Nodes:         nodes,
+	}
+}
+
+func instanceManager(nodes []string) LogTarget {
+	return LogTarget{
+		Title:         "Instance Manager",
+		Namespace:     "longhorn-system",
+		LabelSelector: "longhorn.io/component=instance-manager",
+		Container:     "instance-manager",
+		TailLines:     200,
+		Nodes:         nodes,
+	}
+}
+
+func virtHandler(nodes []string) LogTarget {
+	return LogTarget{
+		Title:         "virt-handler",
+		Namespace:     "harvester-system",
+		LabelSelector: "kubevirt.io=virt-handler",
+		Container:     "virt-handler",
+		TailLines:     300,
+		Nodes:         nodes,
+	}
+}
+
+func virtController() LogTarget {
+	return LogTarget{
+		Title:         "virt-controller",
+		Namespace:     "harvester-system",
+		LabelSelector: "kubevirt.io=virt-controller",
+		Container:     "virt-controller",
+		TailLines:     300,
+	}
+}
+
+// virtLauncher targets every launcher pod of the VM, e.g. both the source
+// and target pod of a migration
+func virtLauncher(req types.LogAnalysisRequest) LogTarget {
+	return LogTarget{
+		Title:         "virt-launcher",
+		Namespace:     req.Namespace,
+		LabelSelector: "vm.kubevirt.io/name=" + req.VMName,
+		Container:     "compute",
+		TailLines:     200,
+		Limit:         4,
+	}
+}
+
+// ── Plans ───────────────────────────────────────────────────────────────────
+
+// replicaPlan reads Longhorn on the nodes of the volume's engine and
+// replicas, where replica state changes and replica process errors are
+// logged. Instance managers the replicas name are read as well.
+func replicaPlan(req types.LogAnalysisRequest) []LogTarget {
+	nodes := storageNodes(req)
+	im := instanceManager(nodes)
+	for _, replica := range req.ReplicaDetails {
+		if replica.InstanceManager != "" {
+			im.PodNames = append(im.PodNames, replica.InstanceManager)
+		}
+	}
+	targets := []LogTarget{longhornManager(nodes), im}
+	if req.VolumeName != "" {
+		targets = append(targets, LogTarget{
+			Title:         "Replica pod for volume " + req.VolumeName,
+			Namespace:     "longhorn-system",
+			LabelSelector: "longhornvolume=" + req.VolumeName,
+			TailLines:     200,
+			Limit:         10,
+			Optional:      true,
+		})
+	}
+	return targets
+}
+
+// migrationPlan reads KubeVirt on the source and target node, the migration
+// controller, and both launcher pods
+func migrationPlan(req types.LogAnalysisRequest) []LogTarget {
+	targets := []LogTarget{virtHandler(affectedNodes(req)), virtController()}
+	if req.VMName != "" && req.Namespace != "" {
+		targets = append(targets, virtLauncher(req))
+	}
+	return targets
+}
+
+// attachmentPlan reads the CSI driver and Longhorn on the node the volume is
+// attached to
+func attachmentPlan(req types.LogAnalysisRequest) []LogTarget {
+	nodes := attachedNodes(req)
+	return []LogTarget{
+		{
+			Title:         "Longhorn CSI Plugin",
+			Namespace:     "longhorn-system",
+			LabelSelector: "app=longhorn-csi-plugin",
+			Container:     "longhorn-csi-plugin",
+			TailLines:     300,
+			Nodes:         nodes,
+		},
+		{
+			Title:         "CSI Attacher",
+			Namespace:     "longhorn-system",
+			LabelSelector: "app=csi-attacher",
+			Container:     "csi-attacher",
+			TailLines:     300,
+		},
+		longhornManager(nodes),
+	}
+}
+
+// upgradePlan reads the Harvester upgrade jobs and the fleet agent that
+// applies the upgraded charts, plus KubeVirt for the blocked migration
+func upgradePlan(req types.LogAnalysisRequest) []LogTarget {
+	targets := []LogTarget{
+		{
+			Title:         "Harvester Upgrade Job",
+			Namespace:     "harvester-system",
+			LabelSelector: "harvesterhci.io/upgradeComponent",
+			TailLines:     300,
+			Limit:         5,
+		},
+		{
+			Title:         "Fleet Agent",
+			Namespace:     "cattle-fleet-local-system",
+			LabelSelector: "app=fleet-agent",
+			Container:     "fleet-agent",
+			TailLines:     300,
+		},
+	}
+	return append(targets, migrationPlan(req)...)
+}
+
+// vmLifecyclePlan reads what starts and stops a VM
+func vmLifecyclePlan(req types.LogAnalysisRequest) []LogTarget {
+	targets := []LogTarget{virtController(), virtHandler(affectedNodes(req))}
+	if req.VMName != "" && req.Namespace != "" {
+		launcher := virtLauncher(req)
+		launcher.Optional = true
+		targets = append(targets, launcher)
+	}
+	return targets
+}
+
+// nodePlan reads the node agents of Longhorn and KubeVirt on the node
+func nodePlan(req types.LogAnalysisRequest) []LogTarget {
+	nodes := affectedNodes(req)
+	return []LogTarget{longhornManager(nodes), instanceManager(nodes), virtHandler(nodes)}
+}
+
+// affectedNodes returns every node the request ties to the issue
+func affectedNodes(req types.LogAnalysisRequest) []string {
+	var nodes []string
+	add := func(name string) {
+		for _, n := range nodes {
+			if n == name {
+				return
+			}
+		}
+		if name != "" {
+			nodes = append(nodes, name)
+		}
+	}
+
+	add(req.NodeName)
+	add(req.SourceNode)
+	add(req.TargetNode)
+	for _, engine := range req.EngineDetails {
+		add(engine.NodeName)
+	}
+	for _, replica := range req.ReplicaDetails {
+		add(replica.NodeName)
+	}
+	for _, pod := range req.PodDistribution {
+		add(pod.NodeName)
+	}
+	for _, name := range attachedNodes(req) {
+		add(name)
+	}
+	if req.MigrationState != nil {
+		add(req.MigrationState.CurrentMigrationNodeID)
+	}
+	return nodes
+}
+
+// storageNodes returns the nodes hosting the volume's engine and replicas.
+// Without either, every affected node is used.
+func storageNodes(req types.LogAnalysisRequest) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, engine := range req.EngineDetails {
+		if engine.NodeName != "" && !seen[engine.NodeName] {
+			seen[engine.NodeName] = true
+			nodes = append(nodes, engine.NodeName)
+		}
+	}
+	for _, replica := range req.ReplicaDetails {
+		if replica.NodeName != "" && !seen[replica.NodeName] {
+			seen[replica.NodeName] = true
+			nodes = append(nodes, replica.NodeName)
+		}
+	}
+	if len(nodes) > 0 {
+		return nodes
+	}
+	return affectedNodes(req)
+}
+
+// attachedNodes returns the nodes the volume is, or should be, attached to,
+// falling back to the nodes running the VM
+func attachedNodes(req types.LogAnalysisRequest) []string {
+	var nodes []string
+	if state := req.AttachmentState; state != nil {
+		for _, name := range []string{state.CurrentNodeID, state.DesiredNodeID} {
+			if name != "" {
+				nodes = append(nodes, name)
+			}
+		}
+	}
+	if len(nodes) > 0 {
+		return nodes
+	}
+	for _, name := range []string{req.NodeName, req.SourceNode, req.TargetNode} {
+		if name != "" {
+			nodes = append(nodes, name)
+		}
+	}
+	for _, pod := range req.PodDistribution {
+		if pod.NodeName != "" {
+			nodes = append(nodes, pod.NodeName)
+		}
+	}
+	return nodes
+}
+
+// selectPods picks the pods named by the target together with the pods on
+// its nodes. A target without names or nodes reads its first Limit pods.
+func selectPods(target LogTarget, pods []Pod) []Pod {
+	if len(target.PodNames) == 0 && len(target.Nodes) == 0 {
+		limit := target.Limit
+		if limit <= 0 {
+			limit = defaultPodLimit
+		}
+		if len(pods) > limit {
+			pods = pods[:limit]
+		}
+		return pods
+	}
+
+	named := make(map[string]bool, len(target.PodNames))
+	for _, name := range target.PodNames {
+		named[name] = true
+	}
+	onNode := make(map[string]bool, len(target.Nodes))
+	for _, name := range target.Nodes {
+		onNode[name] = true
+	}
+	var selected []Pod
+	for _, pod := range pods {
+		if named[pod.Name] || onNode[pod.NodeName] {
+			selected = append(selected, pod)
+		}
+	}
+	return selected
+}
+
+// podHeading is the heading of one pod's logs
+func podHeading(target LogTarget, pod Pod) string {
+	if pod.NodeName == "" {
+		return fmt.Sprintf("=== %s: %s ===", target.Title, pod.Name)
+	}
+	return fmt.Sprintf("=== %s: %s (node %s) ===", target.Title, pod.Name, pod.NodeName)
+}
@@ -0,0 +1,125 @@
+package loganalysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// fakeLogSource serves pods by label selector and logs that name the pod
+type fakeLogSource struct {
+	pods map[string][]Pod
+}
+
+func (f *fakeLogSource) ListPods(ctx context.Context, namespace, labelSelector string) ([]Pod, error) {
+	return f.pods[labelSelector], nil
+}
+
+func (f *fakeLogSource) PodLogs(ctx context.Context, namespace, podName string, opts LogOptions) (string, error) {
+	if opts.Previous {
+		return "previous logs of " + podName, nil
+	}
+	return "logs of " + podName, nil
+}
+
+func TestCollectLogsForIssue_PrefersAffectedNodes(t *testing.T) {
+	source := &fakeLogSource{pods: map[string][]Pod{
+		"kubevirt.io=virt-handler": {
+			{Name: "virt-handler-a", NodeName: "node-a"},
+			{Name: "virt-handler-b", NodeName: "node-b"},
+			{Name: "virt-handler-c", NodeName: "node-c"},
+		},
+		"kubevirt.io=virt-controller": {{Name: "virt-controller-1", NodeName: "node-a"}},
+		"vm.kubevirt.io/name=vm1": {
+			{Name: "virt-launcher-vm1-src", NodeName: "node-b"},
+			{Name: "virt-launcher-vm1-dst", NodeName: "node-c"},
+		},
+	}}
+
+	logs, err := CollectLogsForIssue(context.Background(), source, types.LogAnalysisRequest{
+		IssueType:  "migration-stuck",
+		VMName:     "vm1",
+		Namespace:  "default",
+		SourceNode: "node-b",
+		TargetNode: "node-c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"virt-handler-b", "virt-handler-c", "virt-controller-1", "virt-launcher-vm1-src", "virt-launcher-vm1-dst"} {
+		if !strings.Contains(logs, "logs of "+want) {
+			t.Errorf("expected logs of %s in:\n%s", want, logs)
+		}
+	}
+	if strings.Contains(logs, "virt-handler-a") {
+		t.Errorf("did not expect virt-handler on an unrelated node:\n%s", logs)
+	}
+}
+
+func TestCollectLogsForIssue_ReplicaNodesAndPreviousLogs(t *testing.T) {
+	source := &fakeLogSource{pods: map[string][]Pod{
+		"app=longhorn-manager": {
+			{Name: "longhorn-manager-a", NodeName: "node-a"},
+			{Name: "longhorn-manager-b", NodeName: "node-b"},
+			{Name: "longhorn-manager-c", NodeName: "node-c", Restarts: map[string]int32{"longhorn-manager": 3}},
+		},
+		"longhorn.io/component=instance-manager": {
+			{Name: "instance-manager-a", NodeName: "node-a"},
+			{Name: "instance-manager-b", NodeName: "node-b"},
+			{Name: "instance-manager-c", NodeName: "node-c"},
+		},
+	}}
+
+	logs, err := CollectLogsForIssue(context.Background(), source, types.LogAnalysisRequest{
+		IssueType:      "replica-faulted",
+		EngineDetails:  []types.EngineDetail{{Name: "pvc-1-e-0", NodeName: "node-c"}},
+		ReplicaDetails: []types.ReplicaDetail{{Name: "pvc-1-r-0", NodeName: "node-b", InstanceManager: "instance-manager-b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"logs of longhorn-manager-b", "logs of longhorn-manager-c", "previous logs of longhorn-manager-c", "logs of instance-manager-b", "logs of instance-manager-c"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected %q in:\n%s", want, logs)
+		}
+	}
+	for _, unwanted := range []string{"longhorn-manager-a", "instance-manager-a", "previous logs of longhorn-manager-b"} {
+		if strings.Contains(logs, unwanted) {
+			t.Errorf("did not expect %q in:\n%s", unwanted, logs)
+		}
+	}
+}
+
+func TestCollectLogsForIssue_NoPodsOnAffectedNodes(t *testing.T) {
+	source := &fakeLogSource{pods: map[string][]Pod{
+		"kubevirt.io=virt-handler": {
+			{Name: "virt-handler-a", NodeName: "node-a"},
+			{Name: "virt-handler-b", NodeName: "node-b"},
+		},
+		"kubevirt.io=virt-controller": {{Name: "virt-controller-1", NodeName: "node-a"}},
+	}}
+
+	logs, err := CollectLogsForIssue(context.Background(), source, types.LogAnalysisRequest{
+		IssueType: "node-not-ready",
+		NodeName:  "node-c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs, "(no virt-handler pods on node-c)") {
+		t.Errorf("expected a note about the missing virt-handler in:\n%s", logs)
+	}
+	if strings.Contains(logs, "virt-handler-a") || strings.Contains(logs, "virt-handler-b") {
+		t.Errorf("did not expect virt-handler pods from other nodes:\n%s", logs)
+	}
+}
+
+func TestCollectLogsForIssue_UnknownIssueType(t *testing.T) {
+	if _, err := CollectLogsForIssue(context.Background(), &fakeLogSource{}, types.LogAnalysisRequest{IssueType: "made-up"}); err == nil {
+		t.Error("expected an error for an issue type without a plan")
+	}
+}
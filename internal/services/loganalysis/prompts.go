@@ -2,45 +2,174 @@ package loganalysis
 
 import (
 	"fmt"
+	"log"
+	"slices"
 	"strings"
 
 	types "github.com/rk280392/harvesterNavigator/internal/models"
 )
 
+// DefaultPromptTokens is the prompt budget of a provider that sets none,
+// small enough for the context window of a local model
+const DefaultPromptTokens = 8000
+
+// promptSection is a part of the prompt that is left out when it does not
+// fit the budget
+type promptSection struct {
+	name  string
+	lines []string
+}
+
+// sectionPriority orders the sections for the budget. The structured cluster
+// state is the primary evidence, so it comes before the logs.
+var sectionPriority = []string{"volume", "node-disk", "replicas", "engines", "attachment", "migration", "pods", "issue-context"}
+
+// BuildAnalysisPrompt returns the whole prompt for an issue, without logs
 func BuildAnalysisPrompt(req types.LogAnalysisRequest) string {
-	var parts []string
+	head, sections, task := analysisPrompt(req)
+	parts := head
+	for _, section := range sections {
+		parts = append(parts, section.lines...)
+	}
+	return strings.Join(append(parts, task...), "\n")
+}
+
+// BuildPrompt assembles the prompt for an issue and its collected logs
+// within maxTokens, or without a limit when maxTokens is 0. The issue
+// details and task are always sent; the structured sections follow in
+// sectionPriority order while they fit, and the logs, condensed by message
+// template, fill the rest newest first.
+func BuildPrompt(req types.LogAnalysisRequest, logs string, maxTokens int) *types.PromptInfo {
+	head, sections, task := analysisPrompt(req)
+	info := &types.PromptInfo{Budget: maxTokens, Sections: []string{}}
+
+	remaining := maxTokens - estimateTokens(strings.Join(head, "\n")+strings.Join(task, "\n")) - estimateTokens(logsHeading)
+	included := make(map[string]bool)
+	for _, name := range sectionPriority {
+		for _, section := range sections {
+			if section.name != name {
+				continue
+			}
+			cost := estimateTokens(strings.Join(section.lines, "\n"))
+			if maxTokens > 0 && cost > remaining {
+				info.DroppedSections = append(info.DroppedSections, name)
+				continue
+			}
+			remaining -= cost
+			included[name] = true
+		}
+	}
 
-	parts = append(parts, "You are analyzing a Harvester Kubernetes cluster issue.")
-	parts = append(parts, "Harvester is a hyperconverged infrastructure built on Kubernetes, KubeVirt, and Longhorn storage.")
-	parts = append(parts, "")
+	parts := head
+	for _, section := range sections {
+		if included[section.name] {
+			parts = append(parts, section.lines...)
+			info.Sections = append(info.Sections, section.name)
+		}
+	}
+	if logs != "" {
+		logBudget := 0
+		if maxTokens > 0 {
+			// Even an exhausted budget keeps a line, as a sign of the logs
+			logBudget = max(remaining, 1)
+		}
+		condensed, kept, omitted := condenseLogs(logs, logBudget)
+		parts = append(parts, logsHeading, condensed, "")
+		info.LogLines, info.OmittedLogLines = kept, omitted
+	}
+	parts = append(parts, task...)
+
+	info.Text = strings.Join(parts, "\n")
+	info.EstimatedTokens = estimateTokens(info.Text)
+	if maxTokens > 0 && info.EstimatedTokens > maxTokens {
+		log.Printf("Warning: Prompt of %d tokens exceeds the budget of %d", info.EstimatedTokens, maxTokens)
+	}
+	return info
+}
+
+const logsHeading = "RELEVANT LOGS:"
+
+// dropLogLines removes log lines from the top of a built prompt's logs until
+// about tokens are freed. Headings and collection notes are kept.
+func dropLogLines(prompt string, tokens int) string {
+	lines := strings.Split(prompt, "\n")
+	start := slices.Index(lines, logsHeading)
+	if tokens <= 0 || start == -1 {
+		return prompt
+	}
+	end := start + 1 + slices.Index(lines[start+1:], promptTask[0])
+	if end == start {
+		end = len(lines)
+	}
+
+	// Counted in characters, as estimateTokens rounds each line up
+	chars := tokens*4 + len(fmt.Sprintf(retryOmittedNote, len(lines))) + 1
+	kept := []string{logsHeading, ""}
+	dropped := 0
+	for _, line := range lines[start+1 : end] {
+		if chars > 0 && line != "" && !strings.HasPrefix(line, "=== ") && !strings.HasPrefix(line, "(") {
+			chars -= len(line) + 1
+			dropped++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if chars > 0 {
+		log.Printf("Warning: Retry prompt exceeds the budget by ~%d tokens with every log line left out", (chars+3)/4)
+	}
+	if dropped == 0 {
+		return prompt
+	}
+	kept[1] = fmt.Sprintf(retryOmittedNote, dropped)
+
+	parts := append(append(append([]string(nil), lines[:start]...), kept...), lines[end:]...)
+	return strings.Join(parts, "\n")
+}
+
+const retryOmittedNote = "(%d more log lines left out to fit the re-ask in the prompt budget)"
+
+// estimateTokens approximates the tokens of English text and logs at four
+// characters each
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// analysisPrompt splits the prompt for an issue into the issue details, the
+// optional sections and the task, in prompt order
+func analysisPrompt(req types.LogAnalysisRequest) (head []string, sections []promptSection, task []string) {
+	head = append(head, promptPreamble...)
 
 	// Issue details
-	parts = append(parts, "ISSUE DETAILS:")
-	parts = append(parts, fmt.Sprintf("- Issue Type: %s", req.IssueType))
+	head = append(head, "ISSUE DETAILS:")
+	head = append(head, fmt.Sprintf("- Issue Type: %s", req.IssueType))
 
 	if req.VMName != "" {
-		parts = append(parts, fmt.Sprintf("- Affected VM: %s", req.VMName))
+		head = append(head, fmt.Sprintf("- Affected VM: %s", req.VMName))
 	}
 
 	if req.Namespace != "" {
-		parts = append(parts, fmt.Sprintf("- Namespace: %s", req.Namespace))
+		head = append(head, fmt.Sprintf("- Namespace: %s", req.Namespace))
 	}
 
 	if req.SourceNode != "" {
-		parts = append(parts, fmt.Sprintf("- Source Node: %s", req.SourceNode))
+		head = append(head, fmt.Sprintf("- Source Node: %s", req.SourceNode))
 	}
 
 	if req.TargetNode != "" {
-		parts = append(parts, fmt.Sprintf("- Target Node: %s", req.TargetNode))
+		head = append(head, fmt.Sprintf("- Target Node: %s", req.TargetNode))
 	}
 
 	if req.TimeWindow != "" {
-		parts = append(parts, fmt.Sprintf("- Time Window: Last %s", req.TimeWindow))
+		head = append(head, fmt.Sprintf("- Time Window: Last %s", req.TimeWindow))
+	}
+	if req.LogError != "" {
+		head = append(head, fmt.Sprintf("- Logs: none collected (%s); rely on the cluster state", req.LogError))
 	}
+	head = append(head, "")
 
 	// Add structured data if available
 	if req.VolumeName != "" {
-		parts = append(parts, "VOLUME STATUS:")
+		parts := []string{"VOLUME STATUS:"}
 		parts = append(parts, fmt.Sprintf("- Volume: %s", req.VolumeName))
 		if req.VolumeRobustness != "" {
 			parts = append(parts, fmt.Sprintf("- Robustness: %s", req.VolumeRobustness))
@@ -52,12 +181,12 @@ func BuildAnalysisPrompt(req types.LogAnalysisRequest) string {
 			parts = append(parts, fmt.Sprintf("- Total Replicas: %d", req.ReplicaCount))
 			parts = append(parts, fmt.Sprintf("- Faulted Replicas: %d", req.FaultedCount))
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"volume", append(parts, "")})
 	}
 
 	// Add node disk status for DiskPressure detection
 	if len(req.NodeDiskStatus) > 0 {
-		parts = append(parts, "NODE DISK STATUS:")
+		parts := []string{"NODE DISK STATUS:"}
 		for _, node := range req.NodeDiskStatus {
 			status := "OK"
 			if node.HasDiskPressure {
@@ -66,12 +195,12 @@ func BuildAnalysisPrompt(req types.LogAnalysisRequest) string {
 			parts = append(parts, fmt.Sprintf("- %s: %s (Scheduled: %s, Max: %s, Available: %s)",
 				node.NodeName, status, node.StorageScheduled, node.StorageMaximum, node.StorageAvailable))
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"node-disk", append(parts, "")})
 	}
 
 	// Add replica details for location and failure analysis
 	if len(req.ReplicaDetails) > 0 {
-		parts = append(parts, "REPLICA DETAILS:")
+		parts := []string{"REPLICA DETAILS:"}
 		for _, replica := range req.ReplicaDetails {
 			startedStr := "stopped"
 			if replica.Started {
@@ -80,66 +209,96 @@ func BuildAnalysisPrompt(req types.LogAnalysisRequest) string {
 			parts = append(parts, fmt.Sprintf("- %s on %s: state=%s, %s",
 				replica.Name, replica.NodeName, replica.State, startedStr))
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"replicas", append(parts, "")})
+	}
+
+	if len(req.EngineDetails) > 0 {
+		parts := []string{"ENGINE DETAILS:"}
+		for _, engine := range req.EngineDetails {
+			parts = append(parts, fmt.Sprintf("- %s on %s: state=%s, active=%t",
+				engine.Name, engine.NodeName, engine.State, engine.Active))
+		}
+		sections = append(sections, promptSection{"engines", append(parts, "")})
 	}
 
 	// Add pod distribution for split-brain detection
 	if len(req.PodDistribution) > 0 {
-		parts = append(parts, "POD DISTRIBUTION:")
+		parts := []string{"POD DISTRIBUTION:"}
 		for _, pod := range req.PodDistribution {
 			parts = append(parts, fmt.Sprintf("- %s on %s (phase: %s)",
 				pod.PodName, pod.NodeName, pod.Phase))
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"pods", append(parts, "")})
 	}
 
 	// Add attachment state for CSI layer analysis
 	if req.AttachmentState != nil {
-		parts = append(parts, "ATTACHMENT STATE:")
+		parts := []string{"ATTACHMENT STATE:"}
 		parts = append(parts, fmt.Sprintf("- Current Node: %s", req.AttachmentState.CurrentNodeID))
 		if req.AttachmentState.DesiredNodeID != "" {
 			parts = append(parts, fmt.Sprintf("- Desired Node: %s", req.AttachmentState.DesiredNodeID))
 		}
 		parts = append(parts, fmt.Sprintf("- Longhorn Attached: %t", req.AttachmentState.LonghornAttached))
+		for _, attachment := range req.AttachmentState.CSIAttachments {
+			parts = append(parts, fmt.Sprintf("- CSI VolumeAttachment: %s", attachment))
+		}
 		if req.AttachmentState.HasConflict {
 			parts = append(parts, "- WARNING: CSI attachment conflict detected")
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"attachment", append(parts, "")})
 	}
 
 	// Add migration state for dangling migration detection
 	if req.MigrationState != nil && req.MigrationState.CurrentMigrationNodeID != "" {
-		parts = append(parts, "MIGRATION STATE:")
+		parts := []string{"MIGRATION STATE:"}
 		parts = append(parts, fmt.Sprintf("- Migration Node ID: %s", req.MigrationState.CurrentMigrationNodeID))
 		if req.MigrationState.IsDangling {
 			parts = append(parts, "- WARNING: Dangling migration state detected")
 		}
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"migration", append(parts, "")})
 	}
 
-	parts = append(parts, "")
 	if issueContext := getIssueTypeContext(req.IssueType); issueContext != "" {
-		parts = append(parts, issueContext)
-		parts = append(parts, "")
+		sections = append(sections, promptSection{"issue-context", []string{strings.TrimPrefix(issueContext, "\n"), ""}})
 	}
 
 	// What we want from the analysis
-	parts = append(parts, "TASK:")
-	parts = append(parts, "Analyze the above and respond with ONLY valid JSON, no markdown, no explanation:")
-	parts = append(parts, `{`)
-	parts = append(parts, `  "root_cause": "one sentence describing the root cause",`)
-	parts = append(parts, `  "error_lines": [],`)
-	parts = append(parts, `  "failing_component": "specific component name",`)
-	parts = append(parts, `  "recommended_action": "one sentence next step",`)
-	parts = append(parts, `  "confidence": "high|medium|low"`)
-	parts = append(parts, `}`)
+	task = append(task, promptTask...)
 
-	return strings.Join(parts, "\n")
+	return head, sections, task
+}
+
+// promptPreamble opens every prompt with the model's role
+var promptPreamble = []string{
+	"You are analyzing a Harvester Kubernetes cluster issue.",
+	"Harvester is a hyperconverged infrastructure built on Kubernetes, KubeVirt, and Longhorn storage.",
+	"",
+}
+
+// promptTask closes every prompt with what we want from the analysis
+var promptTask = []string{
+	"TASK:",
+	"Analyze the above and respond with ONLY valid JSON, no markdown, no explanation:",
+	`{`,
+	`  "root_cause": "one sentence describing the root cause",`,
+	`  "error_lines": [],`,
+	`  "failing_component": "specific component name",`,
+	`  "recommended_action": "one sentence next step",`,
+	`  "confidence": "high|medium|low"`,
+	`}`,
+}
+
+// promptEvidence returns a built prompt without its preamble and task, for
+// providers that send their own instructions as a system message
+func promptEvidence(prompt string) string {
+	prompt = strings.TrimPrefix(prompt, strings.Join(promptPreamble, "\n")+"\n")
+	// A re-ask follows the task with the rejected reply
+	return strings.Replace(prompt, "\n"+strings.Join(promptTask, "\n"), "", 1)
 }
 
 func getIssueTypeContext(issueType string) string {
 	switch issueType {
-	case "vm-migration-stuck":
+	case "vm-migration-stuck", "migration-stuck", "migration-scheduling-failed", "migration-affinity-mismatch":
 		return `
 ISSUE CONTEXT:
 VM live migration involves:
@@ -148,7 +307,7 @@ VM live migration involves:
 - Storage volume attachment/detachment via Longhorn CSI
 Common causes: Network connectivity, libvirt socket issues, volume attachment conflicts`
 
-	case "volume-attachment-conflict":
+	case "volume-attachment-conflict", "attachment-tickets-unsatisfied", "attachment-condition-failed":
 		return `
 ISSUE CONTEXT:
 Volume attachment tickets track CSI volume operations.
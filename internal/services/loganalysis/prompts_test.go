@@ -0,0 +1,69 @@
+package loganalysis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func TestBuildPrompt_Budget(t *testing.T) {
+	req := types.LogAnalysisRequest{
+		IssueType:        "replica-faulted",
+		VMName:           "vm1",
+		VolumeName:       volumeA,
+		VolumeRobustness: "degraded",
+		ReplicaDetails:   []types.ReplicaDetail{{Name: volumeA + "-r-1", NodeName: "node-a", State: "error"}},
+	}
+
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, fmt.Sprintf(`time="2024-03-02T12:%02d:%02dZ" level=error msg="event %d: replica %s-r-%d unreachable at 10.52.0.%d:%d" volume=%s`,
+			i/60, i%60, i, volumeA, i, i%250, 10000+i, volumeA))
+		lines = append(lines, fmt.Sprintf(`time="2024-03-02T12:%02d:%02dZ" level=info msg="sync %d done"`, i/60, i%60, i))
+	}
+	logs := "=== longhorn-manager: longhorn-manager-abc on node-a ===\n" + strings.Join(lines, "\n")
+
+	unlimited := BuildPrompt(req, logs, 0)
+	if unlimited.OmittedLogLines != 0 || unlimited.LogLines != 2 {
+		t.Errorf("unlimited prompt kept %d lines and left out %d, want the 400 lines folded into 2 templates",
+			unlimited.LogLines, unlimited.OmittedLogLines)
+	}
+	if !strings.Contains(unlimited.Text, "(200 similar lines until 2024-03-02T12:03:19Z)") {
+		t.Errorf("expected the repeated lines counted in:\n%s", unlimited.Text)
+	}
+
+	// Distinct messages cannot be folded, so only the newest fit
+	for i := range lines {
+		lines[i] = fmt.Sprintf(`time="2024-03-02T12:%02d:%02dZ" level=error msg="failure %c%c"`, i/2/60, i/2%60, 'a'+i%26, 'a'+i/26)
+	}
+	logs = strings.Join(lines, "\n")
+	prompt := BuildPrompt(req, logs, 600)
+	if prompt.EstimatedTokens > 600 {
+		t.Errorf("prompt of %d tokens exceeds the budget", prompt.EstimatedTokens)
+	}
+	if prompt.OmittedLogLines == 0 || prompt.LogLines == 0 || prompt.LogLines+prompt.OmittedLogLines != len(lines) {
+		t.Errorf("kept %d and left out %d lines", prompt.LogLines, prompt.OmittedLogLines)
+	}
+	if !strings.Contains(prompt.Text, "12:03:19Z") || strings.Contains(prompt.Text, "12:00:00Z") {
+		t.Error("expected the newest lines kept and the oldest left out")
+	}
+	if strings.Join(prompt.Sections, ",") != "volume,replicas" || strings.Join(prompt.DroppedSections, ",") != "issue-context" {
+		t.Errorf("sections = %v, dropped = %v, want the long issue context dropped first", prompt.Sections, prompt.DroppedSections)
+	}
+	if !strings.HasSuffix(prompt.Text, "}") || !strings.Contains(prompt.Text, "ISSUE DETAILS:") {
+		t.Error("expected the issue details and task always sent")
+	}
+}
+
+func TestBuildPrompt_LogError(t *testing.T) {
+	req := types.LogAnalysisRequest{IssueType: "vm-pending", VMName: "vm1", LogError: "no logs collected"}
+	prompt := BuildPrompt(req, "", 0)
+	if !strings.Contains(prompt.Text, "- Logs: none collected (no logs collected)") {
+		t.Errorf("expected a note about the failed collection in:\n%s", prompt.Text)
+	}
+	if strings.Contains(prompt.Text, logsHeading) || prompt.LogLines != 0 {
+		t.Errorf("expected no log section for a failed collection:\n%s", prompt.Text)
+	}
+}
@@ -0,0 +1,388 @@
+package loganalysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderConfig configures a named LLM provider. Secrets are not written in
+// the config: APIKeyEnv or APIKeyFile says where to read the API key, e.g. a
+// mounted Kubernetes Secret.
+type ProviderConfig struct {
+	Name         string        `yaml:"name"`
+	Type         string        `yaml:"type"` // gemini, ollama, openwebui, openai or stub
+	BaseURL      string        `yaml:"base_url"`
+	BasePath     string        `yaml:"base_path"` // OpenAI-compatible API path, /v1 by default
+	Model        string        `yaml:"model"`
+	APIKeyEnv    string        `yaml:"api_key_env"`
+	APIKeyFile   string        `yaml:"api_key_file"`
+	CollectionID string        `yaml:"collection_id"` // OpenWebUI knowledge collection
+	JSONSchema   bool          `yaml:"json_schema"`   // request structured output from an OpenAI-compatible server
+	Timeout      time.Duration `yaml:"timeout"`       // 0 for no limit
+	Cost         *CostTable    `yaml:"cost"`          // nil for the provider's default
+	Retries      *int          `yaml:"retries"`       // re-asks after an invalid answer, nil for DefaultRetries
+	PromptTokens int           `yaml:"prompt_tokens"` // prompt budget, 0 for DefaultPromptTokens
+}
+
+// CostTable prices the tokens of a provider in USD per million
+type CostTable struct {
+	InputPerMillion  float64 `yaml:"input_per_million" json:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million" json:"output_per_million"`
+}
+
+// Estimate returns the cost of one call
+func (c CostTable) Estimate(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*c.InputPerMillion + float64(outputTokens)*c.OutputPerMillion) / 1000000
+}
+
+// PerThousand averages the input and output price per 1000 tokens, as
+// LogAnalyzer.EstimatedCost reports it
+func (c CostTable) PerThousand() float64 {
+	return (c.InputPerMillion + c.OutputPerMillion) / 2 / 1000
+}
+
+// ProvidersFile is the YAML layout of a provider config file:
+//
+//	default: local
+//	providers:
+//	  - name: local
+//	    type: ollama
+//	    base_url: http://ollama.internal:11434
+//	    model: mixtral:8x7b
+//	    timeout: 2m
+type ProvidersFile struct {
+	Default   string           `yaml:"default"`
+	Providers []ProviderConfig `yaml:"providers"`
+}
+
+// LoadProvidersFile reads a provider config file. Unknown keys are rejected.
+func LoadProvidersFile(path string) (*ProvidersFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider config: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var file ProvidersFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid provider config %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// EnvProviders returns the providers configured by environment variables,
+// for when no config file is given: Gemini (GEMINI_API_KEY), Ollama
+// (OLLAMA_URL, OLLAMA_MODEL), OpenWebUI (OPENWEBUI_URL, OPENWEBUI_API_KEY,
+// OPENWEBUI_MODEL, OPENWEBUI_COLLECTION_ID) and the stub
+func EnvProviders() *ProvidersFile {
+	return &ProvidersFile{
+		Default: "gemini",
+		Providers: []ProviderConfig{
+			{Name: "gemini", Type: "gemini", Model: "gemini-2.5-flash", APIKeyEnv: "GEMINI_API_KEY"},
+			{Name: "ollama", Type: "ollama", BaseURL: envOr("OLLAMA_URL", "http://localhost:11434"), Model: envOr("OLLAMA_MODEL", "mixtral:8x7b")},
+			{Name: "openwebui", Type: "openwebui", BaseURL: os.Getenv("OPENWEBUI_URL"), Model: envOr("OPENWEBUI_MODEL", "qwen3:latest"),
+				APIKeyEnv: "OPENWEBUI_API_KEY", CollectionID: os.Getenv("OPENWEBUI_COLLECTION_ID")},
+			{Name: "stub", Type: "stub"},
+		},
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Pinger is implemented by analyzers that can check their backend is
+// reachable without running an analysis
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Provider is a configured LLM provider. It is a LogAnalyzer that applies
+// the configured timeout to every analysis.
+type Provider struct {
+	config   ProviderConfig
+	analyzer LogAnalyzer // nil when the provider could not be built
+
+	mu        sync.RWMutex
+	err       error
+	checkedAt time.Time
+}
+
+// ProviderInfo describes a provider for /api/providers
+type ProviderInfo struct {
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Model        string    `json:"model,omitempty"`
+	Default      bool      `json:"default"`
+	Available    bool      `json:"available"`
+	Error        string    `json:"error,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Cost         float64   `json:"cost_per_1k_tokens"`
+	PromptTokens int       `json:"prompt_tokens,omitempty"`
+}
+
+func (p *Provider) Analyze(ctx context.Context, prompt string) (*types.LogAnalysisResult, error) {
+	if p.analyzer == nil {
+		return nil, fmt.Errorf("provider %s is not available: %w", p.config.Name, p.lastError())
+	}
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+	return p.analyzer.Analyze(ctx, prompt)
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// Type is the provider's backend: gemini, ollama, openwebui, openai or stub
+func (p *Provider) Type() string {
+	return p.config.Type
+}
+
+// PromptTokens is the token budget of the prompts sent to the provider
+func (p *Provider) PromptTokens() int {
+	return p.config.promptTokens()
+}
+
+func (p *Provider) EstimatedCost() float64 {
+	if p.analyzer == nil {
+		return 0
+	}
+	return p.analyzer.EstimatedCost()
+}
+
+// check pings the provider and records the outcome
+func (p *Provider) check(ctx context.Context) {
+	if p.analyzer == nil {
+		return
+	}
+	var err error
+	if pinger, ok := p.analyzer.(Pinger); ok {
+		ctx, cancel := context.WithTimeout(ctx, providerPingTimeout)
+		err = pinger.Ping(ctx)
+		cancel()
+	}
+	p.mu.Lock()
+	p.err, p.checkedAt = err, time.Now()
+	p.mu.Unlock()
+}
+
+func (p *Provider) lastError() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.err
+}
+
+// providerPingTimeout bounds each health check
+const providerPingTimeout = 10 * time.Second
+
+// ProviderRegistry holds the providers built at startup, in config order
+type ProviderRegistry struct {
+	providers   []*Provider
+	defaultName string
+}
+
+// NewProviderRegistry builds every provider once. A provider that cannot be
+// built, e.g. for a missing API key, is kept and reported as unavailable.
+// Duplicate or unknown names in the config are errors.
+func NewProviderRegistry(ctx context.Context, file *ProvidersFile) (*ProviderRegistry, error) {
+	r := &ProviderRegistry{defaultName: file.Default}
+	seen := make(map[string]bool)
+	for _, cfg := range file.Providers {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("provider name is required")
+		}
+		if seen[cfg.Name] {
+			return nil, fmt.Errorf("provider %q: duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = true
+		switch cfg.Type {
+		case "gemini", "ollama", "openwebui", "openai", "stub":
+		default:
+			return nil, fmt.Errorf("provider %q: unknown type %q (use gemini, ollama, openwebui, openai or stub)", cfg.Name, cfg.Type)
+		}
+		if cfg.Retries != nil && *cfg.Retries < 0 {
+			return nil, fmt.Errorf("provider %q: retries must not be negative", cfg.Name)
+		}
+		if cfg.PromptTokens < 0 {
+			return nil, fmt.Errorf("provider %q: prompt_tokens must not be negative", cfg.Name)
+		}
+
+		p := &Provider{config: cfg}
+		analyzer, err := buildAnalyzer(ctx, cfg)
+		if err != nil {
+			log.Printf("Warning: LLM provider %s is unavailable: %v", cfg.Name, err)
+			p.err = err
+		} else {
+			p.analyzer = analyzer
+		}
+		r.providers = append(r.providers, p)
+	}
+	if r.defaultName != "" && !seen[r.defaultName] {
+		return nil, fmt.Errorf("default provider %q is not configured", r.defaultName)
+	}
+	return r, nil
+}
+
+func buildAnalyzer(ctx context.Context, cfg ProviderConfig) (LogAnalyzer, error) {
+	apiKey, err := cfg.apiKey()
+	if err != nil {
+		return nil, err
+	}
+	var analyzer interface {
+		LogAnalyzer
+		options() *llmOptions
+	}
+	switch cfg.Type {
+	case "gemini":
+		analyzer, err = NewGeminiAnalyzer(ctx, apiKey, cfg.Model)
+	case "ollama":
+		analyzer, err = NewOllamaAnalyzer(cfg.BaseURL, cfg.Model)
+	case "openwebui":
+		analyzer, err = NewOpenwebuiAnalyzer(cfg.BaseURL, cfg.Model, apiKey, cfg.CollectionID)
+	case "openai":
+		analyzer, err = NewOpenAIAnalyzer(cfg.BaseURL, cfg.BasePath, cfg.Model, apiKey, cfg.JSONSchema)
+	default:
+		return NewStubAnalyzer(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Cost != nil {
+		analyzer.options().cost = *cfg.Cost
+	}
+	if cfg.Retries != nil {
+		analyzer.options().retries = *cfg.Retries
+	}
+	analyzer.options().promptTokens = cfg.promptTokens()
+	return analyzer, nil
+}
+
+// promptTokens is the prompt budget, DefaultPromptTokens when none is set
+func (cfg ProviderConfig) promptTokens() int {
+	if cfg.PromptTokens > 0 {
+		return cfg.PromptTokens
+	}
+	return DefaultPromptTokens
+}
+
+// apiKey reads the key from the file or environment variable, if one is set
+func (cfg ProviderConfig) apiKey() (string, error) {
+	switch {
+	case cfg.APIKeyFile != "":
+		data, err := os.ReadFile(cfg.APIKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case cfg.APIKeyEnv != "":
+		key := os.Getenv(cfg.APIKeyEnv)
+		if key == "" {
+			return "", fmt.Errorf("%s is not set", cfg.APIKeyEnv)
+		}
+		return key, nil
+	}
+	return "", nil
+}
+
+// Get returns the named provider, or the default one for an empty name
+func (r *ProviderRegistry) Get(name string) (*Provider, bool) {
+	if name == "" {
+		name = r.defaultName
+	}
+	for _, p := range r.providers {
+		if p.config.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Providers describes every provider, in config order
+func (r *ProviderRegistry) Providers() []ProviderInfo {
+	infos := make([]ProviderInfo, 0, len(r.providers))
+	for _, p := range r.providers {
+		p.mu.RLock()
+		info := ProviderInfo{
+			Name:         p.config.Name,
+			Type:         p.config.Type,
+			Model:        p.config.Model,
+			Default:      p.config.Name == r.defaultName,
+			Available:    p.analyzer != nil && p.err == nil,
+			CheckedAt:    p.checkedAt,
+			Cost:         p.EstimatedCost(),
+			PromptTokens: p.PromptTokens(),
+		}
+		if p.err != nil {
+			info.Error = p.err.Error()
+		}
+		p.mu.RUnlock()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// CheckHealth pings every built provider concurrently
+func (r *ProviderRegistry) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range r.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.check(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// Run checks the providers' health every interval until ctx is done
+func (r *ProviderRegistry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r.CheckHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pingURL GETs url, with a bearer token when apiKey is set, and expects 200
+func pingURL(ctx context.Context, url, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package loganalysis
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	var down bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" || down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_OPENWEBUI_KEY", "")
+	registry, err := NewProviderRegistry(context.Background(), &ProvidersFile{
+		Default: "local",
+		Providers: []ProviderConfig{
+			{Name: "local", Type: "ollama", BaseURL: server.URL, Model: "mixtral:8x7b", Cost: &CostTable{InputPerMillion: 1, OutputPerMillion: 3}},
+			{Name: "webui", Type: "openwebui", BaseURL: server.URL, APIKeyEnv: "TEST_OPENWEBUI_KEY"},
+			{Name: "stub", Type: "stub"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewProviderRegistry: %v", err)
+	}
+	registry.CheckHealth(context.Background())
+
+	byName := make(map[string]ProviderInfo)
+	for _, info := range registry.Providers() {
+		byName[info.Name] = info
+	}
+	if local := byName["local"]; !local.Available || !local.Default || local.Cost != 0.002 || local.CheckedAt.IsZero() {
+		t.Errorf("local = %+v, want available default provider costing 0.002 per 1k tokens", local)
+	}
+	if webui := byName["webui"]; webui.Available || !strings.Contains(webui.Error, "TEST_OPENWEBUI_KEY") {
+		t.Errorf("webui = %+v, want unavailable for the missing API key", webui)
+	}
+	if !byName["stub"].Available {
+		t.Errorf("stub = %+v, want available", byName["stub"])
+	}
+
+	if p, ok := registry.Get(""); !ok || p.Name() != "local" {
+		t.Errorf("Get(\"\") = %v, want the default provider", p)
+	}
+	if _, ok := registry.Get("missing"); ok {
+		t.Error("Get(\"missing\") found a provider")
+	}
+	p, _ := registry.Get("webui")
+	if _, err := p.Analyze(context.Background(), "prompt"); err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("Analyze on an unbuilt provider = %v, want not available", err)
+	}
+
+	down = true
+	registry.CheckHealth(context.Background())
+	if local, _ := registry.Get("local"); local.lastError() == nil {
+		t.Error("expected the failed health check to be recorded")
+	}
+}
+
+func TestNewProviderRegistry_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file ProvidersFile
+		want string
+	}{
+		{"unknown type", ProvidersFile{Providers: []ProviderConfig{{Name: "x", Type: "bard"}}}, "unknown type"},
+		{"duplicate name", ProvidersFile{Providers: []ProviderConfig{{Name: "x", Type: "stub"}, {Name: "x", Type: "stub"}}}, "duplicate"},
+		{"missing default", ProvidersFile{Default: "y", Providers: []ProviderConfig{{Name: "x", Type: "stub"}}}, "not configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProviderRegistry(context.Background(), &tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProvidersFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "providers.yaml")
+	config := `default: local
+providers:
+  - name: local
+    type: ollama
+    base_url: http://ollama:11434
+    timeout: 2m
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := LoadProvidersFile(path)
+	if err != nil {
+		t.Fatalf("LoadProvidersFile: %v", err)
+	}
+	if file.Default != "local" || len(file.Providers) != 1 || file.Providers[0].Timeout.Minutes() != 2 {
+		t.Errorf("file = %+v", file)
+	}
+
+	if err := os.WriteFile(path, []byte("providers:\n  - name: x\n    apikey: secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProvidersFile(path); err == nil {
+		t.Error("expected an unknown key to be rejected")
+	}
+}
@@ -0,0 +1,162 @@
+package loganalysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
+)
+
+// filterLogs keeps the lines that matter for an issue. Lines older than
+// opts.Since, measured back from the newest line, are dropped. With a scope,
+// lines about the scoped resources are kept, lines only about other volumes
+// are dropped, and lines about no particular resource are kept when they
+// look like errors or state changes.
+func filterLogs(logContent string, opts LogOptions) string {
+	records := logrecord.ParseAll(logContent)
+	var newest time.Time
+	for _, record := range records {
+		if record.Time.After(newest) {
+			newest = record.Time
+		}
+	}
+
+	var candidates []logrecord.Record
+	for _, record := range records {
+		if opts.Since > 0 && !record.Time.IsZero() && record.Time.Before(newest.Add(-opts.Since)) {
+			continue
+		}
+		if len(opts.Scope) > 0 && !record.Mentions(opts.Scope) && len(record.References()) > 0 {
+			continue
+		}
+		candidates = append(candidates, record)
+	}
+
+	var relevant []string
+	for _, record := range candidates {
+		if (len(opts.Scope) > 0 && record.Mentions(opts.Scope)) || isRelevantLine(record.Raw) {
+			relevant = append(relevant, record.Raw)
+		}
+	}
+	if len(relevant) > 0 {
+		return strings.Join(relevant, "\n")
+	}
+
+	// If no errors found, return the last 50 lines as fallback
+	if len(candidates) > 50 {
+		candidates = candidates[len(candidates)-50:]
+	}
+	fallback := make([]string, 0, len(candidates))
+	for _, record := range candidates {
+		fallback = append(fallback, record.Raw)
+	}
+	return strings.Join(fallback, "\n")
+}
+
+// CondenseLogs prepares collected logs for a prompt, without a size limit
+func CondenseLogs(logContent string) string {
+	condensed, _, _ := condenseLogs(logContent, 0)
+	return condensed
+}
+
+// condenseLogs prepares collected logs for a prompt. Within each pod's
+// section the lines are parsed, lines logged by the same statement are
+// folded into one with a count, and the rest are ordered by time. Headings
+// and collection notes are kept as they are. With maxTokens, the newest lines
+// are kept while they fit and the older ones are left out. It returns the
+// number of condensed lines kept and left out.
+func condenseLogs(logContent string, maxTokens int) (string, int, int) {
+	type entry struct {
+		text     string
+		last     time.Time
+		position int
+		keep     bool
+	}
+	type section struct {
+		heading string
+		entries []*entry
+	}
+
+	// Lines before the first heading form a section without one
+	sections := []*section{{}}
+	var lines []string
+	var all []*entry
+	flush := func() {
+		current := sections[len(sections)-1]
+		for _, g := range logrecord.CondenseTemplates(logrecord.ParseAll(strings.Join(lines, "\n"))) {
+			text := g.String()
+			if g.Count > 1 && !g.Last.IsZero() {
+				text += fmt.Sprintf(" (%d similar lines until %s)", g.Count, g.Last.UTC().Format(time.RFC3339))
+			} else if g.Count > 1 {
+				text += fmt.Sprintf(" (%d similar lines)", g.Count)
+			}
+			e := &entry{text: text, last: g.Last, position: len(all), keep: maxTokens == 0}
+			current.entries = append(current.entries, e)
+			all = append(all, e)
+		}
+		lines = lines[:0]
+	}
+	for _, line := range strings.Split(logContent, "\n") {
+		if strings.HasPrefix(line, "=== ") || (strings.HasPrefix(line, "(") && strings.HasSuffix(line, ")")) {
+			flush()
+			sections = append(sections, &section{heading: line})
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	kept, omitted := len(all), 0
+	if maxTokens > 0 {
+		// Headings and the omission note are always sent
+		used := estimateTokens(omittedNote)
+		for _, s := range sections {
+			used += estimateTokens(s.heading) + 1
+		}
+		newest := append([]*entry(nil), all...)
+		sort.SliceStable(newest, func(i, j int) bool {
+			if !newest[i].last.Equal(newest[j].last) {
+				return newest[i].last.After(newest[j].last)
+			}
+			return newest[i].position > newest[j].position
+		})
+		kept = 0
+		for _, e := range newest {
+			used += estimateTokens(e.text) + 1
+			if used > maxTokens {
+				break
+			}
+			e.keep = true
+			kept++
+		}
+		omitted = len(all) - kept
+	}
+
+	var out []string
+	for _, s := range sections {
+		var body []string
+		for _, e := range s.entries {
+			if e.keep {
+				body = append(body, e.text)
+			}
+		}
+		if len(s.entries) > 0 && len(body) == 0 {
+			continue
+		}
+		if s.heading != "" {
+			if len(out) > 0 {
+				out = append(out, "")
+			}
+			out = append(out, s.heading)
+		}
+		out = append(out, body...)
+	}
+	if omitted > 0 {
+		out = append(out, "", fmt.Sprintf(omittedNote, omitted))
+	}
+	return strings.Join(out, "\n"), kept, omitted
+}
+
+const omittedNote = "(%d older log lines left out to fit the prompt budget)"
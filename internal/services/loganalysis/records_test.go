@@ -0,0 +1,35 @@
+package loganalysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	volumeA = "pvc-11111111-2222-3333-4444-555555555555"
+	volumeB = "pvc-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+)
+
+func TestFilterLogs_WindowAndScope(t *testing.T) {
+	logs := strings.Join([]string{
+		`time="2024-03-02T10:00:00Z" level=error msg="old failure" volume=` + volumeA,
+		`time="2024-03-02T12:00:00Z" level=info msg="volume attached" volume=` + volumeA,
+		`time="2024-03-02T12:01:00Z" level=error msg="replica failed" volume=` + volumeB,
+		`time="2024-03-02T12:02:00Z" level=error msg="failed to sync node"`,
+		`time="2024-03-02T12:03:00Z" level=info msg="resync done"`,
+	}, "\n")
+
+	got := filterLogs(logs, LogOptions{Since: time.Hour, Scope: []string{volumeA}})
+	want := []string{"volume attached", "failed to sync node"}
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("expected %q in:\n%s", w, got)
+		}
+	}
+	for _, unwanted := range []string{"old failure", volumeB, "resync done"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("did not expect %q in:\n%s", unwanted, got)
+		}
+	}
+}
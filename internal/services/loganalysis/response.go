@@ -0,0 +1,158 @@
+package loganalysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// DefaultRetries is how often a model is re-asked after an invalid answer
+const DefaultRetries = 2
+
+// llmOptions are the settings shared by the LLM analyzers
+type llmOptions struct {
+	cost    CostTable
+	retries int
+	// promptTokens is the budget re-asks are kept within, 0 for no limit
+	promptTokens int
+}
+
+func (o *llmOptions) options() *llmOptions {
+	return o
+}
+
+// Completion is a model's raw reply to one prompt
+type Completion struct {
+	Text             string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// completeFunc sends one prompt to a model
+type completeFunc func(ctx context.Context, prompt string) (*Completion, error)
+
+// InvalidResponseError is returned when a model gave no valid analysis, even
+// after being re-asked with the validation error
+type InvalidResponseError struct {
+	Provider string
+	Attempts int
+	Raw      string // the last reply
+	Err      error
+}
+
+func (e *InvalidResponseError) Error() string {
+	return fmt.Sprintf("%s gave no valid analysis in %d attempts: %v", e.Provider, e.Attempts, e.Err)
+}
+
+func (e *InvalidResponseError) Unwrap() error {
+	return e.Err
+}
+
+// analysisSchema is the JSON schema of types.LogAnalysisResult as the model
+// fills it in, for servers that support structured output. It keeps to what
+// OpenAI's strict mode accepts, so the non-empty root cause is left to
+// ParseAnalysis.
+var analysisSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"root_cause":         map[string]any{"type": "string"},
+		"error_lines":        map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"failing_component":  map[string]any{"type": "string"},
+		"recommended_action": map[string]any{"type": "string"},
+		"confidence":         map[string]any{"type": "string", "enum": []string{"high", "medium", "low"}},
+	},
+	"required":             []string{"root_cause", "error_lines", "failing_component", "recommended_action", "confidence"},
+	"additionalProperties": false,
+}
+
+// analyzeWithRetries asks the model for an analysis and validates the reply.
+// An invalid reply is sent back with the validation error, up to retries
+// times within the prompt budget; failed API calls are not retried. Token
+// usage and cost cover every attempt.
+func analyzeWithRetries(ctx context.Context, provider string, complete completeFunc, prompt string, opts *llmOptions) (*types.LogAnalysisResult, error) {
+	var usage Completion
+	current := prompt
+	attempts := opts.retries + 1
+	for attempt := 1; ; attempt++ {
+		reply, err := complete(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		usage.PromptTokens += reply.PromptTokens
+		usage.CompletionTokens += reply.CompletionTokens
+		if reply.TotalTokens == 0 {
+			reply.TotalTokens = reply.PromptTokens + reply.CompletionTokens
+		}
+		usage.TotalTokens += reply.TotalTokens
+
+		result, err := ParseAnalysis(reply.Text)
+		if err == nil {
+			result.Provider = provider
+			result.TokensUsed = usage.TotalTokens
+			result.EstimatedCost = opts.cost.Estimate(usage.PromptTokens, usage.CompletionTokens)
+			return result, nil
+		}
+		log.Printf("Warning: %s returned an invalid analysis (attempt %d of %d): %v", provider, attempt, attempts, err)
+		if attempt == attempts {
+			return nil, &InvalidResponseError{Provider: provider, Attempts: attempts, Raw: reply.Text, Err: err}
+		}
+		current = retryPrompt(prompt, reply.Text, err, opts.promptTokens)
+	}
+}
+
+// retryPrompt repeats the prompt with the rejected reply and why it was
+// rejected. With maxTokens, the oldest log lines make room for the reply.
+func retryPrompt(prompt, reply string, err error, maxTokens int) string {
+	correction := fmt.Sprintf("\n\nYOUR PREVIOUS REPLY:\n%s\n\nThat reply was rejected: %v\n"+
+		"Reply again with ONLY the corrected JSON object.", reply, err)
+	if maxTokens > 0 {
+		prompt = dropLogLines(prompt, estimateTokens(prompt)+estimateTokens(correction)-maxTokens)
+	}
+	return prompt + correction
+}
+
+// ParseAnalysis extracts the analysis from a model reply and validates it:
+// root_cause must be set and confidence must be high, medium or low
+func ParseAnalysis(text string) (*types.LogAnalysisResult, error) {
+	raw := extractJSON(text)
+	var result types.LogAnalysisResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		// Models often escape underscores and dots in markdown style
+		if json.Unmarshal([]byte(sanitizeEscapes(raw)), &result) != nil {
+			return nil, fmt.Errorf("reply is not a JSON object of the requested fields: %w", err)
+		}
+	}
+
+	result.Confidence = strings.ToLower(strings.TrimSpace(result.Confidence))
+	switch {
+	case strings.TrimSpace(result.RootCause) == "":
+		return nil, fmt.Errorf("root_cause is empty")
+	case result.Confidence != "high" && result.Confidence != "medium" && result.Confidence != "low":
+		return nil, fmt.Errorf("confidence is %q, must be high, medium or low", result.Confidence)
+	}
+	return &result, nil
+}
+
+// extractJSON returns the JSON object in a model reply, which may be wrapped
+// in a markdown code block or surrounded by text
+func extractJSON(text string) string {
+	if start := strings.Index(text, "```json"); start != -1 {
+		if end := strings.LastIndex(text, "```"); end > start {
+			return strings.TrimSpace(text[start+7 : end])
+		}
+	}
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start != -1 && end > start {
+		return text[start : end+1]
+	}
+	return strings.TrimSpace(text)
+}
+
+func sanitizeEscapes(s string) string {
+	return strings.NewReplacer(`\_`, "_", `\-`, "-", `\.`, ".").Replace(s)
+}
@@ -0,0 +1,117 @@
+package loganalysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func TestParseAnalysis(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr string
+	}{
+		{"plain", `{"root_cause": "disk full", "confidence": "high"}`, ""},
+		{"markdown", "Here it is:\n```json\n{\"root_cause\": \"disk full\", \"confidence\": \"Medium\"}\n```", ""},
+		{"escaped underscores", `{"root\_cause": "disk full", "confidence": "low"}`, ""},
+		{"empty root cause", `{"root_cause": " ", "confidence": "high"}`, "root_cause is empty"},
+		{"bad confidence", `{"root_cause": "disk full", "confidence": "certain"}`, `confidence is "certain"`},
+		{"not json", "I could not determine the cause.", "not a JSON object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseAnalysis(tt.reply)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ParseAnalysis: %v", err)
+				}
+				if result.RootCause != "disk full" {
+					t.Errorf("root_cause = %q", result.RootCause)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWithRetries(t *testing.T) {
+	var prompts []string
+	replies := []string{`{"root_cause": "", "confidence": "high"}`, `{"root_cause": "disk full", "confidence": "high"}`}
+	complete := func(ctx context.Context, prompt string) (*Completion, error) {
+		prompts = append(prompts, prompt)
+		return &Completion{Text: replies[len(prompts)-1], PromptTokens: 100, CompletionTokens: 10}, nil
+	}
+
+	opts := &llmOptions{cost: CostTable{InputPerMillion: 1000, OutputPerMillion: 1000}, retries: 1}
+	result, err := analyzeWithRetries(context.Background(), "test", complete, "ISSUE", opts)
+	if err != nil {
+		t.Fatalf("analyzeWithRetries: %v", err)
+	}
+	if result.Provider != "test" || result.TokensUsed != 220 || result.EstimatedCost != 0.22 {
+		t.Errorf("result = %+v, want usage of both attempts", result)
+	}
+	if len(prompts) != 2 || !strings.HasPrefix(prompts[1], "ISSUE") || !strings.Contains(prompts[1], "root_cause is empty") {
+		t.Errorf("retry prompt = %q, want the prompt with the validation error", prompts[len(prompts)-1])
+	}
+
+	prompts, replies = nil, []string{"no idea", "still no idea"}
+	_, err = analyzeWithRetries(context.Background(), "test", complete, "ISSUE", opts)
+	var invalid *InvalidResponseError
+	if !errors.As(err, &invalid) || invalid.Attempts != 2 || invalid.Raw != "still no idea" {
+		t.Errorf("err = %v, want an InvalidResponseError with the last reply", err)
+	}
+
+	failed := errors.New("connection refused")
+	_, err = analyzeWithRetries(context.Background(), "test", func(ctx context.Context, prompt string) (*Completion, error) {
+		return nil, failed
+	}, "ISSUE", opts)
+	if !errors.Is(err, failed) || errors.As(err, &invalid) {
+		t.Errorf("err = %v, want the API error unretried", err)
+	}
+}
+
+func TestRetryPrompt_Budget(t *testing.T) {
+	var logs []string
+	for i := 0; i < 200; i++ {
+		logs = append(logs, fmt.Sprintf("level=info msg=\"step %d of the rebuild\" file=\"step%d.go:1\" volume=pvc-1", i, i))
+	}
+	req := types.LogAnalysisRequest{IssueType: "replica-faulted", VolumeName: "pvc-1"}
+	prompt := BuildPrompt(req, "=== Longhorn Manager: longhorn-manager-a ===\n"+strings.Join(logs, "\n"), 1000).Text
+	reply := strings.Repeat("I think the disk is full. ", 40)
+
+	retry := retryPrompt(prompt, reply, errors.New("reply is not a JSON object"), 1000)
+	if tokens := estimateTokens(retry); tokens > 1000 {
+		t.Errorf("retry prompt of %d tokens exceeds the budget of 1000", tokens)
+	}
+	for _, want := range []string{"VOLUME STATUS:", "=== Longhorn Manager: longhorn-manager-a ===", "left out to fit the re-ask", "step 199 of", "TASK:", reply} {
+		if !strings.Contains(retry, want) {
+			t.Errorf("retry prompt is missing %q:\n%s", want, retry)
+		}
+	}
+
+	if unlimited := retryPrompt(prompt, reply, errors.New("invalid"), 0); !strings.HasPrefix(unlimited, prompt) {
+		t.Error("retry prompt without a budget should repeat the whole prompt")
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := map[string]string{
+		`{"a":1}`:                         `{"a":1}`,
+		"```json\n{\"a\":1}\n```":         `{"a":1}`,
+		"Sure! {\"a\":{\"b\":2}} Thanks.": `{"a":{"b":2}}`,
+		"no json here":                    "no json here",
+	}
+	for in, want := range tests {
+		if got := extractJSON(in); got != want {
+			t.Errorf("extractJSON(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package logrecord
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Group is a run of records folded into one, the first standing for the rest
+type Group struct {
+	Record
+	Count int
+	// Last is the time of the newest record in the group; Record.Time is the oldest
+	Last time.Time
+}
+
+// Condense de-duplicates records and orders them by time. Records with the
+// same level, component, message and fields, apart from their time, are
+// folded into one group. Records without a time keep their position after
+// the record before them.
+func Condense(records []Record) []Group {
+	return condense(records, Record.dedupKey)
+}
+
+// CondenseTemplates is Condense with records grouped by level, component
+// and message template instead, so lines logged by the same statement about
+// different objects or values fold into one group
+func CondenseTemplates(records []Record) []Group {
+	return condense(records, Record.templateKey)
+}
+
+func condense(records []Record, keyOf func(Record) string) []Group {
+	index := make(map[string]int)
+	var groups []Group
+	var previous time.Time
+	for _, record := range records {
+		if record.Time.IsZero() {
+			// Sort next to the preceding line
+			record.Time = previous
+		} else {
+			previous = record.Time
+		}
+
+		key := keyOf(record)
+		if i, ok := index[key]; ok {
+			g := &groups[i]
+			g.Count++
+			if record.Time.Before(g.Time) {
+				g.Time = record.Time
+			}
+			if record.Time.After(g.Last) {
+				g.Last = record.Time
+			}
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, Group{Record: record, Count: 1, Last: record.Time})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Time.Before(groups[j].Time)
+	})
+	return groups
+}
+
+// dedupKey identifies a record apart from its time
+func (r Record) dedupKey() string {
+	if r.Fields == nil {
+		return r.Raw
+	}
+	key := r.Level + "\x00" + r.Component + "\x00" + r.Message
+	for _, name := range sortedKeys(r.Fields) {
+		switch name {
+		case "time", "timestamp", "ts":
+			continue
+		}
+		key += "\x00" + name + "=" + r.Fields[name]
+	}
+	return key
+}
+
+// templateKey identifies the statement that logged a record
+func (r Record) templateKey() string {
+	return r.Level + "\x00" + r.Component + "\x00" + r.Template()
+}
+
+// templateVars are the variable parts of a message, most specific first
+var templateVars = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{instanceRef, "<instance>"},
+	{volumeRef, "<volume>"},
+	{regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`\b[0-9a-f]{8,}\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(?:\.\d+)?`), "<n>"},
+}
+
+// Template returns the message with its variable parts, such as Longhorn
+// object names, UUIDs, IP addresses and numbers, replaced by placeholders,
+// e.g. "replica <instance> failed after <n>s"
+func (r Record) Template() string {
+	template := r.Message
+	for _, v := range templateVars {
+		template = v.pattern.ReplaceAllString(template, v.placeholder)
+	}
+	return template
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,324 @@
+// Package logrecord parses the log formats found in a Harvester cluster into
+// structured records: Longhorn logfmt, KubeVirt JSON and klog
+package logrecord
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log line parsed into its structured parts. Lines in an unknown
+// format keep only Raw and Message.
+type Record struct {
+	Time      time.Time
+	Level     string
+	Component string
+	Message   string
+	Fields    map[string]string
+	Raw       string
+}
+
+var (
+	// klogLine matches "E0302 12:42:06.123456   1 controller.go:12] message"
+	klogLine = regexp.MustCompile(`^([IWEF])(\d{2})(\d{2}) (\d{2}:\d{2}:\d{2}\.\d+)\s+\d+ ([^\]]+)\] ?(.*)$`)
+	// volumeRef matches Longhorn volume names, which also prefix replica and
+	// engine names
+	volumeRef = regexp.MustCompile(`pvc-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	// instanceRef matches Longhorn replica and engine names: the volume name,
+	// "-r-" or "-e-" and a suffix
+	instanceRef = regexp.MustCompile(volumeRef.String() + `-([re])-[0-9a-z]+`)
+)
+
+var klogLevels = map[string]string{"I": "info", "W": "warning", "E": "error", "F": "fatal"}
+
+// resourceFields are the keys Longhorn and KubeVirt use to name the object a
+// line is about
+var resourceFields = []string{"volume", "replica", "engine", "volumeName", "replicaName", "engineName", "pvc", "name"}
+
+// Parse parses a single log line, e.g.
+//
+//	time="2024-03-02T12:42:06Z" level=error msg="..." volume=pvc-...
+//	{"component":"virt-handler","level":"error","msg":"...","timestamp":"..."}
+//	E0302 12:42:06.123456   1 controller.go:12] message
+func Parse(line string) Record {
+	line = strings.TrimRight(line, "\r")
+	if strings.HasPrefix(line, "{") {
+		if record, ok := parseJSON(line); ok {
+			return record
+		}
+	}
+	if m := klogLine.FindStringSubmatch(line); m != nil {
+		return parseKlog(line, m)
+	}
+	return parseLogfmtLine(line)
+}
+
+// ParseAll parses every non-empty line of content
+func ParseAll(content string) []Record {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	records := make([]Record, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		records = append(records, Parse(line))
+	}
+	return records
+}
+
+// parseJSON parses a KubeVirt JSON line
+func parseJSON(line string) (Record, bool) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Record{}, false
+	}
+	record := Record{Raw: line, Fields: make(map[string]string, len(raw))}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record.Fields[key] = v
+		case float64:
+			record.Fields[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			encoded, _ := json.Marshal(v)
+			record.Fields[key] = string(encoded)
+		}
+	}
+	record.Level = normalizeLevel(record.Fields["level"])
+	record.Component = record.Fields["component"]
+	record.Message = firstOf(record.Fields, "msg", "message")
+	if record.Message == "" {
+		record.Message = line
+	}
+	for _, key := range []string{"timestamp", "ts", "time"} {
+		if t, err := time.Parse(time.RFC3339Nano, record.Fields[key]); err == nil {
+			record.Time = t
+			break
+		}
+	}
+	return record, true
+}
+
+// parseKlog parses a klog line. klog omits the year, so the current one is
+// assumed.
+func parseKlog(line string, m []string) Record {
+	record := Record{Raw: line, Level: klogLevels[m[1]], Message: m[6]}
+	if t, err := time.Parse("01 02 15:04:05.999999", m[2]+" "+m[3]+" "+m[4]); err == nil {
+		record.Time = t.AddDate(time.Now().Year(), 0, 0)
+	}
+	record.Fields = map[string]string{"file": m[5]}
+	record.Component = sourceFile(m[5])
+	// Structured klog: "message" key="value" ...
+	if msg, rest, ok := cutQuoted(m[6]); ok {
+		record.Message = msg
+		for key, value := range parseLogfmt(rest) {
+			record.Fields[key] = value
+		}
+	}
+	return record
+}
+
+// parseLogfmtLine parses a Longhorn logfmt line. The component is the
+// controller that logged it, else its source file.
+func parseLogfmtLine(line string) Record {
+	record := Record{Raw: line, Message: line}
+	fields := parseLogfmt(line)
+	_, hasLevel := fields["level"]
+	_, hasMsg := fields["msg"]
+	if !hasLevel && !hasMsg {
+		return record
+	}
+	record.Fields = fields
+	record.Level = normalizeLevel(fields["level"])
+	if msg, ok := fields["msg"]; ok {
+		record.Message = msg
+	}
+	record.Component = firstOf(fields, "component", "controller")
+	if record.Component == "" && fields["file"] != "" {
+		record.Component = sourceFile(fields["file"])
+	}
+	if t, err := time.Parse(time.RFC3339Nano, fields["time"]); err == nil {
+		record.Time = t
+	}
+	return record
+}
+
+// parseLogfmt parses key=value pairs, where values may be double-quoted.
+// Text that is not a key=value pair, e.g. a "[pvc-...-r-1]" prefix, is skipped.
+func parseLogfmt(line string) map[string]string {
+	fields := make(map[string]string)
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		end := strings.IndexAny(rest, " =")
+		if end <= 0 || rest[end] != '=' {
+			// Not a key; skip the word
+			if space := strings.IndexByte(rest, ' '); space >= 0 {
+				rest = rest[space:]
+				continue
+			}
+			break
+		}
+		key := rest[:end]
+		rest = rest[end+1:]
+
+		if value, after, ok := cutQuoted(rest); ok {
+			fields[key] = value
+			rest = after
+			continue
+		}
+		value := rest
+		if space := strings.IndexByte(rest, ' '); space >= 0 {
+			value, rest = rest[:space], rest[space:]
+		} else {
+			rest = ""
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// cutQuoted splits a leading Go-quoted string from s
+func cutQuoted(s string) (value, rest string, ok bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", s, false
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", s, false
+			}
+			return value, s[i+1:], true
+		}
+	}
+	return "", s, false
+}
+
+func normalizeLevel(level string) string {
+	level = strings.ToLower(level)
+	if level == "warn" {
+		return "warning"
+	}
+	return level
+}
+
+// sourceFile strips the line number from "controller.go:12"
+func sourceFile(file string) string {
+	name, _, _ := strings.Cut(file, ":")
+	return name
+}
+
+func firstOf(fields map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := fields[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Field returns a field of the record. "msg" or "message", "level" and
+// "component" name the parsed parts; any other name is looked up in Fields.
+func (r Record) Field(name string) (string, bool) {
+	switch name {
+	case "msg", "message":
+		return r.Message, true
+	case "level":
+		return r.Level, r.Level != ""
+	case "component":
+		return r.Component, r.Component != ""
+	}
+	value, ok := r.Fields[name]
+	return value, ok
+}
+
+// References returns the objects a record is about: the values of its
+// resource fields and every Longhorn volume name in the line
+func (r Record) References() []string {
+	var refs []string
+	for _, key := range resourceFields {
+		if value := r.Fields[key]; value != "" {
+			refs = append(refs, value)
+		}
+	}
+	return append(refs, r.Volumes()...)
+}
+
+// Volumes returns every Longhorn volume name in the line. Replica and engine
+// names count as their volume.
+func (r Record) Volumes() []string {
+	return volumeRef.FindAllString(r.Raw, -1)
+}
+
+// Replicas returns every Longhorn replica name in the line
+func (r Record) Replicas() []string {
+	return instances(r.Raw, "r")
+}
+
+// Engines returns every Longhorn engine name in the line
+func (r Record) Engines() []string {
+	return instances(r.Raw, "e")
+}
+
+func instances(line, kind string) []string {
+	var names []string
+	for _, m := range instanceRef.FindAllStringSubmatch(line, -1) {
+		if m[1] == kind {
+			names = append(names, m[0])
+		}
+	}
+	return names
+}
+
+// Mentions reports whether the record names any of the scope terms
+func (r Record) Mentions(scope []string) bool {
+	for _, term := range scope {
+		if term != "" && strings.Contains(r.Raw, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the record on one line as "time level [component] message
+// key=value ...", with the fields already shown dropped and keys sorted
+func (r Record) String() string {
+	if r.Fields == nil && r.Level == "" {
+		return r.Raw
+	}
+	var b strings.Builder
+	if !r.Time.IsZero() {
+		b.WriteString(r.Time.UTC().Format(time.RFC3339))
+		b.WriteByte(' ')
+	}
+	if r.Level != "" {
+		b.WriteString(strings.ToUpper(r.Level))
+		b.WriteByte(' ')
+	}
+	if r.Component != "" {
+		fmt.Fprintf(&b, "[%s] ", r.Component)
+	}
+	b.WriteString(r.Message)
+	for _, key := range sortedKeys(r.Fields) {
+		switch key {
+		case "time", "timestamp", "ts", "level", "msg", "message", "component", "controller", "func", "file", "pos":
+			continue
+		}
+		fmt.Fprintf(&b, " %s=%s", key, quoteIfNeeded(r.Fields[key]))
+	}
+	return b.String()
+}
+
+func quoteIfNeeded(value string) string {
+	if value == "" || strings.ContainsAny(value, " \"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,117 @@
+package logrecord
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	volumeA = "pvc-11111111-2222-3333-4444-555555555555"
+	volumeB = "pvc-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+)
+
+func TestParse(t *testing.T) {
+	longhorn := Parse(`time="2024-03-02T12:42:06Z" level=warning msg="Replica is faulted, rebuilding" func=controller.syncVolume file="volume_controller.go:512" volume=` + volumeA)
+	if longhorn.Level != "warning" || longhorn.Message != "Replica is faulted, rebuilding" || longhorn.Fields["volume"] != volumeA {
+		t.Errorf("unexpected Longhorn record %+v", longhorn)
+	}
+	if longhorn.Component != "volume_controller.go" {
+		t.Errorf("expected the source file as component, got %q", longhorn.Component)
+	}
+	if want := time.Date(2024, 3, 2, 12, 42, 6, 0, time.UTC); !longhorn.Time.Equal(want) {
+		t.Errorf("expected time %v, got %v", want, longhorn.Time)
+	}
+
+	klog := Parse(`E0302 12:42:06.123456       1 attacher.go:87] "Failed to attach" volume="` + volumeB + `" node="node-1"`)
+	if klog.Level != "error" || klog.Message != "Failed to attach" || klog.Fields["node"] != "node-1" || klog.Fields["file"] != "attacher.go:87" {
+		t.Errorf("unexpected klog record %+v", klog)
+	}
+
+	kubevirt := Parse(`{"component":"virt-handler","level":"error","msg":"Synchronizing the VirtualMachineInstance failed.","name":"vm1","namespace":"default","pos":"vm.go:1712","timestamp":"2024-03-02T12:42:06.123456Z"}`)
+	if kubevirt.Component != "virt-handler" || kubevirt.Level != "error" || kubevirt.Fields["name"] != "vm1" || kubevirt.Time.IsZero() {
+		t.Errorf("unexpected KubeVirt record %+v", kubevirt)
+	}
+	if msg, _ := kubevirt.Field("msg"); msg != "Synchronizing the VirtualMachineInstance failed." {
+		t.Errorf("unexpected msg field %q", msg)
+	}
+
+	crashed := Parse(`level=warning msg="Instance ` + volumeA + `-e-0 crashed, replica ` + volumeA + `-r-7d3c1b2a unreachable"`)
+	if replicas, engines := crashed.Replicas(), crashed.Engines(); len(replicas) != 1 || replicas[0] != volumeA+"-r-7d3c1b2a" || len(engines) != 1 || engines[0] != volumeA+"-e-0" {
+		t.Errorf("unexpected replicas %v and engines %v", replicas, engines)
+	}
+
+	plain := Parse("just some text")
+	if plain.Message != "just some text" || plain.Fields != nil {
+		t.Errorf("unexpected plain record %+v", plain)
+	}
+}
+
+func TestCondense(t *testing.T) {
+	records := ParseAll(strings.Join([]string{
+		`time="2024-03-02T12:02:00Z" level=error msg="replica failed" volume=` + volumeA,
+		`time="2024-03-02T12:00:00Z" level=info msg="volume attached" volume=` + volumeA,
+		`time="2024-03-02T12:03:00Z" level=error msg="replica failed" volume=` + volumeA,
+		`goroutine 1 [running]:`,
+		`time="2024-03-02T12:04:00Z" level=error msg="replica failed" volume=` + volumeB,
+	}, "\n"))
+
+	groups := Condense(records)
+	if len(groups) != 4 {
+		t.Fatalf("expected 4 groups, got %+v", groups)
+	}
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Message)
+	}
+	want := []string{"volume attached", "replica failed", "goroutine 1 [running]:", "replica failed"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected order %q, got %q", want, got)
+	}
+	if groups[1].Count != 2 || !groups[1].Last.Equal(time.Date(2024, 3, 2, 12, 3, 0, 0, time.UTC)) {
+		t.Errorf("expected the repeated failure folded with its last time, got %+v", groups[1])
+	}
+}
+
+func TestCondenseTemplates(t *testing.T) {
+	records := ParseAll(strings.Join([]string{
+		`time="2024-03-02T12:00:00Z" level=error msg="replica ` + volumeA + `-r-1a2b3c4d failed after 30s" volume=` + volumeA,
+		`time="2024-03-02T12:01:00Z" level=error msg="replica ` + volumeB + `-r-9f8e7d6c failed after 45s" volume=` + volumeB,
+		`time="2024-03-02T12:02:00Z" level=error msg="dial tcp 10.52.0.12:10000: connection refused"`,
+		`time="2024-03-02T12:03:00Z" level=error msg="dial tcp 10.52.1.7:10015: connection refused"`,
+		`time="2024-03-02T12:04:00Z" level=warning msg="replica ` + volumeA + `-r-1a2b3c4d failed after 30s"`,
+	}, "\n"))
+
+	if got, want := records[0].Template(), "replica <instance> failed after <n>s"; got != want {
+		t.Errorf("Template() = %q, want %q", got, want)
+	}
+	groups := CondenseTemplates(records)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %+v", groups)
+	}
+	if groups[0].Count != 2 || groups[1].Count != 2 || groups[2].Count != 1 {
+		t.Errorf("expected the failures and the dial errors folded by template, got counts %d, %d, %d",
+			groups[0].Count, groups[1].Count, groups[2].Count)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"90m", now.Add(-90 * time.Minute), true},
+		{"7d", now.Add(-7 * 24 * time.Hour), true},
+		{"2024-03-01T00:00:00Z", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{"-1h", time.Time{}, false},
+		{"yesterday", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if !got.Equal(tt.want) || (err == nil) != tt.ok {
+			t.Errorf("ParseSince(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
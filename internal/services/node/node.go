@@ -299,6 +299,11 @@ func FetchRunningPodCounts(client *kubernetes.Clientset) (map[string]int, error)
 		return nil, fmt.Errorf("items field not found in pods response")
 	}
 
+	return CountRunningPods(items), nil
+}
+
+// CountRunningPods counts running and succeeded pods per node from a pod list
+func CountRunningPods(items []interface{}) map[string]int {
 	podCounts := make(map[string]int)
 
 	for _, item := range items {
@@ -330,7 +335,7 @@ func FetchRunningPodCounts(client *kubernetes.Clientset) (map[string]int, error)
 		}
 	}
 
-	return podCounts, nil
+	return podCounts
 }
 
 // FetchNodeCPULabels fetches CPU feature labels from all nodes
@@ -346,6 +351,11 @@ func FetchNodeCPULabels(client *kubernetes.Clientset) (map[string]map[string]str
 		return nil, fmt.Errorf("failed to fetch nodes: %w", err)
 	}
 
+	return ExtractNodeCPULabels(nodes), nil
+}
+
+// ExtractNodeCPULabels collects CPU feature labels from a list of Kubernetes nodes
+func ExtractNodeCPULabels(nodes []interface{}) map[string]map[string]string {
 	result := make(map[string]map[string]string)
 
 	for _, nodeItem := range nodes {
@@ -386,5 +396,5 @@ func FetchNodeCPULabels(client *kubernetes.Clientset) (map[string]map[string]str
 		}
 	}
 
-	return result, nil
+	return result
 }
@@ -16,6 +16,9 @@ func ShouldIncludeMatch(match MatchResultV2, opts AnalysisOptions) bool {
 	if opts.MinSeverity != "" && severityOrder(match.Severity) < severityOrder(opts.MinSeverity) {
 		return false
 	}
+	if opts.MinConfidence != "" && confidenceRank(match.Confidence) < confidenceRank(opts.MinConfidence) {
+		return false
+	}
 	return true
 }
 
@@ -30,36 +33,11 @@ func severityOrder(s Severity) int {
 	}
 }
 
-// DetectCorrelations finds co-occurring pattern correlations across all matches
+// DetectCorrelations finds the declared correlations between matched patterns
+// whose matches are close in time and about the same resources, ordered
+// cause first where timestamps tell
 func DetectCorrelations(matches []MatchResultV2, registry *PatternRegistry) []CorrelationMatch {
-	var correlations []CorrelationMatch
-	matchedIDs := make(map[string]bool)
-
-	for _, match := range matches {
-		if match.Matched {
-			matchedIDs[match.PatternID] = true
-		}
-	}
-
-	for _, match := range matches {
-		if !match.Matched {
-			continue
-		}
-		pattern, found := registry.GetByID(match.PatternID)
-		if !found {
-			continue
-		}
-		for _, corr := range pattern.Correlations {
-			if matchedIDs[corr.PatternID] {
-				correlations = append(correlations, CorrelationMatch{
-					PatternID1: match.PatternID,
-					PatternID2: corr.PatternID,
-					Message:    corr.Message,
-				})
-			}
-		}
-	}
-	return correlations
+	return correlate(matches, registry)
 }
 
 // BuildSummary creates analysis summary statistics
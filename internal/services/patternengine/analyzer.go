@@ -18,124 +18,156 @@ type Analyzer struct {
 	workers  int
 }
 
-// NewAnalyzer creates an Analyzer with all built-in patterns loaded
+// NewAnalyzer creates an Analyzer with all built-in and custom patterns loaded
 func NewAnalyzer() *Analyzer {
 	workers := runtime.NumCPU()
 	if workers < 4 {
 		workers = 4
 	}
 	return &Analyzer{
-		registry: NewRegistry(),
+		registry: loadRegistry(),
 		hints:    newHintGenerator(),
 		workers:  workers,
 	}
 }
 
-// AnalyzeLogs runs the pattern engine against raw log text and returns a LogAnalysisResult.
-// This is designed to be called before any LLM provider — if confidence is high, the LLM call can be skipped.
-func (a *Analyzer) AnalyzeLogs(ctx context.Context, logContent string) (*types.LogAnalysisResult, error) {
+// Analyze runs every pattern against raw log text and returns all findings
+// that pass opts. Hints are ranked with the root cause, the earliest pattern
+// that caused others, first; the rest follow by severity, confidence and
+// frequency.
+func (a *Analyzer) Analyze(ctx context.Context, logContent string, opts AnalysisOptions) (*AnalysisResult, error) {
 	if strings.TrimSpace(logContent) == "" {
 		return nil, fmt.Errorf("no log content provided")
 	}
 
 	start := time.Now()
 
-	matches := a.analyzeParallel(ctx, logContent)
-	hints := a.hints.GenerateAll(matches, a.registry)
-	correlations := DetectCorrelations(matches, a.registry)
+	all := a.analyzeParallel(ctx, logContent)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Correlations are found before filtering, so a filtered-out cause still
+	// orders what it caused
+	rootCause := rootCauseID(correlate(all, a.registry))
 
-	if len(hints) == 0 {
-		return nil, nil // no patterns matched — caller should fall through to LLM
+	matches := []MatchResultV2{}
+	included := make(map[string]bool)
+	for _, m := range all {
+		if ShouldIncludeMatch(m, opts) {
+			matches = append(matches, m)
+			included[m.PatternID] = true
+		}
+	}
+
+	hints := sortHints(a.hints.GenerateAll(matches, a.registry))
+	for i, h := range hints {
+		if h.PatternID == rootCause {
+			copy(hints[1:i+1], hints[:i])
+			hints[0] = h
+			break
+		}
+	}
+	if opts.MaxHints > 0 && len(hints) > opts.MaxHints {
+		hints = hints[:opts.MaxHints]
 	}
 
-	// Sort hints: critical first
-	hints = sortHints(hints)
+	correlations := []CorrelationMatch{}
+	for _, c := range correlate(matches, a.registry) {
+		if included[c.PatternID1] && included[c.PatternID2] {
+			correlations = append(correlations, c)
+		}
+	}
+
+	end := time.Now()
+	result := &AnalysisResult{
+		StartTime:    start,
+		EndTime:      end,
+		Duration:     end.Sub(start),
+		Patterns:     matches,
+		Hints:        hints,
+		Correlations: correlations,
+		Summary:      BuildSummary(matches, correlations, a.registry),
+	}
+	if len(hints) > 0 {
+		result.RootCause = hints[0].PatternID
+	}
+	return result, nil
+}
 
-	// Pick the top (most severe, most confident) hint as root cause
-	top := hints[0]
+// AnalyzeLogs runs the pattern engine against raw log text and returns a LogAnalysisResult
+// built from the top hint.
+// This is designed to be called before any LLM provider — if confidence is high, the LLM call can be skipped.
+func (a *Analyzer) AnalyzeLogs(ctx context.Context, logContent string) (*types.LogAnalysisResult, error) {
+	analysis, err := a.Analyze(ctx, logContent, AnalysisOptions{IncludeInfo: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(analysis.Hints) == 0 {
+		return nil, nil // no patterns matched — caller should fall through to LLM
+	}
 
+	top := analysis.Hints[0]
 	result := &types.LogAnalysisResult{
 		Provider:          "pattern-engine",
 		RootCause:         top.Summary,
 		FailingComponent:  categoryFromPatternID(top.PatternID, a.registry),
 		RecommendedAction: top.Suggestion,
 		Confidence:        mapConfidence(top.Confidence),
-		ErrorLines:        collectEvidence(matches, 10),
+		ErrorLines:        collectEvidence(analysis.Patterns, 10),
 		EstimatedCost:     0,
 		TokensUsed:        0,
 	}
 
 	// Enrich with correlation context if present
-	if len(correlations) > 0 {
-		msgs := make([]string, 0, len(correlations))
-		for _, c := range correlations {
+	if len(analysis.Correlations) > 0 {
+		msgs := make([]string, 0, len(analysis.Correlations))
+		for _, c := range analysis.Correlations {
 			msgs = append(msgs, c.Message)
 		}
 		result.RootCause += " [correlated: " + strings.Join(msgs, "; ") + "]"
 	}
 
-	_ = start
 	return result, nil
 }
 
-// analyzeParallel runs all patterns concurrently against the log content
+// analyzeParallel runs all patterns concurrently against the log content.
+// Matches are returned in registry order.
 func (a *Analyzer) analyzeParallel(ctx context.Context, content string) []MatchResultV2 {
-	patterns := a.registry.GetAll()
-	type result struct{ matches []MatchResultV2 }
+	matchers := a.registry.matchers
+	input := prepareLog(content, a.registry.index)
 
-	tasks := make(chan PatternV2, len(patterns))
-	results := make(chan result, len(patterns))
+	tasks := make(chan int, len(matchers))
+	evaluated := make([]MatchResultV2, len(matchers))
 
 	var wg sync.WaitGroup
 	for i := 0; i < a.workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for p := range tasks {
+			for n := range tasks {
 				select {
 				case <-ctx.Done():
 					return
 				default:
-					m := NewMatcherV2(p)
-					results <- result{matches: m.Match(content)}
+					evaluated[n] = matchers[n].evaluate(input)
 				}
 			}
 		}()
 	}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	for _, p := range patterns {
-		tasks <- p
+	for n := range matchers {
+		tasks <- n
 	}
 	close(tasks)
+	wg.Wait()
 
 	var all []MatchResultV2
-	matchedIDs := make(map[string]bool)
-	for r := range results {
-		for _, match := range r.matches {
-			if match.Matched {
-				all = append(all, match)
-				matchedIDs[match.PatternID] = true
-			}
-		}
-	}
-
-	// Fill in correlations
-	for i := range all {
-		p, found := a.registry.GetByID(all[i].PatternID)
-		if !found {
-			continue
-		}
-		for _, corr := range p.Correlations {
-			if matchedIDs[corr.PatternID] {
-				all[i].Correlated = append(all[i].Correlated, corr.PatternID)
-			}
+	for _, match := range evaluated {
+		if match.Matched {
+			all = append(all, match)
 		}
 	}
+	markCorrelated(all, correlate(all, a.registry), a.registry)
 	return all
 }
 
@@ -154,17 +186,18 @@ func sortHints(hints []*Hint) []*Hint {
 	return out
 }
 
+// hintLess reports whether a ranks before b
 func hintLess(a, b *Hint) bool {
 	sa, sb := severityRank(a.Severity), severityRank(b.Severity)
 	if sa != sb {
-		return sa < sb
+		return sa > sb
 	}
 	ca, cb := confidenceRank(a.Confidence), confidenceRank(b.Confidence)
 	if ca != cb {
-		return ca < cb
+		return ca > cb
 	}
 	// Same severity and confidence — higher occurrence count wins
-	return a.OccurrenceCount < b.OccurrenceCount
+	return a.OccurrenceCount > b.OccurrenceCount
 }
 
 func severityRank(s Severity) int {
@@ -0,0 +1,228 @@
+package patternengine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A corpus is a directory of log excerpts, each <name>.log paired with a
+// <name>.yaml file of the findings expected from it:
+//
+//	patterns: [longhorn-engine-crashed, longhorn-volume-degraded]
+//	root_cause: longhorn-engine-crashed
+//	correlations:
+//	  - cause: longhorn-engine-crashed
+//	    effect: longhorn-volume-degraded
+
+// Expectation lists the findings expected from a corpus sample
+type Expectation struct {
+	// Patterns are the IDs of every pattern that should match; any other
+	// match is a false positive
+	Patterns []string `yaml:"patterns" json:"patterns"`
+	// RootCause is checked when set
+	RootCause    string                `yaml:"root_cause" json:"root_cause,omitempty"`
+	Correlations []ExpectedCorrelation `yaml:"correlations" json:"correlations,omitempty"`
+}
+
+// ExpectedCorrelation is a correlation expected in a sample. The order is
+// only checked when the logs have timestamps to order it by.
+type ExpectedCorrelation struct {
+	Cause  string `yaml:"cause" json:"cause"`
+	Effect string `yaml:"effect" json:"effect"`
+}
+
+func (c ExpectedCorrelation) String() string {
+	return c.Cause + " -> " + c.Effect
+}
+
+// CorpusSample is one log excerpt of a corpus
+type CorpusSample struct {
+	Name   string
+	Logs   string
+	Expect Expectation
+}
+
+// SampleResult is the outcome of analyzing one sample
+type SampleResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Matched are the patterns that matched
+	Matched []string `json:"matched"`
+	// Missing were expected but did not match; Unexpected matched but were
+	// not expected
+	Missing    []string `json:"missing,omitempty"`
+	Unexpected []string `json:"unexpected,omitempty"`
+	RootCause  string   `json:"root_cause,omitempty"`
+	// WrongRootCause is set when the root cause is not the expected one
+	WrongRootCause      bool     `json:"wrong_root_cause,omitempty"`
+	MissingCorrelations []string `json:"missing_correlations,omitempty"`
+}
+
+// PatternScore counts how one pattern did across the corpus. Precision is 1
+// when the pattern never matched and recall is 1 when it was never expected.
+type PatternScore struct {
+	PatternID      string  `json:"pattern_id"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+}
+
+// CorpusReport is the outcome of running a corpus
+type CorpusReport struct {
+	Samples  []SampleResult `json:"samples"`
+	Patterns []PatternScore `json:"patterns"`
+}
+
+// Failed returns the samples that did not meet their expectation
+func (r *CorpusReport) Failed() []SampleResult {
+	var failed []SampleResult
+	for _, s := range r.Samples {
+		if !s.Passed {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// LoadCorpus reads every sample in dir, in name order. A log without an
+// expectation file is an error, so a sample cannot be skipped silently.
+func LoadCorpus(dir string) ([]CorpusSample, error) {
+	logs, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("no *.log samples in %s", dir)
+	}
+	sort.Strings(logs)
+
+	samples := make([]CorpusSample, 0, len(logs))
+	for _, path := range logs {
+		name := strings.TrimSuffix(filepath.Base(path), ".log")
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sample %s: %w", name, err)
+		}
+		expect, err := readExpectation(strings.TrimSuffix(path, ".log") + ".yaml")
+		if err != nil {
+			return nil, fmt.Errorf("sample %s: %w", name, err)
+		}
+		samples = append(samples, CorpusSample{Name: name, Logs: string(content), Expect: expect})
+	}
+	return samples, nil
+}
+
+func readExpectation(path string) (Expectation, error) {
+	var expect Expectation
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return expect, fmt.Errorf("failed to read expectation: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&expect); err != nil && !errors.Is(err, io.EOF) {
+		return expect, fmt.Errorf("invalid expectation %s: %w", filepath.Base(path), err)
+	}
+	return expect, nil
+}
+
+// RunCorpus analyzes every sample with all info findings included and scores
+// every pattern of the analyzer's registry
+func RunCorpus(ctx context.Context, a *Analyzer, samples []CorpusSample) (*CorpusReport, error) {
+	scores := make(map[string]*PatternScore)
+	for _, p := range a.registry.GetAll() {
+		scores[p.ID] = &PatternScore{PatternID: p.ID}
+	}
+	score := func(id string) *PatternScore {
+		if scores[id] == nil {
+			// Expected, but not in the registry
+			scores[id] = &PatternScore{PatternID: id}
+		}
+		return scores[id]
+	}
+
+	report := &CorpusReport{}
+	for _, sample := range samples {
+		result, err := a.Analyze(ctx, sample.Logs, AnalysisOptions{IncludeInfo: true})
+		if err != nil {
+			return nil, fmt.Errorf("sample %s: %w", sample.Name, err)
+		}
+
+		outcome := SampleResult{Name: sample.Name, Matched: []string{}, RootCause: result.RootCause}
+		matched := make(map[string]bool)
+		for _, m := range result.Patterns {
+			matched[m.PatternID] = true
+			outcome.Matched = append(outcome.Matched, m.PatternID)
+		}
+		expected := make(map[string]bool)
+		for _, id := range sample.Expect.Patterns {
+			expected[id] = true
+			if matched[id] {
+				score(id).TruePositives++
+			} else {
+				score(id).FalseNegatives++
+				outcome.Missing = append(outcome.Missing, id)
+			}
+		}
+		for _, id := range outcome.Matched {
+			if !expected[id] {
+				score(id).FalsePositives++
+				outcome.Unexpected = append(outcome.Unexpected, id)
+			}
+		}
+		if sample.Expect.RootCause != "" && sample.Expect.RootCause != result.RootCause {
+			outcome.WrongRootCause = true
+		}
+		for _, want := range sample.Expect.Correlations {
+			if !hasCorrelation(result.Correlations, want) {
+				outcome.MissingCorrelations = append(outcome.MissingCorrelations, want.String())
+			}
+		}
+		outcome.Passed = len(outcome.Missing) == 0 && len(outcome.Unexpected) == 0 &&
+			!outcome.WrongRootCause && len(outcome.MissingCorrelations) == 0
+		report.Samples = append(report.Samples, outcome)
+	}
+
+	for _, s := range scores {
+		s.Precision, s.Recall = 1, 1
+		if n := s.TruePositives + s.FalsePositives; n > 0 {
+			s.Precision = float64(s.TruePositives) / float64(n)
+		}
+		if n := s.TruePositives + s.FalseNegatives; n > 0 {
+			s.Recall = float64(s.TruePositives) / float64(n)
+		}
+		report.Patterns = append(report.Patterns, *s)
+	}
+	sort.Slice(report.Patterns, func(i, j int) bool {
+		return report.Patterns[i].PatternID < report.Patterns[j].PatternID
+	})
+	return report, nil
+}
+
+// hasCorrelation reports whether want was found. A correlation without a
+// cause, found in logs without timestamps, matches either order.
+func hasCorrelation(correlations []CorrelationMatch, want ExpectedCorrelation) bool {
+	for _, c := range correlations {
+		if c.Cause != "" {
+			if c.Cause == want.Cause && c.Effect == want.Effect {
+				return true
+			}
+			continue
+		}
+		if pairKey(c.PatternID1, c.PatternID2) == pairKey(want.Cause, want.Effect) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package patternengine
+
+import (
+	"context"
+	"testing"
+)
+
+// TestCorpus runs every pattern against the log excerpts in testdata/corpus
+// and fails on any sample whose findings differ from its expectation file
+func TestCorpus(t *testing.T) {
+	samples, err := LoadCorpus("testdata/corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := RunCorpus(context.Background(), NewAnalyzer(), samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range report.Failed() {
+		t.Errorf("%s: missing %v, unexpected %v, root cause %q (wrong: %v), missing correlations %v",
+			s.Name, s.Missing, s.Unexpected, s.RootCause, s.WrongRootCause, s.MissingCorrelations)
+	}
+	for _, p := range report.Patterns {
+		if p.TruePositives+p.FalsePositives+p.FalseNegatives > 0 {
+			t.Logf("%-40s precision %.2f recall %.2f", p.PatternID, p.Precision, p.Recall)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package patternengine
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
+)
+
+// DefaultCorrelationWindow is how far apart two timestamped matches may be to
+// be correlated, unless the correlation sets its own window
+const DefaultCorrelationWindow = 10 * time.Minute
+
+// maxOccurrences caps the lines kept per match for correlation
+const maxOccurrences = 100
+
+// resourceKeys maps field and named regex group names, lowercased, to the
+// kind of resource they name
+var resourceKeys = map[string]string{
+	"volume": "volume", "volumename": "volume", "pvc": "volume",
+	"replica": "replica", "replicaname": "replica",
+	"engine": "engine", "enginename": "engine",
+	"node": "node", "nodeid": "node", "nodename": "node",
+	"pod": "pod", "podname": "pod",
+	"namespace": "namespace",
+}
+
+// collectOccurrences builds the occurrences of the matched lines, whose named
+// regex groups are in groups
+func collectOccurrences(records []logrecord.Record, groups map[int]map[string]string) []Occurrence {
+	lines := make([]int, 0, len(groups))
+	for n := range groups {
+		lines = append(lines, n)
+	}
+	sort.Ints(lines)
+	if len(lines) > maxOccurrences {
+		lines = lines[:maxOccurrences]
+	}
+
+	occurrences := make([]Occurrence, 0, len(lines))
+	for _, n := range lines {
+		record := records[n]
+		occurrences = append(occurrences, Occurrence{
+			Time:      record.Time,
+			Line:      strings.TrimSpace(record.Raw),
+			Resources: resourcesOf(record, groups[n]),
+		})
+	}
+	return occurrences
+}
+
+// resourcesOf names the resources a line is about. Named regex groups win
+// over fields; volume, replica and engine names anywhere in the line are used
+// when no field names one.
+func resourcesOf(record logrecord.Record, groups map[string]string) map[string]string {
+	resources := make(map[string]string)
+	set := func(name, value string) {
+		kind, ok := resourceKeys[strings.ToLower(name)]
+		if _, exists := resources[kind]; ok && value != "" && !exists {
+			resources[kind] = value
+		}
+	}
+	for name, value := range groups {
+		set(name, value)
+	}
+	for key, value := range record.Fields {
+		set(key, value)
+	}
+	if volumes := record.Volumes(); len(volumes) > 0 {
+		set("volume", volumes[0])
+	}
+	if replicas := record.Replicas(); len(replicas) > 0 {
+		set("replica", replicas[0])
+	}
+	if engines := record.Engines(); len(engines) > 0 {
+		set("engine", engines[0])
+	}
+	return resources
+}
+
+// resourceOrder lists the kinds reported in MatchResultV2.Resources, in the
+// order they are reported
+var resourceOrder = []string{"volume", "replica", "engine", "node", "pod"}
+
+// matchResources lists the distinct resources named by the occurrences. A
+// pod takes the namespace named on its line.
+func matchResources(occurrences []Occurrence) []Resource {
+	var resources []Resource
+	seen := make(map[Resource]bool)
+	for _, kind := range resourceOrder {
+		for _, o := range occurrences {
+			name := o.Resources[kind]
+			if name == "" {
+				continue
+			}
+			r := Resource{Kind: kind, Name: name}
+			if kind == "pod" {
+				r.Namespace = o.Resources["namespace"]
+			}
+			if !seen[r] {
+				seen[r] = true
+				resources = append(resources, r)
+			}
+		}
+	}
+	return resources
+}
+
+// sameResources reports whether two lines may be about the same thing: they
+// agree on at least one resource, or name no resource of the same kind
+// differently
+func sameResources(a, b map[string]string) bool {
+	conflict := false
+	for kind, value := range a {
+		other, ok := b[kind]
+		if !ok {
+			continue
+		}
+		if other == value {
+			return true
+		}
+		conflict = true
+	}
+	return !conflict
+}
+
+// shared returns the resources two lines agree on
+func shared(a, b map[string]string) map[string]string {
+	result := make(map[string]string)
+	for kind, value := range a {
+		if b[kind] == value {
+			result[kind] = value
+		}
+	}
+	return result
+}
+
+// relation is how two matches relate in time
+type relation struct {
+	ordered bool
+	// aFirst is set when the first match came before the second
+	aFirst    bool
+	causeTime time.Time
+	gap       time.Duration
+	shared    map[string]string
+}
+
+// relate finds the closest pair of occurrences of a and b that are about the
+// same resources. Timestamped pairs must be within window of each other and
+// are ordered; pairs without timestamps only relate when no timestamped pair
+// was close enough to compare.
+func relate(a, b MatchResultV2, window time.Duration) (relation, bool) {
+	var best relation
+	found, untimed := false, false
+	var untimedShared map[string]string
+	for _, oa := range a.Occurrences {
+		for _, ob := range b.Occurrences {
+			if !sameResources(oa.Resources, ob.Resources) {
+				continue
+			}
+			if oa.Time.IsZero() || ob.Time.IsZero() {
+				if !untimed {
+					untimed, untimedShared = true, shared(oa.Resources, ob.Resources)
+				}
+				continue
+			}
+			gap := ob.Time.Sub(oa.Time)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > window || (found && gap >= best.gap) {
+				continue
+			}
+			found = true
+			best = relation{
+				ordered: !oa.Time.Equal(ob.Time),
+				aFirst:  oa.Time.Before(ob.Time),
+				gap:     gap,
+				shared:  shared(oa.Resources, ob.Resources),
+			}
+			best.causeTime = oa.Time
+			if ob.Time.Before(oa.Time) {
+				best.causeTime = ob.Time
+			}
+		}
+	}
+	if found {
+		return best, true
+	}
+	if untimed && (!timed(a) || !timed(b)) {
+		return relation{shared: untimedShared}, true
+	}
+	return relation{}, false
+}
+
+// timed reports whether any occurrence of the match has a timestamp
+func timed(m MatchResultV2) bool {
+	for _, o := range m.Occurrences {
+		if !o.Time.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// correlate returns the declared correlations whose patterns matched close
+// together on the same resources, one per pair of patterns. Ordered
+// correlations come first, earliest cause first.
+func correlate(matches []MatchResultV2, registry *PatternRegistry) []CorrelationMatch {
+	byID := make(map[string]MatchResultV2)
+	for _, m := range matches {
+		if m.Matched {
+			byID[m.PatternID] = m
+		}
+	}
+
+	type timedCorrelation struct {
+		CorrelationMatch
+		causeTime time.Time
+	}
+	var found []timedCorrelation
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if !m.Matched {
+			continue
+		}
+		pattern, ok := registry.GetByID(m.PatternID)
+		if !ok {
+			continue
+		}
+		for _, corr := range pattern.Correlations {
+			other, ok := byID[corr.PatternID]
+			pair := pairKey(m.PatternID, corr.PatternID)
+			if !ok || seen[pair] {
+				continue
+			}
+			window := corr.Window
+			if window <= 0 {
+				window = DefaultCorrelationWindow
+			}
+			rel, ok := relate(m, other, window)
+			if !ok {
+				continue
+			}
+			seen[pair] = true
+			c := CorrelationMatch{
+				PatternID1: m.PatternID,
+				PatternID2: corr.PatternID,
+				Message:    corr.Message,
+				Gap:        rel.gap,
+				Shared:     rel.shared,
+			}
+			if rel.ordered {
+				c.Cause, c.Effect = corr.PatternID, m.PatternID
+				if rel.aFirst {
+					c.Cause, c.Effect = m.PatternID, corr.PatternID
+				}
+			}
+			found = append(found, timedCorrelation{CorrelationMatch: c, causeTime: rel.causeTime})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if (a.Cause != "") != (b.Cause != "") {
+			return a.Cause != ""
+		}
+		return a.causeTime.Before(b.causeTime)
+	})
+	correlations := make([]CorrelationMatch, 0, len(found))
+	for _, c := range found {
+		correlations = append(correlations, c.CorrelationMatch)
+	}
+	return correlations
+}
+
+// markCorrelated fills in Correlated on each match with the patterns it
+// declares a correlation to that were found related
+func markCorrelated(matches []MatchResultV2, correlations []CorrelationMatch, registry *PatternRegistry) {
+	related := make(map[string]bool, len(correlations))
+	for _, c := range correlations {
+		related[pairKey(c.PatternID1, c.PatternID2)] = true
+	}
+	for i := range matches {
+		pattern, found := registry.GetByID(matches[i].PatternID)
+		if !found {
+			continue
+		}
+		for _, corr := range pattern.Correlations {
+			if related[pairKey(matches[i].PatternID, corr.PatternID)] {
+				matches[i].Correlated = append(matches[i].Correlated, corr.PatternID)
+			}
+		}
+	}
+}
+
+// rootCauseID returns the earliest pattern that caused another and was not
+// itself caused by one, or "" when no correlation is ordered
+func rootCauseID(correlations []CorrelationMatch) string {
+	effects := make(map[string]bool)
+	for _, c := range correlations {
+		if c.Effect != "" {
+			effects[c.Effect] = true
+		}
+	}
+	// Ordered correlations are sorted by cause time
+	for _, c := range correlations {
+		if c.Cause != "" && !effects[c.Cause] {
+			return c.Cause
+		}
+	}
+	return ""
+}
+
+func pairKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
@@ -0,0 +1,90 @@
+package patternengine
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const (
+	volumeA = "pvc-11111111-2222-3333-4444-555555555555"
+	volumeB = "pvc-aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee"
+)
+
+func TestDetectCorrelations_WindowAndResources(t *testing.T) {
+	crashed := `time="2026-03-02T12:10:26Z" level=warning msg="Instance ` + volumeA + `-e-0 crashed on Instance Manager im-1 at node-1, getting log"`
+	cases := map[string]struct {
+		degraded string
+		want     bool
+	}{
+		"same volume, close":     {`time="2026-03-02T12:12:00Z" level=warning msg="Failed to auto-balance volume in degraded state" volume=` + volumeA, true},
+		"same volume, hours off": {`time="2026-03-02T15:12:00Z" level=warning msg="Failed to auto-balance volume in degraded state" volume=` + volumeA, false},
+		"other volume, close":    {`time="2026-03-02T12:12:00Z" level=warning msg="Failed to auto-balance volume in degraded state" volume=` + volumeB, false},
+	}
+	for name, tc := range cases {
+		registry := NewRegistry()
+		matches := registry.AnalyzeV2(crashed + "\n" + tc.degraded)
+		var found *CorrelationMatch
+		for _, c := range DetectCorrelations(matches, registry) {
+			if pairKey(c.PatternID1, c.PatternID2) == pairKey("longhorn-engine-crashed", "longhorn-volume-degraded") {
+				found = &c
+			}
+		}
+		if (found != nil) != tc.want {
+			t.Errorf("%s: expected correlated=%v, got %+v", name, tc.want, found)
+			continue
+		}
+		if found != nil && (found.Cause != "longhorn-engine-crashed" || found.Effect != "longhorn-volume-degraded" || found.Shared["volume"] != volumeA) {
+			t.Errorf("%s: expected the crash to cause the degraded volume, got %+v", name, found)
+		}
+	}
+}
+
+func TestAnalyzeLogs_EarliestCauseIsRootCause(t *testing.T) {
+	// The replica became unreachable before the engine crashed, so the less
+	// severe pattern is the root cause
+	logs := strings.Join([]string{
+		`time="2026-03-02T12:00:00Z" level=error msg="Failed to sync" error="failed to get replica ` + volumeA + `-r-1: connect: connection refused"`,
+		`time="2026-03-02T12:01:00Z" level=warning msg="Instance ` + volumeA + `-e-0 crashed on Instance Manager im-1 at node-1, getting log"`,
+	}, "\n")
+
+	result, err := NewAnalyzer().AnalyzeLogs(context.Background(), logs)
+	if err != nil || result == nil {
+		t.Fatalf("expected a result, got %v (%v)", result, err)
+	}
+	if !strings.Contains(result.RootCause, "Replica Process Unreachable") {
+		t.Errorf("expected the unreachable replica as root cause, got %q", result.RootCause)
+	}
+}
+
+func TestAnalyze_OptionsAndRanking(t *testing.T) {
+	logs := strings.Join([]string{
+		`time="2026-03-02T12:00:00Z" level=error msg="Failed to sync" error="failed to get replica ` + volumeA + `-r-1: connect: connection refused"`,
+		`time="2026-03-02T12:01:00Z" level=warning msg="Instance ` + volumeA + `-e-0 crashed on Instance Manager im-1 at node-1, getting log"`,
+		`time="2026-03-02T12:02:00Z" level=warning msg="Failed to auto-balance volume in degraded state" volume=` + volumeA,
+	}, "\n")
+
+	result, err := NewAnalyzer().Analyze(context.Background(), logs, AnalysisOptions{IncludeInfo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hints) < 2 || result.RootCause != result.Hints[0].PatternID {
+		t.Fatalf("expected ranked hints led by the root cause, got %q and %d hints", result.RootCause, len(result.Hints))
+	}
+	if result.Summary.MatchesFound != len(result.Patterns) || len(result.Correlations) == 0 {
+		t.Errorf("expected summary and correlations to cover the matches, got %+v", result.Summary)
+	}
+
+	limited, err := NewAnalyzer().Analyze(context.Background(), logs, AnalysisOptions{MinSeverity: SeverityCritical, MaxHints: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited.Hints) > 1 {
+		t.Errorf("expected at most 1 hint, got %d", len(limited.Hints))
+	}
+	for _, m := range limited.Patterns {
+		if m.Severity != SeverityCritical {
+			t.Errorf("expected only critical matches, got %s (%s)", m.PatternID, m.Severity)
+		}
+	}
+}
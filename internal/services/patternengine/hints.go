@@ -45,6 +45,8 @@ func (hg *HintGeneratorV2) Generate(match MatchResultV2, pattern PatternV2) (*Hi
 		References:      references,
 		Metadata:        match.Metadata,
 		OccurrenceCount: match.OccurrenceCount,
+		Score:           match.Score,
+		Resources:       match.Resources,
 	}, nil
 }
 
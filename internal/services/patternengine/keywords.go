@@ -0,0 +1,110 @@
+package patternengine
+
+// keywordIndex finds every keyword of every pattern in a single pass over the
+// log, using an Aho-Corasick automaton. Matching ignores ASCII case.
+type keywordIndex struct {
+	nodes []keywordNode
+	count int
+}
+
+type keywordNode struct {
+	next map[byte]int
+	fail int
+	// outputs are the keywords ending at this node, including those reached
+	// through fail links
+	outputs []keywordOutput
+}
+
+type keywordOutput struct {
+	id     int
+	length int
+}
+
+// newKeywordIndex builds the automaton. Keyword IDs are their positions in
+// keywords; empty keywords never match.
+func newKeywordIndex(keywords []string) *keywordIndex {
+	idx := &keywordIndex{nodes: []keywordNode{{next: map[byte]int{}}}, count: len(keywords)}
+	for id, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		node := 0
+		for i := 0; i < len(keyword); i++ {
+			c := lowerASCII(keyword[i])
+			child, ok := idx.nodes[node].next[c]
+			if !ok {
+				child = len(idx.nodes)
+				idx.nodes = append(idx.nodes, keywordNode{next: map[byte]int{}})
+				idx.nodes[node].next[c] = child
+			}
+			node = child
+		}
+		idx.nodes[node].outputs = append(idx.nodes[node].outputs, keywordOutput{id: id, length: len(keyword)})
+	}
+
+	// Breadth-first, so a node's fail target is complete before its children
+	queue := make([]int, 0, len(idx.nodes))
+	for _, child := range idx.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for c, child := range idx.nodes[node].next {
+			fail := idx.nodes[node].fail
+			for fail > 0 {
+				if _, ok := idx.nodes[fail].next[c]; ok {
+					break
+				}
+				fail = idx.nodes[fail].fail
+			}
+			if target, ok := idx.nodes[fail].next[c]; ok && target != child {
+				idx.nodes[child].fail = target
+			}
+			idx.nodes[child].outputs = append(idx.nodes[child].outputs, idx.nodes[idx.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+	return idx
+}
+
+// scanLines returns, for every keyword, the indexes of the lines containing it
+func (idx *keywordIndex) scanLines(lines []string) [][]int {
+	found := make([][]int, idx.count)
+	for i, line := range lines {
+		idx.walk(line, func(id, _ int) {
+			if n := len(found[id]); n == 0 || found[id][n-1] != i {
+				found[id] = append(found[id], i)
+			}
+		})
+	}
+	return found
+}
+
+// walk calls emit with the ID and start offset of every keyword occurrence
+func (idx *keywordIndex) walk(content string, emit func(id, start int)) {
+	node := 0
+	for i := 0; i < len(content); i++ {
+		c := lowerASCII(content[i])
+		for {
+			if next, ok := idx.nodes[node].next[c]; ok {
+				node = next
+				break
+			}
+			if node == 0 {
+				break
+			}
+			node = idx.nodes[node].fail
+		}
+		for _, out := range idx.nodes[node].outputs {
+			emit(out.id, i+1-out.length)
+		}
+	}
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
@@ -0,0 +1,204 @@
+package patternengine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatternFile is the YAML layout of a custom pattern file:
+//
+//	patterns:
+//	  - id: longhorn-example
+//	    name: Example
+//	    severity: warning
+//	    confidence: likely
+//	    matchers:
+//	      - type: keyword
+//	        pattern: "something failed"
+type PatternFile struct {
+	Patterns []PatternV2 `yaml:"patterns"`
+}
+
+// Pattern sources hold the custom patterns loaded at runtime, keyed by where
+// they came from, e.g. "dir:/etc/patterns" or "configmap:ns/name". NewRegistry
+// adds them after the built-in patterns.
+var (
+	sourcesMutex sync.RWMutex
+	sources      = make(map[string][]PatternV2)
+)
+
+// ParsePatternFile decodes a pattern file. Unknown keys are rejected so that
+// a misspelt field is not silently ignored.
+func ParsePatternFile(data []byte) ([]PatternV2, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var file PatternFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return file.Patterns, nil
+}
+
+// SetPatternSource replaces the patterns of a source with those in files,
+// which maps file names to their YAML content. The update is rejected, and
+// the patterns loaded before are kept, when any file fails to parse or the
+// combined patterns fail ValidatePatterns.
+func SetPatternSource(source string, files map[string][]byte) error {
+	var patterns []PatternV2
+	var errs []error
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parsed, err := ParsePatternFile(files[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		patterns = append(patterns, parsed...)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load patterns from %s: %w", source, errors.Join(errs...))
+	}
+
+	sourcesMutex.Lock()
+	all := builtinPatterns()
+	for _, name := range sortedSources() {
+		if name != source {
+			all = append(all, sources[name]...)
+		}
+	}
+	if err := ValidatePatterns(append(all, patterns...)); err != nil {
+		sourcesMutex.Unlock()
+		return fmt.Errorf("invalid patterns in %s: %w", source, err)
+	}
+	if len(patterns) == 0 {
+		delete(sources, source)
+	} else {
+		sources[source] = patterns
+	}
+	// Released first, as building a registry takes sourcesMutex under loadedMutex
+	sourcesMutex.Unlock()
+	invalidateRegistry()
+	return nil
+}
+
+// RemovePatternSource drops the patterns of a source
+func RemovePatternSource(source string) {
+	sourcesMutex.Lock()
+	delete(sources, source)
+	sourcesMutex.Unlock()
+	invalidateRegistry()
+}
+
+// customPatterns returns the patterns of every source, ordered by source
+func customPatterns() []PatternV2 {
+	sourcesMutex.RLock()
+	defer sourcesMutex.RUnlock()
+	var patterns []PatternV2
+	for _, name := range sortedSources() {
+		patterns = append(patterns, sources[name]...)
+	}
+	return patterns
+}
+
+// sortedSources must be called with sourcesMutex held
+func sortedSources() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidatePatterns checks a complete pattern set: every pattern is valid on
+// its own, IDs are unique, and correlations point at patterns in the set
+func ValidatePatterns(patterns []PatternV2) error {
+	var errs []error
+	ids := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		if err := validatePattern(p); err != nil {
+			errs = append(errs, err)
+		}
+		if ids[p.ID] {
+			errs = append(errs, fmt.Errorf("pattern %q: duplicate ID", p.ID))
+		}
+		ids[p.ID] = true
+	}
+	for _, p := range patterns {
+		for _, corr := range p.Correlations {
+			if !ids[corr.PatternID] {
+				errs = append(errs, fmt.Errorf("pattern %q: correlation target %q does not exist", p.ID, corr.PatternID))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validatePattern checks the fields of a single pattern
+func validatePattern(p PatternV2) error {
+	if p.ID == "" {
+		return fmt.Errorf("pattern ID is required")
+	}
+	var errs []string
+	if p.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	switch p.Severity {
+	case SeverityCritical, SeverityWarning, SeverityInfo:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown severity %q", p.Severity))
+	}
+	switch p.Confidence {
+	case ConfidenceCertain, ConfidenceLikely, ConfidencePossible:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown confidence %q", p.Confidence))
+	}
+	if len(p.Matchers) == 0 {
+		errs = append(errs, "at least one matcher is required")
+	}
+	if p.Threshold < 0 || p.Threshold > 1 {
+		errs = append(errs, fmt.Sprintf("threshold %v is outside 0-1", p.Threshold))
+	}
+	for i, m := range p.Matchers {
+		if m.Weight < 0 {
+			errs = append(errs, fmt.Sprintf("matcher %d: weight is negative", i))
+		}
+		switch m.Type {
+		case "keyword":
+			if m.Pattern == "" {
+				errs = append(errs, fmt.Sprintf("matcher %d: keyword is empty", i))
+			}
+		case "regex":
+			if _, err := regexp.Compile(m.Pattern); err != nil {
+				errs = append(errs, fmt.Sprintf("matcher %d: %v", i, err))
+			}
+		case "record":
+			if len(m.Fields) == 0 {
+				errs = append(errs, fmt.Sprintf("matcher %d: record matcher needs at least one field condition", i))
+			}
+			for _, c := range m.Fields {
+				if c.Field == "" {
+					errs = append(errs, fmt.Sprintf("matcher %d: field condition without a field", i))
+				}
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("matcher %d: unknown type %q (use keyword, regex or record)", i, m.Type))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("pattern %q: %s", p.ID, strings.Join(errs, "; "))
+	}
+	return nil
+}
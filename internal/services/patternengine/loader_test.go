@@ -0,0 +1,59 @@
+package patternengine
+
+import (
+	"strings"
+	"testing"
+)
+
+const customPatternFile = `
+patterns:
+  - id: custom-volume-faulted
+    name: Custom Volume Faulted
+    category: Longhorn
+    severity: critical
+    confidence: certain
+    matchers:
+      - type: record
+        fields:
+          - field: msg
+            contains: all replicas are faulted
+    correlations:
+      - pattern_id: longhorn-volume-degraded
+        message: The volume degraded before every replica failed
+`
+
+func TestBuiltinPatternsAreValid(t *testing.T) {
+	if err := ValidatePatterns(builtinPatterns()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetPatternSource(t *testing.T) {
+	defer RemovePatternSource("test")
+
+	if err := SetPatternSource("test", map[string][]byte{"faulted.yaml": []byte(customPatternFile)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := NewRegistry().GetByID("custom-volume-faulted"); !found {
+		t.Fatal("expected the custom pattern in a new registry")
+	}
+
+	invalid := map[string]string{
+		"duplicate ID":      strings.Replace(customPatternFile, "custom-volume-faulted", "longhorn-volume-degraded", 1),
+		"regex":             strings.Replace(customPatternFile, "type: record", "type: regex\n        pattern: \"(unclosed\"", 1),
+		"correlation":       strings.Replace(customPatternFile, "pattern_id: longhorn-volume-degraded", "pattern_id: no-such-pattern", 1),
+		"unknown field":     strings.Replace(customPatternFile, "severity: critical", "severity: critical\n    serverity: typo", 1),
+		"unknown severity":  strings.Replace(customPatternFile, "severity: critical", "severity: urgent", 1),
+		"record w/o fields": strings.Replace(customPatternFile, "contains: all replicas are faulted", "contains: x\n      - type: record", 1),
+	}
+	for name, content := range invalid {
+		if err := SetPatternSource("test", map[string][]byte{"faulted.yaml": []byte(content)}); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	// Rejected updates keep the patterns loaded before
+	if _, found := NewRegistry().GetByID("custom-volume-faulted"); !found {
+		t.Error("expected the previous patterns to survive a rejected update")
+	}
+}
@@ -4,98 +4,229 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
 )
 
-// MatcherV2 provides pattern matching for PatternV2
+// DefaultThreshold is the score a pattern needs to match when it sets no
+// threshold of its own. A lone weak matcher, e.g. a generic phrase weighted
+// 0.3, stays below it.
+const DefaultThreshold = 0.5
+
+// MatcherV2 is a pattern compiled for matching: regexes are compiled and
+// keywords indexed once, when the matcher is created
 type MatcherV2 struct {
 	pattern PatternV2
+	// regexes holds the compiled regex of each regex matcher, by matcher index
+	regexes []*regexp.Regexp
+	// keywordIDs holds the keyword ID of each keyword matcher, by matcher
+	// index, or -1
+	keywordIDs []int
+	// index is the keyword index used by Match. Matchers built by a registry
+	// share the registry's index instead.
+	index *keywordIndex
 }
 
-// NewMatcherV2 creates a new v2 pattern matcher
+// NewMatcherV2 compiles a pattern. Regexes that do not compile never match;
+// ValidatePatterns reports them.
 func NewMatcherV2(p PatternV2) *MatcherV2 {
-	return &MatcherV2{pattern: p}
+	var keywords []string
+	m := compileMatcher(p, &keywords)
+	m.index = newKeywordIndex(keywords)
+	return m
 }
 
-// Match checks if content matches the pattern, returns all matches found
+// compileMatcher compiles a pattern, appending its keywords to keywords
+func compileMatcher(p PatternV2, keywords *[]string) *MatcherV2 {
+	m := &MatcherV2{
+		pattern:    p,
+		regexes:    make([]*regexp.Regexp, len(p.Matchers)),
+		keywordIDs: make([]int, len(p.Matchers)),
+	}
+	for i, matcher := range p.Matchers {
+		m.keywordIDs[i] = -1
+		switch matcher.Type {
+		case "regex":
+			if re, err := regexp.Compile(matcher.Pattern); err == nil {
+				m.regexes[i] = re
+			}
+		case "record":
+		default:
+			m.keywordIDs[i] = len(*keywords)
+			*keywords = append(*keywords, matcher.Pattern)
+		}
+	}
+	return m
+}
+
+// Match checks if content matches the pattern. It returns a single result,
+// which is unmatched when the score stays below the threshold.
 func (m *MatcherV2) Match(content string) []MatchResultV2 {
-	var results []MatchResultV2
-	contentLower := strings.ToLower(content)
-	hasRegexMatch := false
-
-	for _, matcher := range m.pattern.Matchers {
-		if matcher.Type == "regex" {
-			re, err := regexp.Compile(matcher.Pattern)
-			if err != nil {
-				continue
+	return []MatchResultV2{m.evaluate(prepareLog(content, m.index))}
+}
+
+// preparedLog is log content parsed into records once and scanned once for
+// the keywords of every pattern
+type preparedLog struct {
+	records []logrecord.Record
+	// keywordLines holds the indexes of the records containing each keyword
+	keywordLines [][]int
+}
+
+func prepareLog(content string, index *keywordIndex) *preparedLog {
+	records := logrecord.ParseAll(content)
+	lines := make([]string, len(records))
+	for i, record := range records {
+		lines[i] = record.Raw
+	}
+	return &preparedLog{records: records, keywordLines: index.scanLines(lines)}
+}
+
+// evaluate scores the pattern. Each matcher that finds something adds its
+// weight once, however often it hits; the score is capped at 1.
+func (m *MatcherV2) evaluate(input *preparedLog) MatchResultV2 {
+	var score float64
+	occurrences := 0
+	var evidence []string
+	metadata := make(map[string]string)
+	// groups holds the named regex groups of each matched line
+	groups := make(map[int]map[string]string)
+	addEvidence := func(e string) {
+		for _, existing := range evidence {
+			if existing == e {
+				return
 			}
-			matches := re.FindAllStringSubmatch(content, -1)
-			if matches == nil {
+		}
+		evidence = append(evidence, e)
+	}
+
+	for i, matcher := range m.pattern.Matchers {
+		var lines []int
+		switch matcher.Type {
+		case "regex":
+			re := m.regexes[i]
+			if re == nil {
 				continue
 			}
-			hasRegexMatch = true
-			for _, match := range matches {
-				metadata := make(map[string]string)
-				for i, name := range re.SubexpNames() {
-					if i != 0 && name != "" && i < len(match) {
-						metadata[name] = match[i]
+			for n, record := range input.records {
+				match := re.FindStringSubmatch(record.Raw)
+				if match == nil {
+					continue
+				}
+				lines = append(lines, n)
+				named := make(map[string]string)
+				for j, name := range re.SubexpNames() {
+					if j != 0 && name != "" {
+						named[name] = match[j]
+					}
+				}
+				if len(named) > 0 {
+					groups[n] = named
+				}
+				if len(lines) == 1 {
+					for name, value := range named {
+						if _, set := metadata[name]; !set {
+							metadata[name] = value
+						}
 					}
+					addEvidence(match[0])
+				}
+			}
+		case "record":
+			for n, record := range input.records {
+				if recordMatches(matcher.Fields, record) {
+					lines = append(lines, n)
 				}
-				results = append(results, MatchResultV2{
-					Matched:     true,
-					PatternID:   m.pattern.ID,
-					PatternName: m.pattern.Name,
-					Severity:    m.pattern.Severity,
-					Confidence:  m.pattern.Confidence,
-					Message:     m.detectedMessage(),
-					Evidence:    []string{match[0]},
-					Metadata:    metadata,
-				})
 			}
-		} else {
-			// keyword matcher — skip if regex already matched
-			if hasRegexMatch {
+			if len(lines) == 0 {
 				continue
 			}
-			patternLower := strings.ToLower(matcher.Pattern)
-			if strings.Contains(contentLower, patternLower) {
-				// Sum occurrences across all matching keywords for accurate frequency
-				totalOcc := 0
-				for _, km := range m.pattern.Matchers {
-					if km.Type != "regex" {
-						totalOcc += strings.Count(contentLower, strings.ToLower(km.Pattern))
-					}
+			first := input.records[lines[0]]
+			for key, value := range first.Fields {
+				if _, set := metadata[key]; !set {
+					metadata[key] = value
 				}
-				results = append(results, MatchResultV2{
-					Matched:         true,
-					PatternID:       m.pattern.ID,
-					PatternName:     m.pattern.Name,
-					Severity:        m.pattern.Severity,
-					Confidence:      m.pattern.Confidence,
-					Message:         m.detectedMessage(),
-					Evidence:        []string{m.extractEvidence(matcher, content)},
-					Metadata:        make(map[string]string),
-					OccurrenceCount: totalOcc,
-				})
-				break // one result per pattern
 			}
+			if first.Component != "" {
+				metadata["component"] = first.Component
+			}
+			metadata["msg"] = first.Message
+			addEvidence(strings.TrimSpace(first.Raw))
+		default:
+			lines = input.keywordLines[m.keywordIDs[i]]
+			if len(lines) > 0 {
+				addEvidence(strings.TrimSpace(input.records[lines[0]].Raw))
+			}
+		}
+		if len(lines) == 0 {
+			continue
 		}
+		occurrences += len(lines)
+		score += matcherWeight(matcher)
+		for _, n := range lines {
+			if _, ok := groups[n]; !ok {
+				groups[n] = nil
+			}
+		}
+	}
+	if score > 1 {
+		score = 1
 	}
 
-	if len(results) == 0 {
-		return []MatchResultV2{{Matched: false}}
+	if score == 0 || score < m.threshold() {
+		return MatchResultV2{Matched: false, PatternID: m.pattern.ID, Score: score}
 	}
-	return results
+	matchedLines := collectOccurrences(input.records, groups)
+	return MatchResultV2{
+		Matched:         true,
+		PatternID:       m.pattern.ID,
+		PatternName:     m.pattern.Name,
+		Severity:        m.pattern.Severity,
+		Confidence:      m.pattern.Confidence,
+		Message:         m.detectedMessage(),
+		Evidence:        evidence,
+		Metadata:        metadata,
+		OccurrenceCount: occurrences,
+		Resources:       matchResources(matchedLines),
+		Occurrences:     matchedLines,
+		Score:           score,
+	}
+}
+
+func (m *MatcherV2) threshold() float64 {
+	if m.pattern.Threshold > 0 {
+		return m.pattern.Threshold
+	}
+	return DefaultThreshold
+}
+
+// matcherWeight returns the weight of a matcher; an unset weight counts fully
+func matcherWeight(matcher Matcher) float64 {
+	if matcher.Weight > 0 {
+		return matcher.Weight
+	}
+	return 1
 }
 
-func (m *MatcherV2) extractEvidence(matcher Matcher, content string) string {
-	lines := strings.Split(content, "\n")
-	patternLower := strings.ToLower(matcher.Pattern)
-	for _, line := range lines {
-		if strings.Contains(strings.ToLower(line), patternLower) {
-			return strings.TrimSpace(line)
+// recordMatches reports whether the record meets every condition. Contains
+// ignores case, like keyword matchers; Equals is exact.
+func recordMatches(conditions []FieldCondition, record logrecord.Record) bool {
+	if len(conditions) == 0 {
+		return false
+	}
+	for _, c := range conditions {
+		value, ok := record.Field(c.Field)
+		if !ok {
+			return false
+		}
+		if c.Equals != "" && value != c.Equals {
+			return false
+		}
+		if c.Contains != "" && !strings.Contains(strings.ToLower(value), strings.ToLower(c.Contains)) {
+			return false
 		}
 	}
-	return matcher.Pattern
+	return true
 }
 
 func (m *MatcherV2) detectedMessage() string {
@@ -2,6 +2,7 @@ package patternengine
 
 import (
 	"context"
+	"fmt"
 	"testing"
 )
 
@@ -179,3 +180,52 @@ time="2026-03-02T12:47:21Z" level=warning msg="Replica rebuildings for map[pvc-a
 		t.Errorf("unexpected pattern: %s", r.PatternID)
 	}
 }
+
+func TestMatcherV2_RecordFields(t *testing.T) {
+	p := PatternV2{
+		ID: "volume-a-faulted", Name: "Volume A Faulted", Severity: SeverityWarning, Confidence: ConfidenceCertain,
+		Matchers: []Matcher{{Type: "record", Fields: []FieldCondition{
+			{Field: "msg", Contains: "faulted"},
+			{Field: "volume", Equals: "pvc-a"},
+		}}},
+	}
+	logs := `time="2026-03-02T12:42:06Z" level=warning msg="Replica is faulted" volume=pvc-b
+time="2026-03-02T12:42:07Z" level=warning msg="volume is healthy" volume=pvc-a
+time="2026-03-02T12:42:08Z" level=warning msg="Replica is Faulted" volume=pvc-a`
+
+	results := NewMatcherV2(p).Match(logs)
+	if len(results) != 1 || !results[0].Matched {
+		t.Fatalf("expected one match, got %+v", results)
+	}
+	if results[0].OccurrenceCount != 1 || results[0].Metadata["volume"] != "pvc-a" {
+		t.Errorf("expected only the pvc-a line to match, got %+v", results[0])
+	}
+	if NewMatcherV2(p).Match(`Replica is faulted volume=pvc-a`)[0].Matched {
+		t.Error("expected no match on a line without a msg field")
+	}
+}
+
+func TestMatcherV2_WeakEvidenceBelowThreshold(t *testing.T) {
+	p, _ := NewRegistry().GetByID("longhorn-engine-crashed")
+
+	weak := NewMatcherV2(p).Match(`level=info msg="getting log for instance"`)[0]
+	if weak.Matched || weak.Score != 0.3 {
+		t.Errorf("expected an unmatched 0.3 score for the weak matcher alone, got %+v", weak)
+	}
+
+	strong := NewMatcherV2(p).Match(`level=warning msg="Instance pvc-1-e-0 crashed on Instance Manager im-1 at node-1, getting log"`)[0]
+	if !strong.Matched || strong.Score != 1 || len(strong.Evidence) != 1 {
+		t.Errorf("expected a full score with one evidence line, got %+v", strong)
+	}
+}
+
+func TestKeywordIndex(t *testing.T) {
+	idx := newKeywordIndex([]string{"he", "she", "his", "hers", ""})
+	found := idx.scanLines([]string{"uSHErs", "and his", "he said he"})
+	want := [][]int{{0, 2}, {0}, {1}, {0}, nil}
+	for i, w := range want {
+		if fmt.Sprint(found[i]) != fmt.Sprint(w) {
+			t.Errorf("keyword %d: expected lines %v, got %v", i, w, found[i])
+		}
+	}
+}
@@ -1,35 +1,96 @@
 package patternengine
 
-import "fmt"
+import (
+	"fmt"
+	"log"
+	"sync"
+)
 
-// PatternRegistry manages all pattern definitions
+// PatternRegistry manages all pattern definitions. Patterns are compiled as
+// they are added, and the keywords of all patterns share one index so the log
+// is scanned for them once.
 type PatternRegistry struct {
 	patterns []PatternV2
+	matchers []*MatcherV2
+	keywords []string
+	index    *keywordIndex
 }
 
-// NewRegistry creates a registry loaded with all built-in patterns (generic + Harvester-specific)
+var (
+	loadedMutex    sync.Mutex
+	loadedRegistry *PatternRegistry
+)
+
+// NewRegistry creates a registry loaded with all built-in patterns (generic + Harvester-specific),
+// followed by the custom patterns loaded from pattern files and ConfigMaps
 func NewRegistry() *PatternRegistry {
+	r := &PatternRegistry{}
+	for _, p := range builtinPatterns() {
+		r.add(p)
+	}
+	for _, p := range customPatterns() {
+		if err := r.validateNew(p); err != nil {
+			log.Printf("Warning: Skipping custom pattern: %v", err)
+			continue
+		}
+		r.add(p)
+	}
+	r.index = newKeywordIndex(r.keywords)
+	return r
+}
+
+// loadRegistry returns a shared registry, built on first use and rebuilt
+// after the custom patterns change. It must not be modified.
+func loadRegistry() *PatternRegistry {
+	loadedMutex.Lock()
+	defer loadedMutex.Unlock()
+	if loadedRegistry == nil {
+		loadedRegistry = NewRegistry()
+	}
+	return loadedRegistry
+}
+
+// invalidateRegistry makes the next loadRegistry pick up changed patterns
+func invalidateRegistry() {
+	loadedMutex.Lock()
+	defer loadedMutex.Unlock()
+	loadedRegistry = nil
+}
+
+func builtinPatterns() []PatternV2 {
 	all := make([]PatternV2, 0, len(genericPatterns)+len(harvesterPatterns))
 	all = append(all, genericPatterns...)
 	all = append(all, harvesterPatterns...)
-	return &PatternRegistry{patterns: all}
+	return all
 }
 
-// Register adds a custom pattern
+// Register adds a custom pattern. Correlation targets are not checked here,
+// since they may be registered later; ValidatePatterns checks a whole set.
 func (r *PatternRegistry) Register(p PatternV2) error {
-	if p.ID == "" {
-		return fmt.Errorf("pattern ID is required")
+	if err := r.validateNew(p); err != nil {
+		return err
 	}
-	if p.Name == "" {
-		return fmt.Errorf("pattern name is required")
+	r.add(p)
+	r.index = newKeywordIndex(r.keywords)
+	return nil
+}
+
+func (r *PatternRegistry) validateNew(p PatternV2) error {
+	if err := validatePattern(p); err != nil {
+		return err
 	}
-	if len(p.Matchers) == 0 {
-		return fmt.Errorf("at least one matcher is required")
+	if _, found := r.GetByID(p.ID); found {
+		return fmt.Errorf("pattern %q is already registered", p.ID)
 	}
-	r.patterns = append(r.patterns, p)
 	return nil
 }
 
+// add compiles a pattern; the caller rebuilds the keyword index
+func (r *PatternRegistry) add(p PatternV2) {
+	r.patterns = append(r.patterns, p)
+	r.matchers = append(r.matchers, compileMatcher(p, &r.keywords))
+}
+
 // GetByID retrieves a pattern by ID
 func (r *PatternRegistry) GetByID(id string) (PatternV2, bool) {
 	for _, p := range r.patterns {
@@ -47,32 +108,13 @@ func (r *PatternRegistry) GetAll() []PatternV2 {
 
 // AnalyzeV2 scans content against all patterns, returns matches with correlations filled in
 func (r *PatternRegistry) AnalyzeV2(content string) []MatchResultV2 {
+	input := prepareLog(content, r.index)
 	var matches []MatchResultV2
-	matchedIDs := make(map[string]bool)
-
-	for _, pattern := range r.patterns {
-		matcher := NewMatcherV2(pattern)
-		results := matcher.Match(content)
-		for _, result := range results {
-			if result.Matched {
-				matches = append(matches, result)
-				matchedIDs[pattern.ID] = true
-			}
+	for _, matcher := range r.matchers {
+		if result := matcher.evaluate(input); result.Matched {
+			matches = append(matches, result)
 		}
 	}
-
-	// Second pass: fill in correlations
-	for i := range matches {
-		pattern, found := r.GetByID(matches[i].PatternID)
-		if !found {
-			continue
-		}
-		for _, corr := range pattern.Correlations {
-			if matchedIDs[corr.PatternID] {
-				matches[i].Correlated = append(matches[i].Correlated, corr.PatternID)
-			}
-		}
-	}
-
+	markCorrelated(matches, correlate(matches, r), r)
 	return matches
 }
@@ -0,0 +1,128 @@
+package patternengine
+
+import (
+	"strings"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// ResolveResources looks up the resources of every match and hint in the
+// cluster data, linking them to their VM, volume and node and recording
+// whether they are still unhealthy
+func ResolveResources(result *AnalysisResult, data *types.FullClusterData) {
+	for i := range result.Patterns {
+		result.Patterns[i].Resources = resolveAll(result.Patterns[i].Resources, data)
+	}
+	for _, hint := range result.Hints {
+		hint.Resources = resolveAll(hint.Resources, data)
+	}
+}
+
+// resolveAll resolves a copy of resources, so that matches and hints sharing
+// them are not changed twice
+func resolveAll(resources []Resource, data *types.FullClusterData) []Resource {
+	if len(resources) == 0 {
+		return resources
+	}
+	resolved := make([]Resource, len(resources))
+	for i, r := range resources {
+		resolved[i] = resolveResource(r, data)
+	}
+	return resolved
+}
+
+func resolveResource(r Resource, data *types.FullClusterData) Resource {
+	r.Status = ResourceNotFound
+	if r.Kind == "node" {
+		for _, node := range data.Nodes {
+			if node.NodeInfo.Name != r.Name && (node.KubernetesNodeInfo == nil || node.KubernetesNodeInfo.Name != r.Name) {
+				continue
+			}
+			r.Node = r.Name
+			r.State = "NotReady"
+			if nodeReady(node) {
+				r.State = "Ready"
+			}
+			r.Status = statusOf(r.State == "Ready")
+			return r
+		}
+		return r
+	}
+
+	for _, vm := range data.VMs {
+		vmName := vm.Namespace + "/" + vm.Name
+		if r.Kind == "pod" {
+			for _, pod := range vm.PodInfo {
+				if pod.Name == r.Name && (r.Namespace == "" || r.Namespace == vm.Namespace) {
+					r.Namespace, r.VM, r.Node, r.State = vm.Namespace, vmName, pod.NodeID, pod.Status
+					r.Status = statusOf(pod.Status == "Running")
+					return r
+				}
+			}
+			continue
+		}
+		for _, disk := range vm.DiskViews() {
+			switch r.Kind {
+			case "volume":
+				if disk.VolumeName != r.Name {
+					continue
+				}
+				r.VM, r.Volume = vmName, disk.VolumeName
+				r.State = disk.VolumeRobustness
+				for _, engine := range disk.EngineInfo {
+					if engine.Active {
+						r.Node = engine.NodeID
+					}
+				}
+				r.Status = statusOf(volumeHealthy(disk))
+				return r
+			case "replica":
+				for _, replica := range disk.ReplicaInfo {
+					if replica.Name == r.Name {
+						r.VM, r.Volume, r.Node, r.State = vmName, disk.VolumeName, replica.NodeID, replica.CurrentState
+						r.Status = statusOf(replica.CurrentState == "running" && replica.Started)
+						return r
+					}
+				}
+			case "engine":
+				for _, engine := range disk.EngineInfo {
+					if engine.Name == r.Name {
+						r.VM, r.Volume, r.Node, r.State = vmName, disk.VolumeName, engine.NodeID, engine.CurrentState
+						r.Status = statusOf(engine.CurrentState == "running")
+						return r
+					}
+				}
+			}
+		}
+	}
+	return r
+}
+
+// volumeHealthy treats a detached volume as healthy unless it is faulted, as
+// Longhorn reports the robustness of detached volumes as unknown
+func volumeHealthy(disk types.VMDisk) bool {
+	if disk.VolumeRobustness == "healthy" {
+		return true
+	}
+	return disk.VolumeState == "detached" && disk.VolumeRobustness != "faulted"
+}
+
+func nodeReady(node types.NodeWithMetrics) bool {
+	conditions := node.NodeInfo.Conditions
+	if node.KubernetesNodeInfo != nil {
+		conditions = node.KubernetesNodeInfo.Conditions
+	}
+	for _, c := range conditions {
+		if strings.EqualFold(c.Type, "Ready") {
+			return c.Status == "True"
+		}
+	}
+	return false
+}
+
+func statusOf(healthy bool) ResourceStatus {
+	if healthy {
+		return ResourceRecovered
+	}
+	return ResourceUnhealthy
+}
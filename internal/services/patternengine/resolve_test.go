@@ -0,0 +1,51 @@
+package patternengine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func TestResolveResources(t *testing.T) {
+	logs := strings.Join([]string{
+		`time="2026-03-02T12:01:00Z" level=warning msg="Instance ` + volumeA + `-e-0 crashed on Instance Manager im-1 at node-1, getting log"`,
+		`time="2026-03-02T12:02:00Z" level=warning msg="Failed to auto-balance volume in degraded state" volume=` + volumeA,
+	}, "\n")
+	result, err := NewAnalyzer().Analyze(context.Background(), logs, AnalysisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := &types.FullClusterData{VMs: []types.VMInfo{{
+		Name: "vm1", Namespace: "default",
+		Disks: []types.VMDisk{{
+			VolumeName:       volumeA,
+			VolumeRobustness: "degraded",
+			VolumeState:      "attached",
+			EngineInfo:       []types.EngineInfo{{Name: volumeA + "-e-0", NodeID: "node-2", CurrentState: "running", Active: true}},
+		}},
+	}}}
+	ResolveResources(result, data)
+
+	want := map[string]Resource{
+		"volume": {Kind: "volume", Name: volumeA, Status: ResourceUnhealthy, State: "degraded", VM: "default/vm1", Volume: volumeA, Node: "node-2"},
+		"engine": {Kind: "engine", Name: volumeA + "-e-0", Status: ResourceRecovered, State: "running", VM: "default/vm1", Volume: volumeA, Node: "node-2"},
+	}
+	for _, hint := range result.Hints {
+		if hint.PatternID != "longhorn-engine-crashed" {
+			continue
+		}
+		if len(hint.Resources) != len(want) {
+			t.Fatalf("expected %d resources, got %+v", len(want), hint.Resources)
+		}
+		for _, r := range hint.Resources {
+			if r != want[r.Kind] {
+				t.Errorf("expected %+v, got %+v", want[r.Kind], r)
+			}
+		}
+		return
+	}
+	t.Fatal("expected the engine crash to be found")
+}
@@ -33,19 +33,35 @@ type PatternV2 struct {
 	Correlations  []Correlation `yaml:"correlations"`
 	HintGenerator HintGenerator `yaml:"hint_generator"`
 	Description   string        `yaml:"description"`
+	// Threshold is the score needed to match; 0 uses DefaultThreshold
+	Threshold float64 `yaml:"threshold"`
 }
 
-// Matcher defines how to match a pattern
+// Matcher defines how to match a pattern. Keyword and regex matchers search
+// the raw log text; a record matcher matches parsed log records on Fields.
 type Matcher struct {
-	Type    string  `yaml:"type"`
-	Pattern string  `yaml:"pattern"`
-	Weight  float64 `yaml:"weight"`
+	Type    string           `yaml:"type"`
+	Pattern string           `yaml:"pattern"`
+	Weight  float64          `yaml:"weight"`
+	Fields  []FieldCondition `yaml:"fields"`
+}
+
+// FieldCondition is one condition of a record matcher, e.g. msg contains
+// "degraded" or volume equals "pvc-...". Field names follow
+// logrecord.Record.Field. Empty Contains and Equals only require the field.
+type FieldCondition struct {
+	Field    string `yaml:"field"`
+	Contains string `yaml:"contains"`
+	Equals   string `yaml:"equals"`
 }
 
 // Correlation links patterns together for root cause analysis
 type Correlation struct {
 	PatternID string `yaml:"pattern_id"`
 	Message   string `yaml:"message"`
+	// Window is how far apart timestamped matches may be; 0 uses
+	// DefaultCorrelationWindow
+	Window time.Duration `yaml:"window"`
 }
 
 // HintGenerator produces root cause hints from pattern matches
@@ -59,72 +75,115 @@ type HintGenerator struct {
 
 // MatchResultV2 represents pattern matching outcome with correlation support
 type MatchResultV2 struct {
-	Matched         bool
-	PatternID       string
-	PatternName     string
-	Severity        Severity
-	Confidence      Confidence
-	Message         string
-	Resources       []Resource
-	Evidence        []string
-	Correlated      []string
-	Metadata        map[string]string
-	OccurrenceCount int // how many times the primary keyword appeared in the logs
+	Matched         bool              `json:"matched"`
+	PatternID       string            `json:"pattern_id"`
+	PatternName     string            `json:"pattern_name"`
+	Severity        Severity          `json:"severity"`
+	Confidence      Confidence        `json:"confidence"`
+	Message         string            `json:"message"`
+	Resources       []Resource        `json:"resources,omitempty"`
+	Evidence        []string          `json:"evidence"`
+	Correlated      []string          `json:"correlated,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	OccurrenceCount int               `json:"occurrence_count"` // how many times the matchers hit in the logs
+	Score           float64           `json:"score"`            // sum of the weights of the matchers that hit, at most 1
+	// Occurrences are the first matched lines, in log order
+	Occurrences []Occurrence `json:"occurrences,omitempty"`
+}
+
+// Occurrence is a log line matched by a pattern
+type Occurrence struct {
+	Time time.Time `json:"time"` // zero when the line has no timestamp
+	Line string    `json:"line"`
+	// Resources names what the line is about, e.g. "volume" and "node", taken
+	// from its fields and the pattern's named regex groups
+	Resources map[string]string `json:"resources,omitempty"`
 }
 
-// Resource identifies a Kubernetes resource affected by a pattern
+// Resource identifies a Kubernetes resource affected by a pattern. The fields
+// after Namespace are set by ResolveResources from the cluster data.
 type Resource struct {
-	Kind      string
-	Name      string
-	Namespace string
+	Kind      string `json:"kind"` // volume, replica, engine, node or pod
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Status tells whether the resource is still unhealthy
+	Status ResourceStatus `json:"status,omitempty"`
+	// State is the current state it was judged by, e.g. a volume's robustness
+	State string `json:"state,omitempty"`
+	// VM, Volume and Node link the resource to what it belongs to or runs on.
+	// VM is namespace/name.
+	VM     string `json:"vm,omitempty"`
+	Volume string `json:"volume,omitempty"`
+	Node   string `json:"node,omitempty"`
 }
 
+// ResourceStatus is the current condition of a resource named in the logs
+type ResourceStatus string
+
+const (
+	ResourceUnhealthy ResourceStatus = "unhealthy"
+	ResourceRecovered ResourceStatus = "recovered"
+	ResourceNotFound  ResourceStatus = "not-found"
+)
+
 // AnalysisOptions provides filtering options for analysis
 type AnalysisOptions struct {
 	MinSeverity   Severity
 	MinConfidence Confidence
-	MaxHints      int
+	MaxHints      int // 0 for no limit
 	IncludeInfo   bool
 }
 
 // AnalysisResult represents the complete analysis of log content
 type AnalysisResult struct {
-	StartTime    time.Time
-	EndTime      time.Time
-	Duration     time.Duration
-	Patterns     []MatchResultV2
-	Hints        []*Hint
-	Correlations []CorrelationMatch
-	Summary      AnalysisSummary
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration_ns"`
+	// RootCause is the pattern ID of the first hint, if any
+	RootCause    string             `json:"root_cause,omitempty"`
+	Patterns     []MatchResultV2    `json:"patterns"`
+	Hints        []*Hint            `json:"hints"`
+	Correlations []CorrelationMatch `json:"correlations"`
+	Summary      AnalysisSummary    `json:"summary"`
 }
 
-// CorrelationMatch represents a detected correlation between patterns
+// CorrelationMatch represents a detected correlation between patterns.
+// PatternID1 declares the correlation to PatternID2. When both matched at a
+// known time, Cause and Effect order them by which came first.
 type CorrelationMatch struct {
-	PatternID1 string
-	PatternID2 string
-	Message    string
+	PatternID1 string `json:"pattern_id_1"`
+	PatternID2 string `json:"pattern_id_2"`
+	Message    string `json:"message"`
+	Cause      string `json:"cause,omitempty"`
+	Effect     string `json:"effect,omitempty"`
+	// Gap is the time from the cause to the effect
+	Gap time.Duration `json:"gap_ns,omitempty"`
+	// Shared are the resources both matches are about
+	Shared map[string]string `json:"shared,omitempty"`
 }
 
 // AnalysisSummary provides high-level statistics
 type AnalysisSummary struct {
-	TotalPatterns  int
-	MatchesFound   int
-	CriticalIssues int
-	WarningIssues  int
-	InfoIssues     int
-	Correlations   int
+	TotalPatterns  int `json:"total_patterns"`
+	MatchesFound   int `json:"matches_found"`
+	CriticalIssues int `json:"critical_issues"`
+	WarningIssues  int `json:"warning_issues"`
+	InfoIssues     int `json:"info_issues"`
+	Correlations   int `json:"correlations"`
 }
 
 // Hint represents a generated root cause hint
 type Hint struct {
-	PatternID       string
-	Severity        Severity
-	Confidence      Confidence
-	Summary         string
-	Explanation     string
-	Suggestion      string
-	Command         string
-	References      []string
-	Metadata        map[string]string
-	OccurrenceCount int
+	PatternID       string            `json:"pattern_id"`
+	Severity        Severity          `json:"severity"`
+	Confidence      Confidence        `json:"confidence"`
+	Summary         string            `json:"summary"`
+	Explanation     string            `json:"explanation"`
+	Suggestion      string            `json:"suggestion,omitempty"`
+	Command         string            `json:"command,omitempty"`
+	References      []string          `json:"references,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	OccurrenceCount int               `json:"occurrence_count"`
+	Score           float64           `json:"score"`
+	Resources       []Resource        `json:"resources,omitempty"`
 }
@@ -0,0 +1,162 @@
+package patternengine
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// PatternConfigMapSelector selects the ConfigMaps holding custom patterns.
+// Every data key ending in .yaml or .yml is read as a pattern file.
+const PatternConfigMapSelector = "harvesternavigator.io/patterns=true"
+
+// LoadPatternDir loads every .yaml and .yml file in dir as one pattern source
+func LoadPatternDir(dir string) error {
+	files, err := readPatternDir(dir)
+	if err != nil {
+		return err
+	}
+	return SetPatternSource("dir:"+dir, files)
+}
+
+// WatchPatternDir reloads the pattern files in dir whenever one is added,
+// removed or modified, until ctx is cancelled. Files are polled every
+// interval. A reload that fails validation keeps the patterns loaded before.
+func WatchPatternDir(ctx context.Context, dir string, interval time.Duration) {
+	last, _ := dirFingerprint(dir)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		current, err := dirFingerprint(dir)
+		if err != nil {
+			log.Printf("Warning: Could not read pattern directory %s: %v", dir, err)
+			continue
+		}
+		if current == last {
+			continue
+		}
+		last = current
+		if err := LoadPatternDir(dir); err != nil {
+			log.Printf("Warning: Could not reload patterns: %v", err)
+			continue
+		}
+		log.Printf("Reloaded patterns from %s", dir)
+	}
+}
+
+func isPatternFile(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
+
+func readPatternDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pattern directory: %w", err)
+	}
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !isPatternFile(entry.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pattern file: %w", err)
+		}
+		files[entry.Name()] = data
+	}
+	return files, nil
+}
+
+// dirFingerprint summarises the names, sizes and modification times of the
+// pattern files in dir
+func dirFingerprint(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isPatternFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "\n"), nil
+}
+
+// WatchPatternConfigMaps loads the patterns of every ConfigMap matching
+// PatternConfigMapSelector, in any namespace, and keeps them in sync as the
+// ConfigMaps change until ctx is cancelled. An error is returned when the
+// ConfigMaps cannot be listed, e.g. for lack of RBAC permission.
+func WatchPatternConfigMaps(ctx context.Context, clientset kubernetes.Interface) error {
+	if _, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: PatternConfigMapSelector, Limit: 1}); err != nil {
+		return fmt.Errorf("failed to list pattern ConfigMaps: %w", err)
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = PatternConfigMapSelector
+		}))
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	_, err := informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { loadConfigMap(obj) },
+		UpdateFunc: func(_, obj interface{}) { loadConfigMap(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if cm, ok := obj.(*corev1.ConfigMap); ok {
+				RemovePatternSource(configMapSource(cm))
+				log.Printf("Removed patterns from ConfigMap %s/%s", cm.Namespace, cm.Name)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch pattern ConfigMaps: %w", err)
+	}
+	factory.Start(ctx.Done())
+	return nil
+}
+
+func loadConfigMap(obj interface{}) {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	files := make(map[string][]byte)
+	for key, value := range cm.Data {
+		if isPatternFile(key) {
+			files[key] = []byte(value)
+		}
+	}
+	if err := SetPatternSource(configMapSource(cm), files); err != nil {
+		log.Printf("Warning: Could not load patterns: %v", err)
+		return
+	}
+	log.Printf("Loaded patterns from ConfigMap %s/%s", cm.Namespace, cm.Name)
+}
+
+func configMapSource(cm *corev1.ConfigMap) string {
+	return "configmap:" + cm.Namespace + "/" + cm.Name
+}
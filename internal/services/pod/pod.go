@@ -90,7 +90,9 @@ func ParsePodData(podData map[string]interface{}) ([]types.PodInfo, error) {
 	}
 
 	// Create and add pod info
+	name, _ := podMetadata["name"].(string)
 	podInfo := types.PodInfo{
+		Name:   name,
 		VMI:    ownerRefName,
 		NodeID: nodeName,
 		Status: status,
@@ -0,0 +1,43 @@
+package source
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Source provides cluster resources in the same shape as a JSON-decoded API
+// response, so the existing Parse* functions work on any backend. It is
+// implemented by the live cluster cache and by offline support bundles.
+type Source interface {
+	// List returns all objects of a resource. An empty namespace lists
+	// across all namespaces.
+	List(gvr schema.GroupVersionResource, namespace string) ([]map[string]interface{}, error)
+	// Get returns a single object. Cluster-scoped resources use an empty
+	// namespace. A missing object yields a Kubernetes NotFound error.
+	Get(gvr schema.GroupVersionResource, namespace, name string) (map[string]interface{}, error)
+}
+
+// ResourceFor maps a REST path such as "apis/longhorn.io/v1beta2" or "/api/v1"
+// and a resource name to a GroupVersionResource.
+func ResourceFor(absPath, resource string) (schema.GroupVersionResource, bool) {
+	parts := strings.Split(strings.Trim(absPath, "/"), "/")
+
+	switch {
+	case len(parts) == 2 && parts[0] == "api":
+		return schema.GroupVersionResource{Version: parts[1], Resource: resource}, true
+	case len(parts) == 3 && parts[0] == "apis":
+		return schema.GroupVersionResource{Group: parts[1], Version: parts[2], Resource: resource}, true
+	default:
+		return schema.GroupVersionResource{}, false
+	}
+}
+
+// ToItems converts objects to the []interface{} shape of a decoded list response
+func ToItems(objects []map[string]interface{}) []interface{} {
+	items := make([]interface{}, len(objects))
+	for i, obj := range objects {
+		items[i] = obj
+	}
+	return items
+}
@@ -0,0 +1,25 @@
+package source
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResourceFor(t *testing.T) {
+	tests := []struct {
+		path string
+		want schema.GroupVersionResource
+		ok   bool
+	}{
+		{"/api/v1", schema.GroupVersionResource{Version: "v1", Resource: "pods"}, true},
+		{"apis/longhorn.io/v1beta2", schema.GroupVersionResource{Group: "longhorn.io", Version: "v1beta2", Resource: "pods"}, true},
+		{"/apis/longhorn.io", schema.GroupVersionResource{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ResourceFor(tt.path, "pods")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ResourceFor(%q) = %v, %t, want %v, %t", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
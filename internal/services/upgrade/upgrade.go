@@ -28,8 +28,13 @@ func FetchLatestUpgrade(client *kubernetes.Clientset) (*models.UpgradeInfo, erro
 		return nil, fmt.Errorf("failed to unmarshal upgrades list: %w", err)
 	}
 
-	items, ok := upgradesList["items"].([]interface{})
-	if !ok || len(items) == 0 {
+	items, _ := upgradesList["items"].([]interface{})
+	return LatestUpgradeFromList(items)
+}
+
+// LatestUpgradeFromList picks the most recently created upgrade from a list of upgrade objects
+func LatestUpgradeFromList(items []interface{}) (*models.UpgradeInfo, error) {
+	if len(items) == 0 {
 		return nil, fmt.Errorf("no upgrades found")
 	}
 
@@ -162,6 +162,73 @@ func ParseLonghornNodeData(nodes []interface{}) ([]models.NodeInfo, error) {
 	return results, nil
 }
 
+// ParseVMDisks lists every PVC-backed disk of a VM. Claims from the
+// harvesterhci.io/volumeClaimTemplates annotation come first, in annotation
+// order, followed by any other PVC volumes in spec.template.spec.volumes
+// (existing or hotplugged volumes).
+func ParseVMDisks(vmData map[string]interface{}) []models.VMDisk {
+	var disks []models.VMDisk
+	index := make(map[string]int)
+
+	metadata, _ := vmData["metadata"].(map[string]interface{})
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if templateStr, ok := annotations["harvesterhci.io/volumeClaimTemplates"].(string); ok {
+		var templates []map[string]interface{}
+		if json.Unmarshal([]byte(templateStr), &templates) == nil {
+			for _, tmpl := range templates {
+				tmplMeta, _ := tmpl["metadata"].(map[string]interface{})
+				claimName := getString(tmplMeta, "name")
+				if claimName == "" {
+					continue
+				}
+				tmplAnns, _ := tmplMeta["annotations"].(map[string]interface{})
+				tmplSpec, _ := tmpl["spec"].(map[string]interface{})
+
+				index[claimName] = len(disks)
+				disks = append(disks, models.VMDisk{
+					ClaimName:    claimName,
+					ImageId:      getString(tmplAnns, "harvesterhci.io/imageId"),
+					StorageClass: getString(tmplSpec, "storageClassName"),
+				})
+			}
+		}
+	}
+
+	spec, _ := vmData["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	templateSpec, _ := template["spec"].(map[string]interface{})
+	volumes, _ := templateSpec["volumes"].([]interface{})
+	for _, vol := range volumes {
+		volMap, ok := vol.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pvc, ok := volMap["persistentVolumeClaim"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		claimName := getString(pvc, "claimName")
+		if claimName == "" {
+			continue
+		}
+		hotplug, _ := pvc["hotpluggable"].(bool)
+
+		if i, exists := index[claimName]; exists {
+			disks[i].Name = getString(volMap, "name")
+			disks[i].Hotplug = hotplug
+			continue
+		}
+		index[claimName] = len(disks)
+		disks = append(disks, models.VMDisk{
+			Name:      getString(volMap, "name"),
+			ClaimName: claimName,
+			Hotplug:   hotplug,
+		})
+	}
+
+	return disks
+}
+
 // ParseVMMetaData extracts metadata and status from a VM object.
 func ParseVMMetaData(vmData map[string]interface{}, vmInfo *models.VMInfo) error {
 	metadata, ok := vmData["metadata"].(map[string]interface{})
@@ -185,41 +252,11 @@ func ParseVMMetaData(vmData map[string]interface{}, vmInfo *models.VMInfo) error
 		vmInfo.RemovedPVCs = removedPVCs
 	}
 
-	// Try to get PVC name from volumeClaimTemplates annotation first
-	if templateStr, ok := annotations["harvesterhci.io/volumeClaimTemplates"].(string); ok {
-		var templates []map[string]interface{}
-		if json.Unmarshal([]byte(templateStr), &templates) == nil && len(templates) > 0 {
-			tmplMeta, _ := templates[0]["metadata"].(map[string]interface{})
-			vmInfo.ClaimNames, _ = tmplMeta["name"].(string)
-
-			tmplAnns, _ := tmplMeta["annotations"].(map[string]interface{})
-			vmInfo.ImageId, _ = tmplAnns["harvesterhci.io/imageId"].(string)
-
-			tmplSpec, _ := templates[0]["spec"].(map[string]interface{})
-			vmInfo.StorageClass, _ = tmplSpec["storageClassName"].(string)
-		}
-	}
-
-	// If no volumeClaimTemplates, try to extract first PVC from spec.template.spec.volumes
-	if vmInfo.ClaimNames == "" {
-		if spec, ok := vmData["spec"].(map[string]interface{}); ok {
-			if template, ok := spec["template"].(map[string]interface{}); ok {
-				if templateSpec, ok := template["spec"].(map[string]interface{}); ok {
-					if volumes, ok := templateSpec["volumes"].([]interface{}); ok {
-						for _, vol := range volumes {
-							if volMap, ok := vol.(map[string]interface{}); ok {
-								if pvc, ok := volMap["persistentVolumeClaim"].(map[string]interface{}); ok {
-									if claimName, ok := pvc["claimName"].(string); ok {
-										vmInfo.ClaimNames = claimName
-										break // Use first PVC found
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
+	vmInfo.Disks = ParseVMDisks(vmData)
+	if len(vmInfo.Disks) > 0 {
+		vmInfo.ClaimNames = vmInfo.Disks[0].ClaimName
+		vmInfo.ImageId = vmInfo.Disks[0].ImageId
+		vmInfo.StorageClass = vmInfo.Disks[0].StorageClass
 	}
 
 	status, _ := vmData["status"].(map[string]interface{})
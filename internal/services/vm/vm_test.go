@@ -0,0 +1,88 @@
+package vm
+
+import (
+	"encoding/json"
+	"testing"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func vmObject(t *testing.T, manifest string) map[string]interface{} {
+	t.Helper()
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(manifest), &obj); err != nil {
+		t.Fatalf("invalid test manifest: %v", err)
+	}
+	return obj
+}
+
+func TestParseVMDisks(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []models.VMDisk
+	}{
+		{
+			name: "only PVC volumes are disks",
+			manifest: `{"spec": {"template": {"spec": {"volumes": [
+				{"name": "rootdisk", "persistentVolumeClaim": {"claimName": "vm1-rootdisk"}},
+				{"name": "cdrom", "containerDisk": {"image": "registry/iso:latest"}},
+				{"name": "cloudinitdisk", "cloudInitNoCloud": {"userData": "#cloud-config"}}
+			]}}}}`,
+			want: []models.VMDisk{{Name: "rootdisk", ClaimName: "vm1-rootdisk"}},
+		},
+		{
+			name: "claim templates first, then other and hotplugged PVCs",
+			manifest: `{
+				"metadata": {"annotations": {"harvesterhci.io/volumeClaimTemplates":
+					"[{\"metadata\":{\"name\":\"vm1-disk-0-abcd\",\"annotations\":{\"harvesterhci.io/imageId\":\"default/image-x\"}},\"spec\":{\"storageClassName\":\"longhorn-image-x\"}},{\"metadata\":{\"name\":\"vm1-disk-1-efgh\"},\"spec\":{\"storageClassName\":\"harvester-longhorn\"}}]"}},
+				"spec": {"template": {"spec": {"volumes": [
+					{"name": "hot", "persistentVolumeClaim": {"claimName": "shared-data", "hotpluggable": true}},
+					{"name": "disk-1", "persistentVolumeClaim": {"claimName": "vm1-disk-1-efgh"}},
+					{"name": "disk-0", "persistentVolumeClaim": {"claimName": "vm1-disk-0-abcd"}}
+				]}}}
+			}`,
+			want: []models.VMDisk{
+				{Name: "disk-0", ClaimName: "vm1-disk-0-abcd", ImageId: "default/image-x", StorageClass: "longhorn-image-x"},
+				{Name: "disk-1", ClaimName: "vm1-disk-1-efgh", StorageClass: "harvester-longhorn"},
+				{Name: "hot", ClaimName: "shared-data", Hotplug: true},
+			},
+		},
+		{
+			// Volumes are matched to templates by claim name, never by
+			// volume name, and a template without a volume is still listed
+			name: "volume and claim names differ",
+			manifest: `{
+				"metadata": {"annotations": {"harvesterhci.io/volumeClaimTemplates":
+					"[{\"metadata\":{\"name\":\"vm1-rootdisk-x1y2\"}},{\"metadata\":{\"name\":\"vm1-unused-z9\"}}]"}},
+				"spec": {"template": {"spec": {"volumes": [
+					{"name": "vm1-unused-z9", "persistentVolumeClaim": {"claimName": "vm1-rootdisk-x1y2"}}
+				]}}}
+			}`,
+			want: []models.VMDisk{
+				{Name: "vm1-unused-z9", ClaimName: "vm1-rootdisk-x1y2"},
+				{ClaimName: "vm1-unused-z9"},
+			},
+		},
+		{
+			name:     "no volumes",
+			manifest: `{"spec": {"template": {"spec": {}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseVMDisks(vmObject(t, tt.manifest))
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseVMDisks = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Name != tt.want[i].Name || got[i].ClaimName != tt.want[i].ClaimName ||
+					got[i].ImageId != tt.want[i].ImageId || got[i].StorageClass != tt.want[i].StorageClass ||
+					got[i].Hotplug != tt.want[i].Hotplug {
+					t.Errorf("disk %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -36,9 +36,19 @@ func FetchVMIDetails(client *kubernetes.Clientset, name, absPath, namespace, res
 	return vmiData, nil
 }
 
+// PodNameLookup resolves the virt-launcher pod names of a VMI, keyed by pod UID
+type PodNameLookup func(namespace, vmName string, nodeToUID map[string]string) (map[string]string, error)
+
 // ParseVMIData extracts relevant information from VMI data and returns it as VMIInfo objects.
 // It processes metadata, status, guest OS info, memory info, and network interfaces.
 func ParseVMIData(client *kubernetes.Clientset, vmiData map[string]interface{}, namespace string) ([]types.VMIInfo, error) {
+	return ParseVMIDataWithLookup(vmiData, namespace, func(ns, vmName string, nodeToUID map[string]string) (map[string]string, error) {
+		return FetchPodNamesForVM(client, ns, vmName, nodeToUID)
+	})
+}
+
+// ParseVMIDataWithLookup is ParseVMIData with a caller-supplied pod name lookup
+func ParseVMIDataWithLookup(vmiData map[string]interface{}, namespace string, lookupPodNames PodNameLookup) ([]types.VMIInfo, error) {
 	var vmiInfos []types.VMIInfo
 
 	// Check if VMI data is available
@@ -74,7 +84,7 @@ func ParseVMIData(client *kubernetes.Clientset, vmiData map[string]interface{},
 	extractActivePods(vmiStatus, &vmiInfo)
 
 	if len(vmiInfo.ActivePods) > 0 {
-		podNames, err := FetchPodNamesForVM(client, namespace, vmiName, vmiInfo.ActivePods)
+		podNames, err := lookupPodNames(namespace, vmiName, vmiInfo.ActivePods)
 		if err != nil {
 			log.Printf("Warning: Could not fetch pod names for VMI %s: %v", vmiName, err)
 		} else {
@@ -201,33 +211,53 @@ func FetchPodNamesForVM(client *kubernetes.Clientset, namespace string, vmName s
 		return nil, err
 	}
 
-	vmPrefix := fmt.Sprintf("virt-launcher-%s-", vmName)
 	for _, pod := range pods.Items {
-		// Check if pod name matches exactly: virt-launcher-{vmName}-{suffix}
-		// The suffix should be exactly 5 random chars, not another VM name
-		if strings.HasPrefix(pod.Name, vmPrefix) {
-			// Extract the suffix after the prefix
-			suffix := strings.TrimPrefix(pod.Name, vmPrefix)
-
-			// Skip if suffix contains additional hyphens (indicates another VM name like "new-xxxxx")
-			if strings.Contains(suffix, "-") {
-				continue
-			}
-
-			nodeName := pod.Spec.NodeName
-			// Find the UID that corresponds to this node
-			for uid, uidNode := range nodeToUID {
-				if uidNode == nodeName {
-					podNames[uid] = pod.Name
-					break
-				}
-			}
-		}
+		matchLauncherPod(pod.Name, pod.Spec.NodeName, vmName, nodeToUID, podNames)
 	}
 
 	return podNames, nil
 }
 
+// MatchPodNamesForVM is FetchPodNamesForVM over an already fetched pod list
+func MatchPodNamesForVM(pods []map[string]interface{}, vmName string, nodeToUID map[string]string) map[string]string {
+	podNames := make(map[string]string)
+
+	for _, pod := range pods {
+		metadata, _ := pod["metadata"].(map[string]interface{})
+		spec, _ := pod["spec"].(map[string]interface{})
+		podName, _ := metadata["name"].(string)
+		nodeName, _ := spec["nodeName"].(string)
+		matchLauncherPod(podName, nodeName, vmName, nodeToUID, podNames)
+	}
+
+	return podNames
+}
+
+// matchLauncherPod records podName under the VMI pod UID running on nodeName
+// when the pod is a virt-launcher pod of vmName
+func matchLauncherPod(podName, nodeName, vmName string, nodeToUID map[string]string, podNames map[string]string) {
+	// Check if pod name matches exactly: virt-launcher-{vmName}-{suffix}
+	// The suffix should be exactly 5 random chars, not another VM name
+	vmPrefix := fmt.Sprintf("virt-launcher-%s-", vmName)
+	if !strings.HasPrefix(podName, vmPrefix) {
+		return
+	}
+
+	// Skip if suffix contains additional hyphens (indicates another VM name like "new-xxxxx")
+	suffix := strings.TrimPrefix(podName, vmPrefix)
+	if strings.Contains(suffix, "-") {
+		return
+	}
+
+	// Find the UID that corresponds to this node
+	for uid, uidNode := range nodeToUID {
+		if uidNode == nodeName {
+			podNames[uid] = podName
+			break
+		}
+	}
+}
+
 // extractGuestOSInfo extracts guest OS information from VMI status
 func extractGuestOSInfo(vmiStatus map[string]interface{}, vmiInfo *types.VMIInfo) {
 	guestOSInfoRaw, ok := vmiStatus["guestOSInfo"]
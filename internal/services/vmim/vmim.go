@@ -62,17 +62,25 @@ func FetchAllVMIMsForVMI(client *kubernetes.Clientset, vmiName, absPath, namespa
 		return nil, fmt.Errorf("items is not an array")
 	}
 
-	var result []map[string]interface{}
+	var vmims []map[string]interface{}
 	for _, item := range items {
 		if itemMap, ok := item.(map[string]interface{}); ok {
-			// Check if this VMIM is for our VMI
-			if isVMIMForVMI(itemMap, vmiName) {
-				result = append(result, itemMap)
-			}
+			vmims = append(vmims, itemMap)
 		}
 	}
 
-	return result, nil
+	return FilterVMIMsForVMI(vmims, vmiName), nil
+}
+
+// FilterVMIMsForVMI returns the migrations in vmims that belong to a specific VMI
+func FilterVMIMsForVMI(vmims []map[string]interface{}, vmiName string) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, vmimData := range vmims {
+		if isVMIMForVMI(vmimData, vmiName) {
+			result = append(result, vmimData)
+		}
+	}
+	return result
 }
 
 // ParseVMIMData extracts relevant information from VMIM data and returns it as VMIMInfo objects
@@ -2,12 +2,15 @@ package volume
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 
 	"github.com/rk280392/harvesterNavigator/internal/services/batch"
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
 	"github.com/rk280392/harvesterNavigator/internal/services/pvc"
+	"github.com/rk280392/harvesterNavigator/internal/services/source"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -15,16 +18,18 @@ import (
 // VolumeService provides batch volume operations
 type VolumeService struct {
 	client       *kubernetes.Clientset
+	source       source.Source
 	batchFetcher *batch.BatchFetcher
 	longhornData map[string]map[string]interface{}
 	mutex        sync.RWMutex
 }
 
-// CreateVolumeService creates a volume service
-func CreateVolumeService(client *kubernetes.Clientset) *VolumeService {
+// CreateVolumeService creates a volume service that reads from the given source when possible
+func CreateVolumeService(client *kubernetes.Clientset, src source.Source) *VolumeService {
 	return &VolumeService{
 		client:       client,
-		batchFetcher: batch.CreateBatchFetcher(client),
+		source:       src,
+		batchFetcher: batch.CreateBatchFetcher(client, src),
 	}
 }
 
@@ -120,6 +125,8 @@ func (vs *VolumeService) BatchFetchVolumeDetails(pvcRequests []batch.PVCRequest)
 						if state, ok := status["state"].(string); ok {
 							volumeDetails.State = state
 						}
+						volumeDetails.CurrentNodeID, _ = status["currentNodeID"].(string)
+						volumeDetails.MigrationNodeID, _ = status["currentMigrationNodeID"].(string)
 					}
 					if spec, ok := backendDetails["spec"].(map[string]interface{}); ok {
 						if n, ok := spec["numberOfReplicas"].(float64); ok {
@@ -297,29 +304,26 @@ func (vs *VolumeService) GetPodFromVolumeBatch(pvcRequests []batch.PVCRequest) (
 		go func(ns string, names []string) {
 			defer wg.Done()
 
-			pods, err := vs.client.CoreV1().Pods(ns).List(context.TODO(), metav1.ListOptions{})
-			if err != nil {
-				log.Printf("Warning: Could not list pods in namespace %s: %v", ns, err)
-				return
-			}
-
 			// Create a map for quick PVC lookup
 			pvcSet := make(map[string]bool)
 			for _, name := range names {
 				pvcSet[name] = true
 			}
 
+			podClaims, err := vs.listPodClaims(ns)
+			if err != nil {
+				log.Printf("Warning: Could not list pods in namespace %s: %v", ns, err)
+				return
+			}
+
 			// Find pods using these PVCs
-			for _, pod := range pods.Items {
-				for _, volume := range pod.Spec.Volumes {
-					if volume.PersistentVolumeClaim != nil {
-						pvcName := volume.PersistentVolumeClaim.ClaimName
-						if pvcSet[pvcName] {
-							key := fmt.Sprintf("pvc-%s-%s", ns, pvcName)
-							mutex.Lock()
-							result[key] = pod.Name
-							mutex.Unlock()
-						}
+			for podName, claims := range podClaims {
+				for _, pvcName := range claims {
+					if pvcSet[pvcName] {
+						key := fmt.Sprintf("pvc-%s-%s", ns, pvcName)
+						mutex.Lock()
+						result[key] = podName
+						mutex.Unlock()
 					}
 				}
 			}
@@ -329,3 +333,52 @@ func (vs *VolumeService) GetPodFromVolumeBatch(pvcRequests []batch.PVCRequest) (
 	wg.Wait()
 	return result, nil
 }
+
+// listPodClaims returns the PVC names mounted by each pod in a namespace
+func (vs *VolumeService) listPodClaims(namespace string) (map[string][]string, error) {
+	podClaims := make(map[string][]string)
+
+	if vs.source != nil {
+		sourcePods, err := vs.source.List(cache.Pods, namespace)
+		if err == nil {
+			for _, podData := range sourcePods {
+				metadata, _ := podData["metadata"].(map[string]interface{})
+				spec, _ := podData["spec"].(map[string]interface{})
+				podName, _ := metadata["name"].(string)
+				volumes, _ := spec["volumes"].([]interface{})
+				for _, v := range volumes {
+					volumeMap, ok := v.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					if claim, ok := volumeMap["persistentVolumeClaim"].(map[string]interface{}); ok {
+						if claimName, ok := claim["claimName"].(string); ok {
+							podClaims[podName] = append(podClaims[podName], claimName)
+						}
+					}
+				}
+			}
+			return podClaims, nil
+		}
+		if !errors.Is(err, cache.ErrNotSynced) {
+			return nil, err
+		}
+	}
+
+	if vs.client == nil {
+		return nil, fmt.Errorf("no API client available to list pods")
+	}
+
+	pods, err := vs.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				podClaims[pod.Name] = append(podClaims[pod.Name], volume.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	return podClaims, nil
+}
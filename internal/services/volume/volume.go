@@ -35,6 +35,8 @@ type VolumeDetails struct {
 	Robustness       string                 `json:"robustness,omitempty"`
 	State            string                 `json:"state,omitempty"`
 	NumberOfReplicas int                    `json:"numberOfReplicas,omitempty"`
+	CurrentNodeID    string                 `json:"currentNodeID,omitempty"`
+	MigrationNodeID  string                 `json:"currentMigrationNodeID,omitempty"`
 	BackendDetails   map[string]interface{} `json:"backendDetails,omitempty"`
 }
 
@@ -9,13 +9,33 @@ class HarvesterDashboardApp {
     init() {
         this.bindEvents();
         this.subscribeToStateChanges();
-        this.startDataFetching();
+        this.startLiveUpdates();
+    }
+
+    // Prefer the live stream; fall back to polling /data if it is unavailable
+    startLiveUpdates() {
+        ViewManager.updateUpgradeStatus('info', 'Connecting to server...');
+        WebSocketManager.connect(() => {
+            this.startDataFetching();
+            setInterval(() => {
+                this.fetchData().catch(() => ViewManager.updateUpgradeStatus('error', 'Refresh failed'));
+            }, CONFIG.API.REFRESH_INTERVAL);
+        });
+    }
+
+    async fetchData() {
+        const response = await fetch(CONFIG.API.ENDPOINT);
+        if (!response.ok) {
+            throw new Error(`Server error: ${response.status} ${response.statusText}`);
+        }
+        const data = await response.json();
+        AppState.updateData(data);
     }
 
     async startDataFetching() {
         try {
             ViewManager.updateUpgradeStatus('info', 'Connecting to server...'); 
-            const response = await fetch('/data');
+            const response = await fetch(CONFIG.API.ENDPOINT);
             
             // Check if the response is ok
             if (!response.ok) {
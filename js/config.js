@@ -2,6 +2,7 @@
 const CONFIG = {
     API: {
         ENDPOINT: '/data',
+        STREAM_ENDPOINT: '/api/stream',
         REFRESH_INTERVAL: 30000, // 30 seconds
         RETRY_DELAY: 5000, // 5 seconds
         MAX_RETRIES: 5
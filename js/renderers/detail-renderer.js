@@ -790,15 +790,16 @@ const DetailRenderer = {
                             ${this.renderVMIDetails(vmData)}
                             ${this.renderPodDetails(vmData, splitBrainInfo)}
                             ${this.renderVMErrors(vmData.errors || [])}
-                            ${this.renderVolumeAttachment(vmData.attachmentTicketsRaw)}
                             ${this.renderMigration(vmData.vmimInfo || [], vmData.vmiInfo)}
                         </div>
 
                         <!-- Right Column: Storage & Replicas -->
                         <div class="space-y-6">
-                            ${this.renderVMStorage(vmData)}   
-                            ${this.renderStorageReplicas(vmData)}
-                            ${this.renderVolumeAttachment(vmData.attachmentTicketsRaw)}
+                            ${VolumeViews.getDiskViews(vmData).map(diskData => `
+                                ${this.renderVMStorage(diskData)}
+                                ${this.renderStorageReplicas(diskData)}
+                                ${this.renderVolumeAttachment(VolumeViews.mergeAttachmentTicketsData(diskData))}
+                            `).join('')}
                         </div>
                     </div>
                 </div>
@@ -1697,10 +1698,12 @@ const DetailRenderer = {
     },
 
     renderVMStorage(vmData) {
+        const diskLabel = vmData.disks && vmData.disks.length > 1 ? `: ${vmData.claimNames}` : '';
         return `
             <div class="bg-slate-700/50 border border-slate-600 rounded-lg p-4">
                 <div class="flex items-center gap-2 mb-4">
-                    <h2 class="text-lg font-medium text-white">Storage</h2>
+                    <h2 class="text-lg font-medium text-white">Storage${diskLabel}</h2>
+                    ${vmData.hotplug ? '<span class="px-2 py-1 text-xs rounded bg-blue-700/80 text-blue-200">Hotplug</span>' : ''}
                 </div>
                 
                 <div class="space-y-4">
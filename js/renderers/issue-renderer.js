@@ -306,7 +306,7 @@ const IssueRenderer = {
                         `).join('')}
                     </div>
                 </div>
-                ` : timeline?.nodes?.size > 0 ? `
+                ` : timeline?.nodes?.length > 0 ? `
                 <div class="bg-slate-800/60 border border-slate-600 rounded-lg p-4">
                     <h4 class="text-lg font-medium text-white mb-4 flex items-center gap-2">
                         Migration Timeline
@@ -314,7 +314,7 @@ const IssueRenderer = {
                     
                     <div class="bg-slate-700/30 rounded p-3">
                         <div class="text-sm text-slate-300 mb-2">Migration detected between nodes:</div>
-                        <div class="text-sm text-blue-300 font-mono">${Array.from(timeline.nodes).join(' → ')}</div>
+                        <div class="text-sm text-blue-300 font-mono">${timeline.nodes.join(' → ')}</div>
                         <div class="text-xs text-slate-400 mt-2">Timeline data not available - this indicates a long-running migration</div>
                     </div>
                 </div>
@@ -537,7 +537,7 @@ const IssueRenderer = {
         );
 
         const replicaHealthCard = (issue.resourceType === 'replica-faulted' && vm)
-            ? this.renderReplicaHealthCard(vm, issue)
+            ? this.renderReplicaHealthCard(VolumeViews.getDiskView(vm, issue.resourceName), issue)
             : '';
 
         return `
@@ -692,30 +692,20 @@ const IssueRenderer = {
             return;
         }
         
-        const vm = AppState.data?.vms?.find(v => 
-            v.name === issue.vmName && v.namespace === issue.vmNamespace
-        );
-        
         const resultDiv = document.getElementById(`test-log-result-${issueId}`);
         resultDiv.innerHTML = '<div class="text-yellow-300 flex items-center gap-2"><span class="animate-pulse">●</span> Analyzing logs...</div>';
         
+        // The backend looks up the issue's VM, volume and attachment state
         const requestBody = {
             issue_id: issue.id,
-            issue_type: issue.resourceType,
-            vm_name: issue.vmName || '',
-            namespace: issue.vmNamespace || '',
-            volume_name: vm?.volumeName || '',
-            volume_robustness: vm?.volumeRobustness || '',
-            volume_state: vm?.volumeState || '',
-            replica_count: vm?.replicaInfo?.length || 0,
-            faulted_count: vm?.replicaInfo?.filter(r => r.currentState === 'error' || !r.started).length || 0,
-            source_node: issue.sourceNode || '',
-            target_node: issue.targetNode || '',
             time_window: '1h',
             provider: document.getElementById(`ai-provider-select-${issueId}`)?.value || 'pattern-engine'
         };
         
-        fetch('/api/analyze-logs', {
+        // The pattern engine returns every finding, ranked; LLM providers
+        // return a single root cause
+        const patternOnly = requestBody.provider === 'pattern-engine';
+        fetch(patternOnly ? '/api/pattern-analysis' : '/api/analyze-logs', {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(requestBody)
@@ -727,6 +717,10 @@ const IssueRenderer = {
             return res.json();
         })
         .then(data => {
+            if (patternOnly) {
+                resultDiv.innerHTML = this.renderPatternAnalysis(data);
+                return;
+            }
             const isPatternEngine = data.provider === 'pattern-engine';
             const confidenceColor = data.confidence === 'high' ? 'text-green-300' : data.confidence === 'medium' ? 'text-yellow-300' : 'text-slate-300';
             const providerBadge = isPatternEngine
@@ -744,8 +738,30 @@ const IssueRenderer = {
                    </div>`
                 : '';
 
+            // The LLM answer was unusable, so this is the pattern engine's
+            // result; show why, with the raw reply for reference
+            const escapeHtml = text => String(text).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+            const fallbackHtml = data.fallback_reason
+                ? `<div class="bg-amber-900/30 border border-amber-600/40 rounded p-2 text-xs text-amber-200">
+                    ${escapeHtml(data.fallback_reason)}. Showing the pattern engine result instead.
+                    ${data.raw_response ? `<details class="mt-1">
+                        <summary class="cursor-pointer text-amber-300">Raw LLM response</summary>
+                        <pre class="mt-1 text-slate-300 whitespace-pre-wrap break-all max-h-64 overflow-y-auto">${escapeHtml(data.raw_response)}</pre>
+                    </details>` : ''}
+                   </div>`
+                : '';
+
+            const prompt = data.prompt;
+            const promptHtml = prompt
+                ? `<details class="mt-3 pt-3 border-t border-slate-600 text-xs">
+                    <summary class="cursor-pointer text-slate-400">Prompt sent: ~${prompt.estimated_tokens} tokens${prompt.budget ? ` of ${prompt.budget}` : ''}, ${prompt.log_lines} log lines${prompt.omitted_log_lines ? `, ${prompt.omitted_log_lines} older lines left out` : ''}${prompt.dropped_sections ? `, left out: ${prompt.dropped_sections.join(', ')}` : ''}</summary>
+                    <pre class="mt-1 text-slate-300 whitespace-pre-wrap break-all max-h-96 overflow-y-auto">${escapeHtml(prompt.text)}</pre>
+                   </details>`
+                : '';
+
             resultDiv.innerHTML = `
                 <div class="bg-slate-700/50 rounded-lg p-4 border border-slate-600 space-y-2">
+                    ${fallbackHtml}
                     <div class="flex items-center justify-between">
                         <div class="flex items-center gap-2">
                             <span class="text-green-400 font-medium">Analysis Complete</span>
@@ -770,6 +786,7 @@ const IssueRenderer = {
                         <div class="text-sm text-blue-200 mt-0.5">${data.recommended_action}</div>
                     </div>
                     ${errorLinesHtml}
+                    ${promptHtml}
                 </div>
             `;
         })
@@ -777,6 +794,116 @@ const IssueRenderer = {
             resultDiv.innerHTML = `<div class="text-red-300 text-xs p-3 bg-red-900/20 rounded border border-red-600/30">Error: ${err.message}</div>`;
         });
     },
+    // Renders a pattern engine AnalysisResult: summary counts, then each hint
+    // in rank order with the root cause marked, then the correlations
+    renderPatternAnalysis(data) {
+        const summary = data.summary || {};
+        const hints = data.hints || [];
+        const correlations = data.correlations || [];
+        const header = `
+            <div class="flex items-center justify-between">
+                <div class="flex items-center gap-2">
+                    <span class="text-green-400 font-medium">Analysis Complete</span>
+                    <span class="px-2 py-0.5 text-xs rounded bg-blue-700/60 text-blue-200">offline / no API cost</span>
+                </div>
+                <span class="text-xs text-slate-400">${(data.duration_ns / 1e6).toFixed(1)} ms</span>
+            </div>
+            <div class="text-xs text-slate-400">
+                ${summary.matches_found || 0} of ${summary.total_patterns || 0} patterns matched:
+                <span class="text-red-300">${summary.critical_issues || 0} critical</span>,
+                <span class="text-yellow-300">${summary.warning_issues || 0} warning</span>,
+                <span class="text-blue-300">${summary.info_issues || 0} info</span>,
+                ${summary.correlations || 0} correlations
+            </div>`;
+
+        if (hints.length === 0) {
+            return `
+                <div class="bg-slate-700/50 rounded-lg p-4 border border-slate-600 space-y-2">
+                    ${header}
+                    <div class="text-sm text-slate-300">No known patterns matched in the collected logs. Review logs manually or try an LLM provider for deeper analysis.</div>
+                </div>`;
+        }
+
+        const hintsHtml = hints.map((hint, i) => `
+            <div class="bg-slate-800/60 rounded p-3 border ${hint.pattern_id === data.root_cause ? 'border-orange-500/60' : 'border-slate-600'} space-y-2">
+                <div class="flex items-center justify-between gap-2">
+                    <div class="text-sm text-white">${i + 1}. ${hint.summary}</div>
+                    <div class="flex items-center gap-2 shrink-0">
+                        ${hint.pattern_id === data.root_cause ? '<span class="px-2 py-0.5 text-xs rounded bg-orange-700/60 text-orange-200">root cause</span>' : ''}
+                        <span class="px-2 py-0.5 text-xs rounded ${Utils.getSeverityBadgeClass(hint.severity)}">${hint.severity.toUpperCase()}</span>
+                    </div>
+                </div>
+                <div class="text-xs text-slate-400">
+                    ${hint.pattern_id} &middot; ${hint.confidence} &middot; score ${hint.score.toFixed(2)} &middot; ${hint.occurrence_count} occurrences
+                </div>
+                <div class="text-xs text-slate-300 whitespace-pre-wrap break-all">${hint.explanation}</div>
+                ${hint.suggestion ? `<div class="text-sm text-blue-200">${hint.suggestion}</div>` : ''}
+                ${hint.command ? `
+                    <div class="flex items-center gap-2">
+                        <code class="text-xs text-green-300 font-mono bg-slate-900/60 rounded px-2 py-1 block flex-1 break-all">${hint.command}</code>
+                        <button onclick="Utils.copyToClipboard(this.previousElementSibling.textContent)" class="text-xs text-slate-400 hover:text-white">Copy</button>
+                    </div>` : ''}
+                ${this.renderPatternResources(hint.resources || [])}
+                ${(hint.references || []).length > 0 ? `
+                    <div class="text-xs space-y-0.5">
+                        ${hint.references.map(ref => `<a href="${ref}" target="_blank" rel="noopener" class="text-blue-400 hover:underline block break-all">${ref}</a>`).join('')}
+                    </div>` : ''}
+            </div>
+        `).join('');
+
+        const correlationsHtml = correlations.length > 0
+            ? `<div class="mt-3 pt-3 border-t border-slate-600">
+                <div class="text-xs text-slate-400 mb-1">Correlations:</div>
+                <div class="space-y-1">
+                    ${correlations.map(c => `
+                        <div class="text-xs text-slate-300">
+                            <span class="text-orange-300">${c.cause || c.pattern_id_1}</span> &rarr;
+                            <span class="text-orange-300">${c.effect || c.pattern_id_2}</span>
+                            ${c.cause ? `<span class="text-slate-500">(${Math.round((c.gap_ns || 0) / 1e9)}s later)</span>` : ''}
+                            &mdash; ${c.message}
+                        </div>`).join('')}
+                </div>
+               </div>`
+            : '';
+
+        return `
+            <div class="bg-slate-700/50 rounded-lg p-4 border border-slate-600 space-y-3">
+                ${header}
+                ${hintsHtml}
+                ${correlationsHtml}
+            </div>
+        `;
+    },
+    // Renders the resources a finding names, linked to their VM or node when
+    // they were found in the cluster
+    renderPatternResources(resources) {
+        if (resources.length === 0) {
+            return '';
+        }
+        const statusClass = {
+            unhealthy: 'text-red-300',
+            recovered: 'text-green-300',
+            'not-found': 'text-slate-500'
+        };
+        const rows = resources.map(r => {
+            const links = [];
+            if (r.vm) {
+                const [ns, name] = r.vm.split('/');
+                links.push(`<button onclick="ViewManager.showVMDetail('${name}', '${ns}')" class="text-blue-400 hover:underline">VM ${r.vm}</button>`);
+            }
+            if (r.node) {
+                links.push(`<button onclick="ViewManager.showNodeDetail('${r.node}')" class="text-blue-400 hover:underline">node ${r.node}</button>`);
+            }
+            return `
+                <div class="flex items-center gap-2 flex-wrap">
+                    <span class="text-slate-400">${r.kind}</span>
+                    <code class="font-mono text-slate-200 break-all">${r.name}</code>
+                    ${r.status ? `<span class="${statusClass[r.status] || 'text-slate-300'}">${r.status}${r.state ? ` (${r.state})` : ''}</span>` : ''}
+                    ${links.join('')}
+                </div>`;
+        }).join('');
+        return `<div class="text-xs space-y-1">${rows}</div>`;
+    },
     renderUpgradeBlockedMigrationDetail(issue) {
         const ud = issue.upgradeDetails || {};
         const stuckNodes = ud.stuckPreDrainNodes || [];
@@ -924,9 +1051,6 @@ const IssueRenderer = {
                     <div class="flex items-center gap-3 flex-wrap">
                         <select id="ai-provider-select-${issue.id}" class="bg-slate-700 text-white text-sm px-3 py-2 rounded-md border border-slate-500">
                             <option value="pattern-engine" selected>Pattern Engine (offline, free)</option>
-                            <option value="openwebui">OpenWebUI (qwen3)</option>
-                            <option value="ollama">Ollama (local)</option>
-                            <option value="gemini">Gemini</option>
                         </select>
                         <button class="bg-purple-600 text-white px-4 py-2 rounded-md hover:bg-purple-700 transition-colors text-sm"
                             onclick="IssueRenderer.testLogAnalysis('${issue.id}', '${issue.resourceType}', '${issue.vmName || ''}')">
@@ -939,6 +1063,28 @@ const IssueRenderer = {
         `;
     },
 
+    // Fills the provider select of an issue from /api/providers. Unavailable
+    // providers stay listed but disabled, with the reason as a tooltip.
+    loadProviders(issueId) {
+        const select = document.getElementById(`ai-provider-select-${issueId}`);
+        if (!select) return;
+        fetch('/api/providers')
+            .then(response => {
+                if (!response.ok) throw new Error(`HTTP ${response.status}`);
+                return response.json();
+            })
+            .then(providers => {
+                select.innerHTML = providers.map(p => {
+                    const label = p.type === 'pattern-engine'
+                        ? 'Pattern Engine (offline, free)'
+                        : `${p.name}${p.model ? ` (${p.model})` : ''}${p.available ? '' : ' - unavailable'}`;
+                    return `<option value="${p.name}" ${p.available ? '' : 'disabled'} ${p.error ? `title="${p.error.replace(/"/g, '&quot;')}"` : ''}>${label}</option>`;
+                }).join('');
+                select.value = 'pattern-engine';
+            })
+            .catch(err => console.error('Failed to load providers:', err));
+    },
+
     renderIssueDetail(issue) {
         // Decide which renderer to use based on issue type
         if (issue.resourceType === 'upgrade-blocked-migration') {
@@ -116,27 +116,30 @@ class UniversalSearch {
                 score += vm.name.toLowerCase() === lowerQuery ? 100 : 50;
             }
 
-            // Check PVC name
-            if (vm.claimNames && vm.claimNames.toLowerCase().includes(lowerQuery)) {
-                matchTypes.push('PVC Name');
-                score += vm.claimNames.toLowerCase() === lowerQuery ? 100 : 40;
-            }
-
-            // Check Volume handle
-            if (vm.volumeName && vm.volumeName.toLowerCase().includes(lowerQuery)) {
-                matchTypes.push('Volume Handle');
-                score += vm.volumeName.toLowerCase() === lowerQuery ? 100 : 30;
-            }
-
-            // Check Replica names
-            if (vm.replicaInfo && vm.replicaInfo.length > 0) {
-                vm.replicaInfo.forEach(replica => {
-                    if (replica.name && replica.name.toLowerCase().includes(lowerQuery)) {
-                        matchTypes.push('Replica');
-                        score += 20;
-                    }
-                });
-            }
+            // Check every disk of the VM
+            VolumeViews.getDiskViews(vm).forEach(disk => {
+                // Check PVC name
+                if (disk.claimNames && disk.claimNames.toLowerCase().includes(lowerQuery)) {
+                    matchTypes.push('PVC Name');
+                    score += disk.claimNames.toLowerCase() === lowerQuery ? 100 : 40;
+                }
+
+                // Check Volume handle
+                if (disk.volumeName && disk.volumeName.toLowerCase().includes(lowerQuery)) {
+                    matchTypes.push('Volume Handle');
+                    score += disk.volumeName.toLowerCase() === lowerQuery ? 100 : 30;
+                }
+
+                // Check Replica names
+                if (disk.replicaInfo && disk.replicaInfo.length > 0) {
+                    disk.replicaInfo.forEach(replica => {
+                        if (replica.name && replica.name.toLowerCase().includes(lowerQuery)) {
+                            matchTypes.push('Replica');
+                            score += 20;
+                        }
+                    });
+                }
+            });
 
             // Check Pod name
             if (vm.podName && vm.podName.toLowerCase().includes(lowerQuery)) {
@@ -8,6 +8,7 @@ const AppState = {
     
     issues: [],
     observers: [],
+    pendingUpdate: null,
     
     getAllRealIssues() {
         if (!this.issues || this.issues.length === 0) {
@@ -29,10 +30,44 @@ const AppState = {
     updateData(newData) {
         this.data = { ...this.data, ...newData };
         
-        this.issues = IssueDetector.detectIssues(this.data);
+        // Issues are detected server-side and arrive with the data
+        this.issues = this.data.issues || [];
         
         this.notifyStateChange();
     },
+
+    // Apply a streamed VM delta: { type: added|updated|removed, namespace, name, vm }
+    applyVMDelta(delta) {
+        const vms = (this.data.vms || []).filter(vm =>
+            !(vm.name === delta.name && vm.namespace === delta.namespace)
+        );
+        if (delta.type !== 'removed' && delta.vm) {
+            vms.push(delta.vm);
+        }
+        this.data.vms = vms;
+        this.scheduleUpdate();
+    },
+
+    // Apply a streamed node delta: { type: added|updated|removed, name, node }
+    applyNodeDelta(delta) {
+        const nodes = (this.data.nodes || []).filter(node =>
+            (node.longhornInfo && node.longhornInfo.name) !== delta.name
+        );
+        if (delta.type !== 'removed' && delta.node) {
+            nodes.push(delta.node);
+        }
+        this.data.nodes = nodes;
+        this.scheduleUpdate();
+    },
+
+    // Deltas arrive in bursts; re-render once per burst
+    scheduleUpdate() {
+        if (this.pendingUpdate) return;
+        this.pendingUpdate = setTimeout(() => {
+            this.pendingUpdate = null;
+            this.updateData({});
+        }, 100);
+    },
     
     subscribe(callback) {
         this.observers.push(callback);
@@ -188,6 +188,7 @@ const ViewManager = {
         this.hideAllViews();
         document.getElementById('issue-detail-container').classList.remove('hidden');
         this.currentView = 'issue-detail';
+        IssueRenderer.loadProviders(issue.id);
     },
     renderRemediationCommand(step) {
     // Check if this is a documentation reference step
@@ -0,0 +1,60 @@
+// Per-disk views of VM volume data
+const VolumeViews = {
+    // Merge attachment ticket status and spec data for backward compatibility
+    mergeAttachmentTicketsData(vm) {
+        if (!vm.attachmentTicketsStatusRaw && !vm.attachmentTicketsSpecRaw) {
+            return null;
+        }
+
+        const mergedData = {};
+        
+        // Start with status data (timestamps, satisfied status, conditions)
+        if (vm.attachmentTicketsStatusRaw && typeof vm.attachmentTicketsStatusRaw === 'object') {
+            Object.entries(vm.attachmentTicketsStatusRaw).forEach(([ticketId, statusData]) => {
+                mergedData[ticketId] = { ...statusData };
+            });
+        }
+
+        // Add spec data (nodeID mappings, type, etc.)
+        if (vm.attachmentTicketsSpecRaw && typeof vm.attachmentTicketsSpecRaw === 'object') {
+            Object.entries(vm.attachmentTicketsSpecRaw).forEach(([ticketId, specData]) => {
+                if (mergedData[ticketId]) {
+                    // Merge spec data into existing status data
+                    mergedData[ticketId] = { ...mergedData[ticketId], ...specData };
+                } else {
+                    // Only spec data available
+                    mergedData[ticketId] = { ...specData };
+                }
+            });
+        }
+
+        return Object.keys(mergedData).length > 0 ? mergedData : null;
+    },
+
+    // Returns one VM-shaped view per disk, with the top-level volume fields
+    // (volumeName, replicaInfo, attachment tickets, ...) taken from that disk
+    getDiskViews(vm) {
+        if (!vm.disks || vm.disks.length === 0) {
+            return [vm];
+        }
+        return vm.disks.map(disk => ({
+            ...vm,
+            claimNames: disk.claimName,
+            volumeName: disk.volumeName,
+            pvcStatus: disk.pvcStatus,
+            volumeRobustness: disk.volumeRobustness,
+            volumeState: disk.volumeState,
+            volumeNumberOfReplicas: disk.volumeNumberOfReplicas,
+            replicaInfo: disk.replicaInfo,
+            engineInfo: disk.engineInfo,
+            attachmentTicketsStatusRaw: disk.attachmentTicketsStatusRaw,
+            attachmentTicketsSpecRaw: disk.attachmentTicketsSpecRaw,
+            hotplug: disk.hotplug
+        }));
+    },
+
+    // Returns the view of the disk backed by volumeName, or the VM itself
+    getDiskView(vm, volumeName) {
+        return this.getDiskViews(vm).find(view => view.volumeName === volumeName) || vm;
+    }
+};
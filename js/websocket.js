@@ -1,6 +1,57 @@
-// HTTP data fetching (replaces WebSocket)
+// Live cluster updates over Server-Sent Events.
+// The server sends one 'snapshot' event with the full cluster data, then
+// 'vm', 'node' and 'cluster' delta events as resources change.
 const WebSocketManager = {
-    connect() {
-        // No longer needed - app.js handles the HTTP fetching
+    source: null,
+    failures: 0,
+
+    connect(onUnavailable) {
+        if (!window.EventSource) {
+            onUnavailable();
+            return;
+        }
+
+        this.disconnect();
+        this.source = new EventSource(CONFIG.API.STREAM_ENDPOINT);
+
+        this.source.onopen = () => {
+            this.failures = 0;
+        };
+
+        this.source.addEventListener('snapshot', (event) => {
+            AppState.updateData(JSON.parse(event.data));
+        });
+
+        this.source.addEventListener('vm', (event) => {
+            AppState.applyVMDelta(JSON.parse(event.data));
+        });
+
+        this.source.addEventListener('node', (event) => {
+            AppState.applyNodeDelta(JSON.parse(event.data));
+        });
+
+        this.source.addEventListener('cluster', (event) => {
+            AppState.updateData(JSON.parse(event.data));
+        });
+
+        // EventSource reconnects on its own; give up after repeated failures
+        this.source.onerror = () => {
+            this.failures++;
+            if (this.failures >= CONFIG.API.MAX_RETRIES) {
+                this.disconnect();
+                onUnavailable();
+            }
+        };
+    },
+
+    disconnect() {
+        if (this.source) {
+            this.source.close();
+            this.source = null;
+        }
+    },
+
+    isConnected() {
+        return this.source !== null && this.source.readyState === EventSource.OPEN;
     }
 };
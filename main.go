@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -10,17 +12,23 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	kubeclient "github.com/rk280392/harvesterNavigator/internal/client"
 	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/bundle"
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
+	"github.com/rk280392/harvesterNavigator/internal/services/health"
+	"github.com/rk280392/harvesterNavigator/internal/services/history"
 	"github.com/rk280392/harvesterNavigator/internal/services/loganalysis"
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
 	"github.com/rk280392/harvesterNavigator/internal/services/patternengine"
 	"github.com/rk280392/harvesterNavigator/internal/services/volume"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 //go:embed index.html js/* styles/*
@@ -28,6 +36,12 @@ var staticFiles embed.FS
 
 var version = "dev"
 
+// patternReloadInterval is how often the custom pattern directory is checked for changes
+const patternReloadInterval = 10 * time.Second
+
+// providerCheckInterval is how often the LLM providers are health-checked
+const providerCheckInterval = time.Minute
+
 func determineKubeconfigPath() (string, string, error) {
 	if kubeconfigEnv := os.Getenv("KUBECONFIG"); kubeconfigEnv != "" {
 		separator := ":"
@@ -127,6 +141,44 @@ func logStorageBackends(clientset *kubernetes.Clientset) {
 	log.Println("=====================================")
 }
 
+// connectToCluster locates the kubeconfig and creates the typed and dynamic clients
+func connectToCluster() (*kubernetes.Clientset, dynamic.Interface) {
+	kubeconfigPath, source, err := determineKubeconfigPath()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := validateKubeconfig(kubeconfigPath); err != nil {
+		log.Fatalf("Error: Invalid kubeconfig file at %s: %v", kubeconfigPath, err)
+	}
+
+	log.Printf("Using kubeconfig: %s", kubeconfigPath)
+	log.Printf("Source: %s", source)
+
+	config, err := kubeclient.GetConfig(kubeconfigPath)
+	if err != nil {
+		log.Fatalf("Error creating Kubernetes config: %v", err)
+	}
+	clientset, err := kubeclient.CreateClientWithConfig(config)
+	if err != nil {
+		log.Fatalf("Error creating Kubernetes client: %v", err)
+	}
+	log.Println("Kubernetes client initialized.")
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		log.Printf("Warning: Could not retrieve server version (connectivity issue?): %v", err)
+	} else {
+		log.Printf("Connected to Kubernetes cluster (version: %s)", serverVersion.String())
+	}
+	logStorageBackends(clientset)
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		log.Fatalf("Error creating dynamic client: %v", err)
+	}
+	return clientset, dynamicClient
+}
+
 func getDefaultResourcePaths(namespace string) types.ResourcePaths {
 	return types.ResourcePaths{
 		VMPath:           "apis/kubevirt.io/v1",
@@ -145,17 +197,10 @@ func getDefaultResourcePaths(namespace string) types.ResourcePaths {
 	}
 }
 
-func handleData(clientset *kubernetes.Clientset, config *rest.Config) http.HandlerFunc {
+func handleData(dataFetcher *DataFetcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		dynamicClient, err := dynamic.NewForConfig(config)
-		if err != nil {
-			log.Printf("Warning: Could not create dynamic client: %v", err)
-			http.Error(w, "Failed to create dynamic client", http.StatusInternalServerError)
-			return
-		}
-		dataFetcher := CreateDataFetcher(clientset, dynamicClient)
 		data, err := dataFetcher.fetchFullClusterData()
 		if err != nil {
 			log.Printf("Error: %v", err)
@@ -173,7 +218,41 @@ func handleData(clientset *kubernetes.Clientset, config *rest.Config) http.Handl
 	}
 }
 
-func handleAnalyzeLogs(clientset *kubernetes.Clientset) http.HandlerFunc {
+// patternEngineProvider names the offline pattern engine, which is always
+// available and not part of the LLM provider registry
+const patternEngineProvider = "pattern-engine"
+
+// enrichAnalysisRequest fills a log analysis request from the stream's
+// cluster data, so clients only need to send the issue ID. The cluster data
+// is returned for reuse, or nil when there is none and the request is used as
+// sent. It reports false after answering with an error: 503 when an issue ID
+// cannot be looked up without cluster data, 404 for an unknown issue.
+func enrichAnalysisRequest(w http.ResponseWriter, clusterStream *ClusterStream, req *types.LogAnalysisRequest) (*types.FullClusterData, bool) {
+	data, ok := clusterStream.current()
+	if !ok {
+		if req.IssueID != "" {
+			http.Error(w, "Cluster data not available to look up the issue", http.StatusServiceUnavailable)
+			return nil, false
+		}
+		log.Printf("Warning: No cluster data for log analysis, using the request as sent")
+		return nil, true
+	}
+	csiAttachments, err := clusterStream.fetcher.listCSIVolumeAttachments()
+	if err != nil {
+		log.Printf("Warning: Could not list CSI volume attachments: %v", err)
+	}
+	if err := loganalysis.EnrichRequest(req, data, csiAttachments); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, loganalysis.ErrIssueNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return nil, false
+	}
+	return data, true
+}
+
+func handleAnalyzeLogs(logSource loganalysis.LogSource, clusterStream *ClusterStream, providers *loganalysis.ProviderRegistry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -185,64 +264,27 @@ func handleAnalyzeLogs(clientset *kubernetes.Clientset) http.HandlerFunc {
 			http.Error(w, "Invalid request", http.StatusBadRequest)
 			return
 		}
-
-		// Default to gemini if not specified
-		if req.Provider == "" {
-			req.Provider = "gemini"
+		if _, ok := enrichAnalysisRequest(w, clusterStream, &req); !ok {
+			return
 		}
 
 		// Collect logs upfront — needed by both pattern engine and LLM providers
-		logs, err := loganalysis.CollectLogsForIssue(r.Context(), clientset, req)
+		// A failed collection is told to the model as such, never passed on
+		// as log lines
+		logs, err := loganalysis.CollectLogsForIssue(r.Context(), logSource, req)
 		if err != nil {
 			log.Printf("Warning: Could not collect logs: %v", err)
-			logs = fmt.Sprintf("(Log collection failed: %v)", err)
+			logs, req.LogError = "", err.Error()
 		} else {
 			log.Printf("Collected %d characters of logs for issue_type=%s vm=%s", len(logs), req.IssueType, req.VMName)
-			if len(logs) > 0 {
-				preview := logs
-				if len(preview) > 500 {
-					preview = preview[:500]
-				}
-				log.Printf("Log preview:\n%s", preview)
-			}
 		}
 
-		// Create the appropriate analyzer
-		var analyzer loganalysis.LogAnalyzer
-
-		switch req.Provider {
-		case "gemini":
-			apiKey := os.Getenv("GEMINI_API_KEY")
-			if apiKey == "" {
-				http.Error(w, "GEMINI_API_KEY environment variable not set", http.StatusInternalServerError)
-				return
-			}
-			geminiAnalyzer, err := loganalysis.NewGeminiAnalyzer(r.Context(), apiKey)
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to create Gemini analyzer: %v", err), http.StatusInternalServerError)
-				return
-			}
-			defer func() { _ = geminiAnalyzer.Close() }()
-			analyzer = geminiAnalyzer
-		case "ollama":
-			ollamaAnalyzer, err := loganalysis.NewOllamaAnalyzer("http://localhost:11434", "mixtral:8x7b")
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to create Ollama analyzer: %v", err), http.StatusInternalServerError)
-				return
-			}
-			analyzer = ollamaAnalyzer
-		case "openwebui":
-			apiKey := os.Getenv("OPENWEBUI_API_KEY")
-			url := os.Getenv("OPENWEBUI_URL")
-			collectionID := os.Getenv("OPENWEBUI_COLLECTION_ID")
-			openwebuiAnalyzer, err := loganalysis.NewOpenwebuiAnalyzer(url, "qwen3:latest", apiKey, collectionID)
-			if err != nil {
-				http.Error(w, fmt.Sprintf("Failed to create OpenWebUI analyzer: %v", err), http.StatusInternalServerError)
+		if req.Provider == patternEngineProvider {
+			// Direct pattern-engine request — bypass the LLM entirely
+			if req.LogError != "" {
+				http.Error(w, fmt.Sprintf("Could not collect logs: %s", req.LogError), http.StatusInternalServerError)
 				return
 			}
-			analyzer = openwebuiAnalyzer
-		case "pattern-engine":
-			// Direct pattern-engine request — bypass the LLM entirely
 			pe := patternengine.NewAnalyzer()
 			peResult, peErr := pe.AnalyzeLogs(r.Context(), logs)
 			if peErr != nil {
@@ -250,32 +292,35 @@ func handleAnalyzeLogs(clientset *kubernetes.Clientset) http.HandlerFunc {
 				return
 			}
 			if peResult == nil {
-				peResult = &types.LogAnalysisResult{
-					Provider:          "pattern-engine",
-					RootCause:         "No known patterns matched in the collected logs",
-					RecommendedAction: "Review logs manually or try an LLM provider for deeper analysis",
-					Confidence:        "low",
-				}
+				peResult = noPatternMatch()
 			}
 			w.Header().Set("Content-Type", "application/json")
 			if encErr := json.NewEncoder(w).Encode(peResult); encErr != nil {
 				log.Printf("JSON encoding error: %v", encErr)
 			}
 			return
-		case "stub":
-			analyzer = loganalysis.NewStubAnalyzer()
-		default:
-			http.Error(w, "Invalid provider. Use 'gemini', 'ollama', 'openwebui', 'pattern-engine', or 'stub'", http.StatusBadRequest)
+		}
+
+		// An empty provider selects the configured default
+		analyzer, ok := providers.Get(req.Provider)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown provider %q, see /api/providers", req.Provider), http.StatusBadRequest)
 			return
 		}
+		req.Provider = analyzer.Name()
 
 		// ── Pattern Engine: offline first-pass analysis ───────────────────────
 		// Run pattern engine before calling any LLM provider.
 		// If high/medium confidence match found, return immediately (zero API cost).
-		if req.Provider != "stub" && logs != "" {
+		var peResult *types.LogAnalysisResult
+		if analyzer.Type() != "stub" && logs != "" {
 			pe := patternengine.NewAnalyzer()
-			peResult, peErr := pe.AnalyzeLogs(r.Context(), logs)
-			if peErr == nil && peResult != nil && (peResult.Confidence == "high" || peResult.Confidence == "medium") {
+			var peErr error
+			peResult, peErr = pe.AnalyzeLogs(r.Context(), logs)
+			if peErr != nil {
+				log.Printf("Warning: Pattern engine failed: %v", peErr)
+			}
+			if peResult != nil && (peResult.Confidence == "high" || peResult.Confidence == "medium") {
 				log.Printf("Pattern engine matched (confidence=%s, component=%s) — skipping LLM", peResult.Confidence, peResult.FailingComponent)
 				w.Header().Set("Content-Type", "application/json")
 				if encErr := json.NewEncoder(w).Encode(peResult); encErr != nil {
@@ -287,19 +332,113 @@ func handleAnalyzeLogs(clientset *kubernetes.Clientset) http.HandlerFunc {
 		}
 		// ─────────────────────────────────────────────────────────────────────
 
-		// Build a simple prompt (we'll improve this later)
-		prompt := loganalysis.BuildAnalysisPrompt(req)
-		fullPrompt := fmt.Sprintf("%s\n\nRELEVANT LOGS:\n%s", prompt, logs)
-		// Call the analyzer
+		// The prompt is returned with the result rather than logged
+		prompt := loganalysis.BuildPrompt(req, logs, analyzer.PromptTokens())
+		log.Printf("Sending %s a prompt of ~%d tokens (budget %d, %d log lines, %d older lines left out)",
+			req.Provider, prompt.EstimatedTokens, prompt.Budget, prompt.LogLines, prompt.OmittedLogLines)
+		result, err := analyzer.Analyze(r.Context(), prompt.Text)
+		var invalid *loganalysis.InvalidResponseError
+		if errors.As(err, &invalid) {
+			// An unusable answer degrades to the pattern engine's best guess
+			// rather than failing the request
+			log.Printf("Warning: %v — falling back to the pattern engine", invalid)
+			result = peResult
+			if result == nil {
+				result = noPatternMatch()
+			}
+			result.FallbackReason = invalid.Error()
+			result.RawResponse = invalid.Raw
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Prompt = prompt
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("JSON encoding error: %v", err)
+		}
+	}
+}
+
+// noPatternMatch is the pattern engine's result when nothing matched
+func noPatternMatch() *types.LogAnalysisResult {
+	return &types.LogAnalysisResult{
+		Provider:          patternEngineProvider,
+		RootCause:         "No known patterns matched in the collected logs",
+		RecommendedAction: "Review logs manually or try an LLM provider for deeper analysis",
+		Confidence:        "low",
+	}
+}
+
+// handleProviders lists the analysis providers for the UI: the pattern
+// engine, then every configured LLM provider with its last health check
+func handleProviders(providers *loganalysis.ProviderRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		list := []loganalysis.ProviderInfo{{Name: patternEngineProvider, Type: patternEngineProvider, Available: true}}
+		list = append(list, providers.Providers()...)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(list); err != nil {
+			log.Printf("JSON encoding error: %v", err)
+		}
+	}
+}
+
+// handlePatternAnalysis runs only the pattern engine over the logs of an
+// issue and returns every finding, with the resources it names looked up in
+// the cluster, e.g. POST /api/pattern-analysis?min_severity=warning&max_hints=5
+func handlePatternAnalysis(logSource loganalysis.LogSource, clusterStream *ClusterStream) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req types.LogAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		maxHints := 0
+		if value := query.Get("max_hints"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid max_hints %q", value), http.StatusBadRequest)
+				return
+			}
+			maxHints = n
+		}
+		opts, err := analysisOptions(query.Get("min_severity"), query.Get("min_confidence"), maxHints, query.Get("include_info") != "false")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, ok := enrichAnalysisRequest(w, clusterStream, &req)
+		if !ok {
+			return
+		}
 
-		log.Println("===== PROMPT BEING SENT TO AI =====")
-		log.Println(fullPrompt)
-		log.Println("===== END PROMPT =====")
-		result, err := analyzer.Analyze(r.Context(), fullPrompt)
+		logs, err := loganalysis.CollectLogsForIssue(r.Context(), logSource, req)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("Could not collect logs: %v", err), http.StatusInternalServerError)
 			return
 		}
+		result, err := patternengine.NewAnalyzer().Analyze(r.Context(), logs, opts)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Pattern engine error: %v", err), http.StatusUnprocessableEntity)
+			return
+		}
+		if data != nil {
+			patternengine.ResolveResources(result, data)
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(result); err != nil {
@@ -308,9 +447,146 @@ func handleAnalyzeLogs(clientset *kubernetes.Clientset) http.HandlerFunc {
 	}
 }
 
+// analysisOptions builds pattern engine options from user input, rejecting
+// unknown severities and confidences
+func analysisOptions(minSeverity, minConfidence string, maxHints int, includeInfo bool) (patternengine.AnalysisOptions, error) {
+	opts := patternengine.AnalysisOptions{
+		MinSeverity:   patternengine.Severity(minSeverity),
+		MinConfidence: patternengine.Confidence(minConfidence),
+		MaxHints:      maxHints,
+		IncludeInfo:   includeInfo,
+	}
+	switch opts.MinSeverity {
+	case "", patternengine.SeverityCritical, patternengine.SeverityWarning, patternengine.SeverityInfo:
+	default:
+		return opts, fmt.Errorf("invalid min severity %q: use critical, warning or info", minSeverity)
+	}
+	switch opts.MinConfidence {
+	case "", patternengine.ConfidenceCertain, patternengine.ConfidenceLikely, patternengine.ConfidencePossible:
+	default:
+		return opts, fmt.Errorf("invalid min confidence %q: use certain, likely or possible", minConfidence)
+	}
+	if maxHints < 0 {
+		return opts, fmt.Errorf("max hints must not be negative")
+	}
+	return opts, nil
+}
+
+// handleHistory serves the recorded transitions of one resource, e.g.
+// /api/history/volume/pvc-1234?since=24h
+func handleHistory(store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if store == nil {
+			http.Error(w, "History is not recorded for support bundles or when disabled", http.StatusNotFound)
+			return
+		}
+
+		kind, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/history/"), "/")
+		if !slices.Contains(history.Kinds, kind) || name == "" {
+			http.Error(w, fmt.Sprintf("Expected /api/history/<kind>/<name> with kind one of: %s", strings.Join(history.Kinds, ", ")), http.StatusBadRequest)
+			return
+		}
+
+		since := time.Now().Add(-24 * time.Hour)
+		if value := r.URL.Query().Get("since"); value != "" {
+			parsed, err := logrecord.ParseSince(value, time.Now())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			since = parsed
+		}
+
+		resource := history.ResourceKey(kind, name)
+		response := struct {
+			Resource string          `json:"resource"`
+			Since    time.Time       `json:"since"`
+			Current  []history.Entry `json:"current"`
+			Entries  []history.Entry `json:"entries"`
+		}{
+			Resource: resource,
+			Since:    since.UTC(),
+			Current:  store.Current(resource),
+			Entries:  store.Query(resource, since),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("JSON encoding error: %v", err)
+		}
+	}
+}
+
+// defaultHistoryPath returns the history file under the user cache directory
+func defaultHistoryPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "harvester-navigator", "history.jsonl")
+}
+
+// setupDataFetcher creates the data fetcher for a support bundle, or for the
+// live cluster when bundlePath is empty. With watch set, live reads are served
+// by a watch-based cache; otherwise every read lists the API server directly.
+func setupDataFetcher(bundlePath string, watch bool, cacheSyncTimeout time.Duration, healthConfig health.Config) (*DataFetcher, *cache.ClusterCache, loganalysis.LogSource) {
+	var dataFetcher *DataFetcher
+	var clusterCache *cache.ClusterCache
+	var logSource loganalysis.LogSource
+
+	if bundlePath != "" {
+		// ── Offline support bundle ───────────────────────────────────────────
+		// Everything, including pod logs, is read from the bundle and no
+		// kubeconfig is needed. Health and PDB checks are skipped.
+		supportBundle, err := bundle.OpenBundle(bundlePath)
+		if err != nil {
+			log.Fatalf("Error opening support bundle: %v", err)
+		}
+		log.Printf("Using support bundle: %s", bundlePath)
+
+		dataFetcher = CreateDataFetcher(nil, nil, supportBundle, healthConfig)
+		logSource = loganalysis.CreateBundleLogSource(supportBundle)
+	} else {
+		clientset, dynamicClient := connectToCluster()
+
+		// ── Shared cluster cache ─────────────────────────────────────────────
+		// Informers keep VMs, Longhorn and core resources in memory so /data
+		// does not re-list the cluster on every request. A cache that is not
+		// started serves nothing, so a single fetch reads the API directly.
+		clusterCache = cache.CreateClusterCache(clientset.Discovery(), dynamicClient)
+		if watch {
+			log.Println("Starting cluster cache...")
+			if err := clusterCache.Start(context.Background(), cacheSyncTimeout); err != nil {
+				log.Printf("Warning: Cluster cache unavailable, falling back to direct API reads: %v", err)
+			}
+		}
+		dataFetcher = CreateDataFetcher(clientset, dynamicClient, clusterCache, healthConfig)
+		logSource = loganalysis.CreateClusterLogSource(clientset)
+	}
+	return dataFetcher, clusterCache, logSource
+}
+
 func main() {
 	port := flag.String("port", "8080", "Port to run the server on")
 	showVersion := flag.Bool("version", false, "Show version and exit")
+	cacheSyncTimeout := flag.Duration("cache-sync-timeout", 60*time.Second, "How long the dashboard waits for the cluster cache to sync at startup; commands read the API directly")
+	bundlePath := flag.String("bundle", "", "Read cluster state from a support bundle (.zip or extracted directory) instead of a live cluster")
+	healthChecks := flag.String("health-checks", "", "Comma-separated health checks to run (default all): "+strings.Join(health.DefaultRegistry.Names(), ", "))
+	skipHealthChecks := flag.String("skip-health-checks", "", "Comma-separated health checks to skip")
+	healthCheckTimeout := flag.Duration("health-check-timeout", health.DefaultCheckTimeout, "Deadline for each health check, after which it reports a timeout")
+	healthCheckInterval := flag.Duration("health-check-interval", health.DefaultInterval, "How often the dashboard re-runs the health checks")
+	historyFile := flag.String("history-file", defaultHistoryPath(), "File that records cluster state transitions")
+	historyInterval := flag.Duration("history-interval", time.Minute, "How often cluster state is sampled into the history")
+	historyRetention := flag.Duration("history-retention", history.DefaultRetention, "How long state transitions are kept")
+	noHistory := flag.Bool("no-history", false, "Do not record cluster state history")
+	patternsDir := flag.String("patterns-dir", "", "Directory of custom log pattern files (*.yaml), reloaded when they change")
+	providersConfig := flag.String("providers-config", "", "YAML file of LLM providers (default: configured from environment variables)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [global flags] [command [flags]]\n\nGlobal flags:\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		printCommands(flag.CommandLine.Output())
+	}
 	flag.Parse()
 
 	if *showVersion {
@@ -318,35 +594,95 @@ func main() {
 		os.Exit(0)
 	}
 
-	log.Printf("Starting Harvester Navigator Backend (version: %s)...", version)
-	kubeconfigPath, source, err := determineKubeconfigPath()
-	if err != nil {
-		log.Fatalf("Error: %v", err)
+	healthConfig := health.DefaultConfig()
+	healthConfig.Enabled = health.ParseCheckList(*healthChecks)
+	healthConfig.Disabled = health.ParseCheckList(*skipHealthChecks)
+	healthConfig.Timeout = *healthCheckTimeout
+	healthConfig.Interval = *healthCheckInterval
+	if healthConfig.Interval <= 0 {
+		log.Fatalf("Error: -health-check-interval must be positive, got %v", healthConfig.Interval)
 	}
-	if err := validateKubeconfig(kubeconfigPath); err != nil {
-		log.Fatalf("Error: Invalid kubeconfig file at %s: %v", kubeconfigPath, err)
+	if *historyInterval <= 0 {
+		log.Fatalf("Error: -history-interval must be positive, got %v", *historyInterval)
+	}
+	for _, name := range append(healthConfig.Enabled, healthConfig.Disabled...) {
+		if !slices.Contains(health.DefaultRegistry.Names(), name) {
+			log.Fatalf("Error: Unknown health check %q (available: %s)", name, strings.Join(health.DefaultRegistry.Names(), ", "))
+		}
 	}
 
-	log.Printf("Using kubeconfig: %s", kubeconfigPath)
-	log.Printf("Source: %s", source)
+	// Custom pattern files are loaded before any subcommand, so that analyze
+	// and patterns test use them too
+	if *patternsDir != "" {
+		if err := patternengine.LoadPatternDir(*patternsDir); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
 
-	config, err := kubeclient.GetConfig(kubeconfigPath)
-	if err != nil {
-		log.Fatalf("Error creating Kubernetes config: %v", err)
+	// Headless subcommands print a single fetch and exit
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args(), func() (types.FullClusterData, error) {
+			dataFetcher, _, _ := setupDataFetcher(*bundlePath, false, *cacheSyncTimeout, healthConfig)
+			return dataFetcher.fetchFullClusterData()
+		}))
 	}
-	clientset, err := kubeclient.CreateClientWithConfig(config)
-	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
+
+	log.Printf("Starting Harvester Navigator Backend (version: %s)...", version)
+
+	dataFetcher, clusterCache, logSource := setupDataFetcher(*bundlePath, true, *cacheSyncTimeout, healthConfig)
+
+	// Health checks call the API server, so they run on their own interval
+	// rather than on every fetch
+	if dataFetcher.client != nil {
+		go dataFetcher.runHealthChecks(context.Background(), healthConfig.Interval)
 	}
-	log.Println("Kubernetes client initialized.")
 
-	serverVersion, err := clientset.Discovery().ServerVersion()
+	clusterStream := CreateClusterStream(dataFetcher, clusterCache)
+	go clusterStream.Run(context.Background())
+
+	// ── State history ────────────────────────────────────────────────────────
+	// A support bundle is a single snapshot, so history is only recorded
+	// against a live cluster.
+	var historyStore *history.Store
+	if *bundlePath == "" && !*noHistory {
+		store, err := history.OpenStore(*historyFile, *historyRetention, history.DefaultMaxEntries)
+		if err != nil {
+			log.Printf("Warning: Could not open history store, history disabled: %v", err)
+		} else {
+			log.Printf("Recording cluster state history in %s every %v", *historyFile, *historyInterval)
+			historyStore = store
+			// Samples come from the stream's snapshot rather than a fetch of their own
+			go historyStore.Run(context.Background(), *historyInterval, clusterStream.latest)
+		}
+	}
+
+	// ── Custom log patterns ──────────────────────────────────────────────────
+	// Patterns from files and labelled ConfigMaps are added to the built-in
+	// ones and picked up by every later analysis.
+	if *patternsDir != "" {
+		log.Printf("Loaded custom patterns from %s", *patternsDir)
+		go patternengine.WatchPatternDir(context.Background(), *patternsDir, patternReloadInterval)
+	}
+	if dataFetcher.client != nil {
+		if err := patternengine.WatchPatternConfigMaps(context.Background(), dataFetcher.client); err != nil {
+			log.Printf("Warning: Custom patterns from ConfigMaps disabled: %v", err)
+		}
+	}
+
+	// ── LLM providers ────────────────────────────────────────────────────────
+	providersFile := loganalysis.EnvProviders()
+	if *providersConfig != "" {
+		file, err := loganalysis.LoadProvidersFile(*providersConfig)
+		if err != nil {
+			log.Fatalf("Failed to load LLM providers: %v", err)
+		}
+		providersFile = file
+	}
+	providers, err := loganalysis.NewProviderRegistry(context.Background(), providersFile)
 	if err != nil {
-		log.Printf("Warning: Could not retrieve server version (connectivity issue?): %v", err)
-	} else {
-		log.Printf("Connected to Kubernetes cluster (version: %s)", serverVersion.String())
+		log.Fatalf("Failed to configure LLM providers: %v", err)
 	}
-	logStorageBackends(clientset)
+	go providers.Run(context.Background(), providerCheckInterval)
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" {
@@ -367,7 +703,9 @@ func main() {
 		// Let other paths fall through to the file server
 		http.NotFound(w, r)
 	})
-	http.HandleFunc("/api/analyze-logs", handleAnalyzeLogs(clientset))
+	http.HandleFunc("/api/analyze-logs", handleAnalyzeLogs(logSource, clusterStream, providers))
+	http.HandleFunc("/api/providers", handleProviders(providers))
+	http.HandleFunc("/api/pattern-analysis", handlePatternAnalysis(logSource, clusterStream))
 
 	// Serve JS files
 	jsFS, _ := fs.Sub(staticFiles, "js")
@@ -377,8 +715,10 @@ func main() {
 	stylesFS, _ := fs.Sub(staticFiles, "styles")
 	http.Handle("/styles/", http.StripPrefix("/styles/", http.FileServer(http.FS(stylesFS))))
 
-	// Your existing data handler stays the same
-	http.HandleFunc("/data", handleData(clientset, config))
+	http.HandleFunc("/data", handleData(dataFetcher))
+	http.Handle("/api/stream", clusterStream)
+	http.HandleFunc("/api/history/", handleHistory(historyStore))
+	http.HandleFunc("/api/diff", handleDiff(dataFetcher, historyStore))
 
 	serverAddr := ":" + *port
 	log.Printf("Backend server started on port %s. Open http://localhost:%s in your browser.", *port, *port)
@@ -0,0 +1,133 @@
+package display
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/patternengine"
+)
+
+// DisplayAnalysis prints a pattern engine report: the summary counts, every
+// finding in rank order and the correlations between them
+func DisplayAnalysis(result *patternengine.AnalysisResult) {
+	s := result.Summary
+	fmt.Printf("%d of %d patterns matched: %d critical, %d warning, %d info, %d correlations (%s)\n",
+		s.MatchesFound, s.TotalPatterns, s.CriticalIssues, s.WarningIssues, s.InfoIssues, s.Correlations,
+		result.Duration.Round(time.Microsecond))
+	if len(result.Hints) == 0 {
+		fmt.Println("\nNo known patterns matched")
+		return
+	}
+
+	for i, hint := range result.Hints {
+		fmt.Println()
+		fmt.Println(strings.Repeat("=", 80))
+		rootCause := ""
+		if hint.PatternID == result.RootCause {
+			rootCause = " (root cause)"
+		}
+		fmt.Printf("%d. %s%s\n", i+1, hint.Summary, rootCause)
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Printf("Pattern:     %s\n", hint.PatternID)
+		fmt.Printf("Confidence:  %s (score %.2f, %d occurrences)\n", hint.Confidence, hint.Score, hint.OccurrenceCount)
+		if hint.Explanation != "" {
+			fmt.Printf("\n%s\n", hint.Explanation)
+		}
+		if hint.Suggestion != "" {
+			fmt.Printf("\nSUGGESTION:\n  %s\n", hint.Suggestion)
+		}
+		if hint.Command != "" {
+			fmt.Printf("\nCOMMAND:\n  $ %s\n", hint.Command)
+		}
+		if len(hint.Resources) > 0 {
+			fmt.Println("\nRESOURCES:")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			safePrintln(w, "  KIND\tNAME\tSTATUS\tSTATE\tVM\tNODE")
+			for _, r := range hint.Resources {
+				safePrint(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", r.Kind, r.Name, orDash(string(r.Status)), orDash(r.State), orDash(r.VM), orDash(r.Node))
+			}
+			if err := w.Flush(); err != nil {
+				log.Printf("Failed to flush writer: %v", err)
+			}
+		}
+		if len(hint.References) > 0 {
+			fmt.Println("\nREFERENCES:")
+			for _, ref := range hint.References {
+				fmt.Printf("  - %s\n", ref)
+			}
+		}
+	}
+
+	if len(result.Correlations) > 0 {
+		fmt.Println("\nCORRELATIONS:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		safePrintln(w, "CAUSE\tEFFECT\tGAP\tMESSAGE")
+		for _, c := range result.Correlations {
+			cause, effect, gap := c.Cause, c.Effect, "-"
+			if cause == "" {
+				cause, effect = c.PatternID1, c.PatternID2
+			} else {
+				gap = c.Gap.String()
+			}
+			safePrint(w, "%s\t%s\t%s\t%s\n", cause, effect, gap, c.Message)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Failed to flush writer: %v", err)
+		}
+	}
+}
+
+// DisplayCorpusReport prints the outcome of each corpus sample, then the
+// precision and recall of every pattern the corpus exercised
+func DisplayCorpusReport(report *patternengine.CorpusReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	safePrintln(w, "SAMPLE\tRESULT\tMATCHED\tROOT CAUSE")
+	for _, s := range report.Samples {
+		result := "PASS"
+		if !s.Passed {
+			result = "FAIL"
+		}
+		safePrint(w, "%s\t%s\t%s\t%s\n", s.Name, result, orDash(strings.Join(s.Matched, ",")), orDash(s.RootCause))
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to flush writer: %v", err)
+	}
+
+	for _, s := range report.Failed() {
+		fmt.Printf("\n%s:\n", s.Name)
+		if len(s.Missing) > 0 {
+			fmt.Printf("  missing:      %s\n", strings.Join(s.Missing, ", "))
+		}
+		if len(s.Unexpected) > 0 {
+			fmt.Printf("  unexpected:   %s\n", strings.Join(s.Unexpected, ", "))
+		}
+		if s.WrongRootCause {
+			fmt.Printf("  root cause:   got %s\n", orDash(s.RootCause))
+		}
+		for _, c := range s.MissingCorrelations {
+			fmt.Printf("  correlation:  %s not found\n", c)
+		}
+	}
+
+	fmt.Println("\nPATTERNS:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	safePrintln(w, "PATTERN\tTP\tFP\tFN\tPRECISION\tRECALL")
+	untested := 0
+	for _, p := range report.Patterns {
+		if p.TruePositives+p.FalsePositives+p.FalseNegatives == 0 {
+			untested++
+			continue
+		}
+		safePrint(w, "%s\t%d\t%d\t%d\t%.2f\t%.2f\n", p.PatternID, p.TruePositives, p.FalsePositives, p.FalseNegatives, p.Precision, p.Recall)
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to flush writer: %v", err)
+	}
+	if untested > 0 {
+		fmt.Printf("\n%d patterns are not exercised by any sample\n", untested)
+	}
+}
@@ -0,0 +1,281 @@
+package display
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+// severityRank orders issues from most to least severe
+var severityRank = map[string]int{
+	"critical": 0,
+	"high":     1,
+	"medium":   2,
+	"warning":  3,
+	"low":      4,
+}
+
+// DisplayNodes prints one row per node with readiness, scheduling and disks
+func DisplayNodes(nodes []types.NodeWithMetrics) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	safePrintln(w, "NAME\tREADY\tSCHEDULABLE\tROLES\tINTERNAL-IP\tPODS\tDISKS\tPDB ISSUES")
+
+	for _, node := range nodes {
+		ready := nodeConditionStatus(node.NodeInfo.Conditions, "Ready")
+		schedulable := "-"
+		roles := "-"
+		internalIP := "-"
+		if node.KubernetesNodeInfo != nil {
+			if k8sReady := nodeConditionStatus(node.KubernetesNodeInfo.Conditions, "Ready"); k8sReady == "True" {
+				ready = k8sReady
+			}
+			schedulable = "Yes"
+			if node.KubernetesNodeInfo.Unschedulable {
+				schedulable = "No (cordoned)"
+			}
+			if len(node.KubernetesNodeInfo.Roles) > 0 {
+				roles = strings.Join(node.KubernetesNodeInfo.Roles, ",")
+			}
+			if node.KubernetesNodeInfo.InternalIP != "" {
+				internalIP = node.KubernetesNodeInfo.InternalIP
+			}
+		}
+		if ready == "" {
+			ready = "Unknown"
+		}
+
+		schedulableDisks := 0
+		for _, disk := range node.NodeInfo.Disks {
+			if disk.IsSchedulable {
+				schedulableDisks++
+			}
+		}
+
+		pdbIssues := 0
+		if node.PDBHealthStatus != nil {
+			pdbIssues = node.PDBHealthStatus.IssueCount
+		}
+
+		safePrint(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d/%d schedulable\t%d\n",
+			node.NodeInfo.Name, ready, schedulable, roles, internalIP,
+			node.RunningPods, schedulableDisks, len(node.NodeInfo.Disks), pdbIssues)
+	}
+
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to flush writer: %v", err)
+	}
+}
+
+// DisplayHealthChecks prints the result of each health check, with details
+// for the checks that did not pass
+func DisplayHealthChecks(summary *types.HealthCheckSummary) {
+	fmt.Printf("Health checks: %d passed, %d warnings, %d failed, %d timed out (last run %s)\n\n",
+		summary.PassedChecks, summary.WarningChecks, summary.FailedChecks, summary.TimeoutChecks,
+		summary.LastRun.Format("2006-01-02 15:04:05"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	safePrintln(w, "CHECK\tCATEGORY\tSTATUS\tDURATION\tMESSAGE")
+	for _, result := range summary.Results {
+		message := result.Message
+		if result.Error != "" {
+			message = result.Error
+		}
+		safePrint(w, "%s\t%s\t%s\t%s\t%s\n", result.CheckName, result.Category, strings.ToUpper(result.Status), result.Duration, message)
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to flush writer: %v", err)
+	}
+
+	for _, result := range summary.Results {
+		if result.Status == "passed" || (len(result.Details) == 0 && len(result.PodErrors) == 0) {
+			continue
+		}
+		fmt.Printf("\n%s:\n", strings.ToUpper(result.CheckName))
+		for _, detail := range result.Details {
+			fmt.Printf("  - %s\n", detail)
+		}
+		for _, pod := range result.PodErrors {
+			fmt.Printf("  - %s/%s on %s: %s\n", pod.Namespace, pod.Name, pod.NodeName, pod.ErrorState)
+		}
+	}
+}
+
+// DisplayIssues prints detected issues, most severe first
+func DisplayIssues(issues []types.Issue) {
+	if len(issues) == 0 {
+		fmt.Println("No issues detected")
+		return
+	}
+
+	sorted := append([]types.Issue{}, issues...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return issueRank(sorted[i].Severity) < issueRank(sorted[j].Severity)
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	safePrintln(w, "SEVERITY\tCATEGORY\tRESOURCE\tTITLE")
+	for _, issue := range sorted {
+		safePrint(w, "%s\t%s\t%s\t%s\n", strings.ToUpper(issue.Severity), issue.Category, issue.AffectedResource, issue.Title)
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to flush writer: %v", err)
+	}
+}
+
+// DisplayIssueDetails prints each issue with its evidence and the steps to
+// verify and fix it
+func DisplayIssueDetails(issues []types.Issue) {
+	for i, issue := range issues {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Printf("[%s] %s\n", strings.ToUpper(issue.Severity), issue.Title)
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Printf("ID:       %s\n", issue.ID)
+		fmt.Printf("Resource: %s\n", issue.AffectedResource)
+		fmt.Printf("\n%s\n", issue.Description)
+
+		if len(issue.Evidence) > 0 {
+			fmt.Println("\nEVIDENCE:")
+			for _, evidence := range issue.Evidence {
+				fmt.Printf("  - %s\n", evidence)
+			}
+		}
+		displaySteps("VERIFICATION", issue.VerificationSteps)
+		displaySteps("REMEDIATION", issue.RemediationSteps)
+	}
+}
+
+func displaySteps(heading string, steps []types.IssueStep) {
+	if len(steps) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", heading)
+	for i, step := range steps {
+		fmt.Printf("  %d. %s\n", i+1, step.Title)
+		if step.Description != "" {
+			fmt.Printf("     %s\n", step.Description)
+		}
+		if step.Command != "" {
+			fmt.Printf("     $ %s\n", step.Command)
+		}
+		for _, command := range step.Commands {
+			fmt.Printf("     $ %s\n", command)
+		}
+		if step.Warning != "" {
+			fmt.Printf("     WARNING: %s\n", step.Warning)
+		}
+	}
+}
+
+func issueRank(severity string) int {
+	if rank, ok := severityRank[severity]; ok {
+		return rank
+	}
+	return len(severityRank)
+}
+
+func nodeConditionStatus(conditions []types.NodeCondition, conditionType string) string {
+	for _, c := range conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+// DisplayChangeset prints what changed between two snapshots
+func DisplayChangeset(c *types.Changeset) {
+	fmt.Printf("Changes from %s to %s\n", c.From, c.To)
+	if len(c.VMsAdded)+len(c.VMsRemoved)+len(c.StatusChanges)+len(c.ReplicaChanges)+
+		len(c.EngineRestarts)+len(c.DiskChanges)+len(c.HealthChanges) == 0 {
+		fmt.Println("\nNo changes")
+		return
+	}
+
+	for _, vm := range c.VMsAdded {
+		fmt.Printf("\n+ VM %s", vm)
+	}
+	for _, vm := range c.VMsRemoved {
+		fmt.Printf("\n- VM %s", vm)
+	}
+	if len(c.VMsAdded)+len(c.VMsRemoved) > 0 {
+		fmt.Println()
+	}
+
+	if len(c.StatusChanges) > 0 || len(c.HealthChanges) > 0 {
+		fmt.Println("\nSTATUS CHANGES:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		safePrintln(w, "KIND\tNAME\tFIELD\tFROM\tTO")
+		for _, change := range append(append([]types.StatusChange{}, c.StatusChanges...), c.HealthChanges...) {
+			safePrint(w, "%s\t%s\t%s\t%s\t%s\n", change.Kind, change.Name, change.Field, orDash(change.From), orDash(change.To))
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Failed to flush writer: %v", err)
+		}
+	}
+
+	if len(c.ReplicaChanges) > 0 {
+		fmt.Println("\nREPLICAS:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		safePrintln(w, "VOLUME\tREPLICA\tCHANGE\tNODE\tSTATE")
+		for _, change := range c.ReplicaChanges {
+			safePrint(w, "%s\t%s\t%s\t%s\t%s\n", change.Volume, change.Name, change.Change,
+				transition(change.FromNode, change.ToNode), transition(change.FromState, change.ToState))
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Failed to flush writer: %v", err)
+		}
+	}
+
+	if len(c.EngineRestarts) > 0 {
+		fmt.Println("\nENGINE RESTARTS:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		safePrintln(w, "VOLUME\tENGINE\tREASON\tNODE\tSTATE")
+		for _, change := range c.EngineRestarts {
+			reason := change.Reason
+			if change.Previous != "" {
+				reason += " (was " + change.Previous + ")"
+			}
+			safePrint(w, "%s\t%s\t%s\t%s\t%s\n", change.Volume, change.Name, reason,
+				transition(change.FromNode, change.ToNode), transition(change.FromState, change.ToState))
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Failed to flush writer: %v", err)
+		}
+	}
+
+	if len(c.DiskChanges) > 0 {
+		fmt.Println("\nDISKS:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		safePrintln(w, "NODE\tDISK\tSCHEDULABLE\tREASON")
+		for _, change := range c.DiskChanges {
+			safePrint(w, "%s\t%s\t%s\t%s\n", change.Node, change.Disk,
+				transition(fmt.Sprint(!change.Schedulable), fmt.Sprint(change.Schedulable)), orDash(change.PressureReason))
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Failed to flush writer: %v", err)
+		}
+	}
+}
+
+// transition formats a value that may have changed as "from -> to"
+func transition(from, to string) string {
+	if from == to {
+		return orDash(to)
+	}
+	return orDash(from) + " -> " + orDash(to)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
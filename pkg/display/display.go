@@ -41,8 +41,13 @@ func DisplayVMInfo(info *types.VMInfo) {
 		log.Printf("Failed to write separator: %v", err)
 	}
 
-	displayEngineInfo(w, info)
-	displayReplicaInfo(info)
+	for _, disk := range info.DiskViews() {
+		if len(info.Disks) > 1 {
+			fmt.Printf("\n--- Volume %s (PVC %s) ---\n", disk.VolumeName, disk.ClaimName)
+		}
+		displayEngineInfo(w, disk.EngineInfo)
+		displayReplicaInfo(disk.ReplicaInfo, info.MissingResource)
+	}
 
 	// Footer
 	fmt.Println("\n" + strings.Repeat("=", 80))
@@ -141,17 +146,33 @@ func displayPodInfo(w *tabwriter.Writer, info *types.VMInfo) {
 func displayStorageInfo(w *tabwriter.Writer, info *types.VMInfo) {
 	safePrintln(w, "\nSTORAGE INFO:")
 	safePrintln(w, "-------------")
-	safePrint(w, "PVC Claim Names:\t%s\n", info.ClaimNames)
-	safePrint(w, "Volume Name:\t%s\n", info.VolumeName)
-	safePrint(w, "PVC Status:\t%s\n", formatPVCStatus(string(info.PVCStatus)))
+	if len(info.Disks) == 0 {
+		safePrint(w, "PVC Claim Names:\t%s\n", info.ClaimNames)
+		safePrint(w, "Volume Name:\t%s\n", info.VolumeName)
+		safePrint(w, "PVC Status:\t%s\n", formatPVCStatus(string(info.PVCStatus)))
+		return
+	}
+	for i, disk := range info.Disks {
+		if i > 0 {
+			safePrintln(w, "")
+		}
+		safePrint(w, "Disk:\t%s\n", disk.Name)
+		safePrint(w, "PVC Claim Name:\t%s\n", disk.ClaimName)
+		safePrint(w, "Volume Name:\t%s\n", disk.VolumeName)
+		safePrint(w, "PVC Status:\t%s\n", formatPVCStatus(string(disk.PVCStatus)))
+		if disk.VolumeRobustness != "" {
+			safePrint(w, "Robustness:\t%s\n", disk.VolumeRobustness)
+		}
+		safePrint(w, "Hotplug:\t%s\n", formatBool(disk.Hotplug))
+	}
 }
-func displayEngineInfo(w *tabwriter.Writer, info *types.VMInfo) {
-	if len(info.EngineInfo) == 0 {
+func displayEngineInfo(w *tabwriter.Writer, engines []types.EngineInfo) {
+	if len(engines) == 0 {
 		return
 	}
 	fmt.Println("\nENGINE INFORMATION:")
 	fmt.Println("-----------------")
-	for i, engine := range info.EngineInfo {
+	for i, engine := range engines {
 		if i > 0 {
 			fmt.Println("\n--- Engine", i+1, "---")
 		}
@@ -163,11 +184,11 @@ func displayEngineInfo(w *tabwriter.Writer, info *types.VMInfo) {
 	}
 }
 
-func displayReplicaInfo(info *types.VMInfo) {
-	if len(info.ReplicaInfo) > 0 {
-		printReplicaTable(info.ReplicaInfo)
-	} else if info.MissingResource != "" {
-		fmt.Printf("\nProcess stopped: %s resource not found\n", info.MissingResource)
+func displayReplicaInfo(replicas []types.ReplicaInfo, missingResource string) {
+	if len(replicas) > 0 {
+		printReplicaTable(replicas)
+	} else if missingResource != "" {
+		fmt.Printf("\nProcess stopped: %s resource not found\n", missingResource)
 	} else {
 		fmt.Println("\nNo replicas found for this volume")
 	}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	types "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/bundle"
+	"github.com/rk280392/harvesterNavigator/internal/services/diff"
+	"github.com/rk280392/harvesterNavigator/internal/services/health"
+	"github.com/rk280392/harvesterNavigator/internal/services/history"
+	"github.com/rk280392/harvesterNavigator/internal/services/logrecord"
+	"github.com/rk280392/harvesterNavigator/pkg/display"
+)
+
+// liveSnapshot names the data source the server or command is connected to
+const liveSnapshot = "live"
+
+// snapshotAt rebuilds the cluster state recorded in the history store at the
+// time given as a duration before now ("24h", "7d") or an RFC3339 timestamp
+func snapshotAt(store *history.Store, ref string) (*types.FullClusterData, error) {
+	if store == nil {
+		return nil, errors.New("history is not recorded, so only live data can be compared")
+	}
+	at, err := logrecord.ParseSince(ref, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return store.StateAt(at)
+}
+
+// loadSnapshotFile reads a snapshot saved from /data, or a support bundle
+func loadSnapshotFile(path string) (*types.FullClusterData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() || strings.HasSuffix(path, ".zip") {
+		supportBundle, err := bundle.OpenBundle(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open support bundle %s: %w", path, err)
+		}
+		defer func() { _ = supportBundle.Close() }()
+
+		data, err := CreateDataFetcher(nil, nil, supportBundle, health.DefaultConfig()).fetchFullClusterData()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read support bundle %s: %w", path, err)
+		}
+		return &data, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var data types.FullClusterData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &data, nil
+}
+
+// handleDiff compares two points in time, e.g. /api/diff?from=24h&to=live.
+// Each side is "live" or a time looked up in the history store.
+func handleDiff(dataFetcher *DataFetcher, store *history.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// A failed live fetch is the server's fault, a time the history
+		// does not cover is the client's
+		load := func(ref string) (*types.FullClusterData, int, error) {
+			if ref == liveSnapshot {
+				data, err := dataFetcher.fetchFullClusterData()
+				if err != nil {
+					return nil, http.StatusServiceUnavailable, err
+				}
+				return &data, http.StatusOK, nil
+			}
+			if store == nil {
+				return nil, http.StatusNotFound, errors.New("history is not recorded, so only live data can be compared")
+			}
+			data, err := snapshotAt(store, ref)
+			if err != nil {
+				return nil, http.StatusBadRequest, err
+			}
+			return data, http.StatusOK, nil
+		}
+
+		fromRef := r.URL.Query().Get("from")
+		toRef := r.URL.Query().Get("to")
+		if fromRef == "" {
+			http.Error(w, "Missing from: use \"live\", a duration like 24h or 7d, or an RFC3339 time", http.StatusBadRequest)
+			return
+		}
+		if toRef == "" {
+			toRef = liveSnapshot
+		}
+
+		from, status, err := load(fromRef)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load from: %v", err), status)
+			return
+		}
+		to, status, err := load(toRef)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load to: %v", err), status)
+			return
+		}
+
+		changes := diff.Compare(from, to)
+		changes.From, changes.To = fromRef, toRef
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(changes); err != nil {
+			log.Printf("JSON encoding error: %v", err)
+		}
+	}
+}
+
+func runDiffCommand(fs *flag.FlagSet, output *string, args []string, fetch func() (types.FullClusterData, error)) error {
+	historyFile := fs.String("history-file", defaultHistoryPath(), "History file used for snapshots given as a time")
+	positional, err := parseCommandFlags(fs, output, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("%w: expected a from and a to snapshot", errUsage)
+	}
+
+	var store *history.Store
+	load := func(ref string) (*types.FullClusterData, error) {
+		if ref == liveSnapshot {
+			data, err := fetch()
+			return &data, err
+		}
+		if _, err := os.Stat(ref); err == nil {
+			return loadSnapshotFile(ref)
+		}
+		if _, err := logrecord.ParseSince(ref, time.Now()); err != nil {
+			return nil, fmt.Errorf("%s is neither a file nor a time: %w", ref, err)
+		}
+		if store == nil {
+			if store, err = history.ReadStore(*historyFile); err != nil {
+				return nil, err
+			}
+		}
+		return snapshotAt(store, ref)
+	}
+
+	from, err := load(positional[0])
+	if err != nil {
+		return err
+	}
+	to, err := load(positional[1])
+	if err != nil {
+		return err
+	}
+
+	changes := diff.Compare(from, to)
+	changes.From, changes.To = positional[0], positional[1]
+	return writeOutput(*output, changes, func() {
+		display.DisplayChangeset(changes)
+	})
+}
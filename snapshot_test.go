@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/rk280392/harvesterNavigator/internal/services/history"
+)
+
+func TestHandleDiff_Status(t *testing.T) {
+	store, err := history.OpenStore(filepath.Join(t.TempDir(), "history.jsonl"), 0, history.DefaultMaxEntries)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		store *history.Store
+		query string
+		want  int
+	}{
+		{"missing from", store, "", http.StatusBadRequest},
+		{"malformed time", store, "from=yesterday&to=1h", http.StatusBadRequest},
+		{"time without history", store, "from=24h&to=1h", http.StatusBadRequest},
+		{"history not recorded", nil, "from=24h&to=1h", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handleDiff(nil, tt.store)(recorder, httptest.NewRequest(http.MethodGet, "/api/diff?"+tt.query, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, tt.want, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+	"github.com/rk280392/harvesterNavigator/internal/services/cache"
+)
+
+const (
+	// streamDebounce batches bursts of watch events into one refresh
+	streamDebounce = 2 * time.Second
+	// streamResync rebuilds the snapshot even without watch events, since
+	// health checks, upgrades and PDBs are not watched
+	streamResync = 30 * time.Second
+	// streamHeartbeat keeps idle connections open through proxies
+	streamHeartbeat = 15 * time.Second
+	// streamClientBuffer is how many messages a slow client may fall behind
+	// before it is disconnected and has to reconnect for a fresh snapshot
+	streamClientBuffer = 64
+)
+
+// Delta event types
+const (
+	DeltaAdded   = "added"
+	DeltaUpdated = "updated"
+	DeltaRemoved = "removed"
+)
+
+// VMDelta describes a change to a single VM
+type VMDelta struct {
+	Type      string         `json:"type"`
+	Namespace string         `json:"namespace"`
+	Name      string         `json:"name"`
+	VM        *models.VMInfo `json:"vm,omitempty"`
+}
+
+// NodeDelta describes a change to a single node
+type NodeDelta struct {
+	Type string                  `json:"type"`
+	Name string                  `json:"name"`
+	Node *models.NodeWithMetrics `json:"node,omitempty"`
+}
+
+// ClusterDelta carries the cluster-wide fields of FullClusterData
+type ClusterDelta struct {
+	UpgradeInfo   *models.UpgradeInfo          `json:"upgradeInfo,omitempty"`
+	HealthChecks  *models.HealthCheckSummary   `json:"healthChecks,omitempty"`
+	NodeCPULabels map[string]map[string]string `json:"nodeCPULabels,omitempty"`
+	Issues        []models.Issue               `json:"issues"`
+}
+
+// streamMessage is a single Server-Sent Event
+type streamMessage struct {
+	event string
+	data  []byte
+}
+
+// ClusterStream fans one upstream watch out to any number of SSE clients.
+// It rebuilds FullClusterData when the cluster cache reports a change and
+// sends each client only the VMs and nodes that differ.
+type ClusterStream struct {
+	fetcher      *DataFetcher
+	cache        *cache.ClusterCache
+	refreshMutex sync.Mutex
+
+	mutex    sync.Mutex
+	clients  map[chan streamMessage]struct{}
+	data     *models.FullClusterData
+	snapshot []byte
+	vms      map[string]string
+	nodes    map[string]string
+	cluster  string
+	dirty    bool
+}
+
+// CreateClusterStream creates a stream over the given fetcher and cache
+func CreateClusterStream(fetcher *DataFetcher, clusterCache *cache.ClusterCache) *ClusterStream {
+	return &ClusterStream{
+		fetcher: fetcher,
+		cache:   clusterCache,
+		clients: make(map[chan streamMessage]struct{}),
+		dirty:   true,
+	}
+}
+
+// Run watches the cluster cache and pushes deltas until ctx is cancelled
+func (s *ClusterStream) Run(ctx context.Context) {
+	changes, unsubscribe := s.cache.Subscribe()
+	defer unsubscribe()
+
+	resync := time.NewTicker(streamResync)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changes:
+			// Let the burst settle before rebuilding
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamDebounce):
+			}
+		case <-resync.C:
+		}
+
+		if s.clientCount() == 0 {
+			s.markDirty()
+			continue
+		}
+		s.refresh()
+	}
+}
+
+// ServeHTTP streams an initial snapshot followed by delta events
+func (s *ClusterStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	if s.isDirty() {
+		s.refresh()
+	}
+
+	ch, snapshot := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	if snapshot == nil {
+		http.Error(w, "Cluster data not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	if err := writeEvent(w, streamMessage{event: "snapshot", data: snapshot}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				// Dropped for falling behind; the browser reconnects
+				return
+			}
+			if err := writeEvent(w, msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// refresh rebuilds the snapshot and broadcasts what changed since the last one
+func (s *ClusterStream) refresh() {
+	s.refreshMutex.Lock()
+	defer s.refreshMutex.Unlock()
+
+	data, err := s.fetcher.fetchFullClusterData()
+	if err != nil {
+		log.Printf("Warning: Could not refresh stream snapshot: %v", err)
+		return
+	}
+	s.publish(data)
+}
+
+// publish makes data the current snapshot and sends clients the VMs, nodes
+// and cluster-wide fields that changed since the previous one
+func (s *ClusterStream) publish(data models.FullClusterData) {
+	snapshot, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Warning: Could not encode stream snapshot: %v", err)
+		return
+	}
+
+	vms := make(map[string]string, len(data.VMs))
+	vmsByKey := make(map[string]*models.VMInfo, len(data.VMs))
+	for i := range data.VMs {
+		key := data.VMs[i].Namespace + "/" + data.VMs[i].Name
+		vms[key] = fingerprint(data.VMs[i])
+		vmsByKey[key] = &data.VMs[i]
+	}
+
+	nodes := make(map[string]string, len(data.Nodes))
+	nodesByName := make(map[string]*models.NodeWithMetrics, len(data.Nodes))
+	for i := range data.Nodes {
+		name := data.Nodes[i].NodeInfo.Name
+		nodes[name] = nodeFingerprint(data.Nodes[i])
+		nodesByName[name] = &data.Nodes[i]
+	}
+
+	cluster := ClusterDelta{
+		UpgradeInfo:   data.UpgradeInfo,
+		HealthChecks:  data.HealthChecks,
+		NodeCPULabels: data.NodeCPULabels,
+		Issues:        data.Issues,
+	}
+	clusterPrint := clusterFingerprint(cluster)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.snapshot != nil && !s.dirty {
+		var messages []streamMessage
+		for key, fp := range vms {
+			old, existed := s.vms[key]
+			switch {
+			case !existed:
+				messages = append(messages, vmMessage(DeltaAdded, vmsByKey[key]))
+			case old != fp:
+				messages = append(messages, vmMessage(DeltaUpdated, vmsByKey[key]))
+			}
+		}
+		for key := range s.vms {
+			if _, exists := vms[key]; !exists {
+				namespace, name, _ := strings.Cut(key, "/")
+				messages = append(messages, encodeMessage("vm", VMDelta{Type: DeltaRemoved, Namespace: namespace, Name: name}))
+			}
+		}
+		for name, fp := range nodes {
+			old, existed := s.nodes[name]
+			switch {
+			case !existed:
+				messages = append(messages, encodeMessage("node", NodeDelta{Type: DeltaAdded, Name: name, Node: nodesByName[name]}))
+			case old != fp:
+				messages = append(messages, encodeMessage("node", NodeDelta{Type: DeltaUpdated, Name: name, Node: nodesByName[name]}))
+			}
+		}
+		for name := range s.nodes {
+			if _, exists := nodes[name]; !exists {
+				messages = append(messages, encodeMessage("node", NodeDelta{Type: DeltaRemoved, Name: name}))
+			}
+		}
+		if clusterPrint != s.cluster {
+			messages = append(messages, encodeMessage("cluster", cluster))
+		}
+
+		for _, msg := range messages {
+			if msg.data != nil {
+				s.broadcastLocked(msg)
+			}
+		}
+		if len(messages) > 0 {
+			log.Printf("Stream: sent %d delta events to %d clients", len(messages), len(s.clients))
+		}
+	} else {
+		// Clients connected before this refresh missed changes made while
+		// nobody was listening, so resend the whole snapshot
+		s.broadcastLocked(streamMessage{event: "snapshot", data: snapshot})
+	}
+
+	s.data = &data
+	s.snapshot = snapshot
+	s.vms = vms
+	s.nodes = nodes
+	s.cluster = clusterPrint
+	s.dirty = false
+}
+
+// current returns the latest cluster data, rebuilding it first when the
+// cluster changed while no client was listening. The data is shared and must
+// not be modified.
+func (s *ClusterStream) current() (*models.FullClusterData, bool) {
+	if s.isDirty() {
+		s.refresh()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.data, s.data != nil
+}
+
+// latest returns the current cluster data for samplers such as the history
+// store
+func (s *ClusterStream) latest() (models.FullClusterData, error) {
+	data, ok := s.current()
+	if !ok {
+		return models.FullClusterData{}, errors.New("cluster data not available")
+	}
+	return *data, nil
+}
+
+// broadcastLocked sends msg to every client, dropping clients whose buffer is full
+func (s *ClusterStream) broadcastLocked(msg streamMessage) {
+	for ch := range s.clients {
+		select {
+		case ch <- msg:
+		default:
+			delete(s.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *ClusterStream) subscribe() (chan streamMessage, []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ch := make(chan streamMessage, streamClientBuffer)
+	s.clients[ch] = struct{}{}
+	return ch, s.snapshot
+}
+
+func (s *ClusterStream) unsubscribe(ch chan streamMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.clients[ch]; ok {
+		delete(s.clients, ch)
+		close(ch)
+	}
+}
+
+func (s *ClusterStream) clientCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.clients)
+}
+
+func (s *ClusterStream) markDirty() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dirty = true
+}
+
+func (s *ClusterStream) isDirty() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dirty || s.snapshot == nil
+}
+
+// writeEvent writes msg in Server-Sent Events framing
+func writeEvent(w http.ResponseWriter, msg streamMessage) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.event, msg.data)
+	return err
+}
+
+func vmMessage(deltaType string, vmInfo *models.VMInfo) streamMessage {
+	return encodeMessage("vm", VMDelta{Type: deltaType, Namespace: vmInfo.Namespace, Name: vmInfo.Name, VM: vmInfo})
+}
+
+func encodeMessage(event string, payload interface{}) streamMessage {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Warning: Could not encode %s event: %v", event, err)
+		return streamMessage{event: event}
+	}
+	return streamMessage{event: event, data: data}
+}
+
+// fingerprint returns a comparable encoding of v
+func fingerprint(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// nodeFingerprint ignores the PDB check time, which changes on every refresh
+func nodeFingerprint(n models.NodeWithMetrics) string {
+	if n.PDBHealthStatus != nil {
+		pdbStatus := *n.PDBHealthStatus
+		pdbStatus.LastChecked = time.Time{}
+		n.PDBHealthStatus = &pdbStatus
+	}
+	return fingerprint(n)
+}
+
+// clusterFingerprint ignores health check and issue detection times, which
+// change on every refresh
+func clusterFingerprint(c ClusterDelta) string {
+	if c.HealthChecks != nil {
+		summary := *c.HealthChecks
+		summary.LastRun = time.Time{}
+		results := make([]models.HealthCheckResult, len(summary.Results))
+		for i, result := range summary.Results {
+			result.Timestamp = time.Time{}
+			result.Duration = ""
+			results[i] = result
+		}
+		summary.Results = results
+		c.HealthChecks = &summary
+	}
+	issues := make([]models.Issue, len(c.Issues))
+	for i, issue := range c.Issues {
+		issue.DetectionTime = time.Time{}
+		if issue.PDBDetails != nil {
+			details := *issue.PDBDetails
+			details.LastChecked = time.Time{}
+			issue.PDBDetails = &details
+		}
+		issues[i] = issue
+	}
+	c.Issues = issues
+	return fingerprint(c)
+}
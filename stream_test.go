@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	models "github.com/rk280392/harvesterNavigator/internal/models"
+)
+
+func streamTestData() models.FullClusterData {
+	return models.FullClusterData{
+		VMs: []models.VMInfo{
+			{Name: "vm1", Namespace: "default", PrintableStatus: "Running"},
+			{Name: "vm2", Namespace: "default", PrintableStatus: "Running"},
+		},
+		Nodes: []models.NodeWithMetrics{
+			{NodeInfo: models.NodeInfo{Name: "node-a"}, RunningPods: 10},
+			{NodeInfo: models.NodeInfo{Name: "node-b"}, RunningPods: 12},
+		},
+		HealthChecks: &models.HealthCheckSummary{LastRun: time.Unix(100, 0)},
+		Issues:       []models.Issue{{ID: "issue-1", DetectionTime: time.Unix(100, 0)}},
+	}
+}
+
+func TestClusterStream_Deltas(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(data *models.FullClusterData)
+		want   []string // event/type/name of each delta
+	}{
+		{
+			name: "unchanged",
+			change: func(data *models.FullClusterData) {
+				// Check and detection times change on every refresh
+				data.HealthChecks.LastRun = time.Unix(200, 0)
+				data.Issues[0].DetectionTime = time.Unix(200, 0)
+			},
+		},
+		{
+			name:   "changed VM",
+			change: func(data *models.FullClusterData) { data.VMs[0].PrintableStatus = "Stopped" },
+			want:   []string{"vm/updated/vm1"},
+		},
+		{
+			name:   "removed VM",
+			change: func(data *models.FullClusterData) { data.VMs = data.VMs[:1] },
+			want:   []string{"vm/removed/vm2"},
+		},
+		{
+			name: "added VM",
+			change: func(data *models.FullClusterData) {
+				data.VMs = append(data.VMs, models.VMInfo{Name: "vm3", Namespace: "default"})
+			},
+			want: []string{"vm/added/vm3"},
+		},
+		{
+			name:   "changed node",
+			change: func(data *models.FullClusterData) { data.Nodes[1].RunningPods = 13 },
+			want:   []string{"node/updated/node-b"},
+		},
+		{
+			name:   "new issue",
+			change: func(data *models.FullClusterData) { data.Issues = append(data.Issues, models.Issue{ID: "issue-2"}) },
+			want:   []string{"cluster//"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := CreateClusterStream(nil, nil)
+			ch, _ := stream.subscribe()
+
+			stream.publish(streamTestData())
+			if msg := <-ch; msg.event != "snapshot" {
+				t.Fatalf("first event = %s, want snapshot", msg.event)
+			}
+
+			data := streamTestData()
+			tt.change(&data)
+			stream.publish(data)
+
+			var got []string
+			for len(ch) > 0 {
+				msg := <-ch
+				var delta struct{ Type, Name string }
+				if err := json.Unmarshal(msg.data, &delta); err != nil {
+					t.Fatalf("decode %s event: %v", msg.event, err)
+				}
+				got = append(got, msg.event+"/"+delta.Type+"/"+delta.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("deltas = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("deltas = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterStream_Current(t *testing.T) {
+	stream := CreateClusterStream(nil, nil)
+	stream.publish(streamTestData())
+
+	data, ok := stream.current()
+	if !ok || len(data.VMs) != 2 || data.Issues[0].ID != "issue-1" {
+		t.Errorf("current = %+v, %t, want the published data", data, ok)
+	}
+}